@@ -0,0 +1,4 @@
+// Package paymentv1 holds the gRPC API of the payment service, generated from payment.proto
+package paymentv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative payment/v1/payment.proto
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: payment/v1/payment.proto
+
+package paymentv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PaymentQueryService_GetPayment_FullMethodName           = "/payment.v1.PaymentQueryService/GetPayment"
+	PaymentQueryService_GetPaymentByTrip_FullMethodName     = "/payment.v1.PaymentQueryService/GetPaymentByTrip"
+	PaymentQueryService_ListPaymentsByUser_FullMethodName   = "/payment.v1.PaymentQueryService/ListPaymentsByUser"
+	PaymentQueryService_ListPaymentsByDriver_FullMethodName = "/payment.v1.PaymentQueryService/ListPaymentsByDriver"
+)
+
+// PaymentQueryServiceClient is the client API for PaymentQueryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PaymentQueryService answers read-only questions about payments for the API gateway and rider app
+type PaymentQueryServiceClient interface {
+	// GetPayment returns a payment by its ID
+	GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// GetPaymentByTrip returns the latest payment for a trip
+	GetPaymentByTrip(ctx context.Context, in *GetPaymentByTripRequest, opts ...grpc.CallOption) (*Payment, error)
+	// ListPaymentsByUser returns a rider's payments, newest first
+	ListPaymentsByUser(ctx context.Context, in *ListPaymentsByUserRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error)
+	// ListPaymentsByDriver returns the payments for a driver's trips, newest first
+	ListPaymentsByDriver(ctx context.Context, in *ListPaymentsByDriverRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error)
+}
+
+type paymentQueryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPaymentQueryServiceClient(cc grpc.ClientConnInterface) PaymentQueryServiceClient {
+	return &paymentQueryServiceClient{cc}
+}
+
+func (c *paymentQueryServiceClient) GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentQueryService_GetPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentQueryServiceClient) GetPaymentByTrip(ctx context.Context, in *GetPaymentByTripRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentQueryService_GetPaymentByTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentQueryServiceClient) ListPaymentsByUser(ctx context.Context, in *ListPaymentsByUserRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPaymentsResponse)
+	err := c.cc.Invoke(ctx, PaymentQueryService_ListPaymentsByUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentQueryServiceClient) ListPaymentsByDriver(ctx context.Context, in *ListPaymentsByDriverRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPaymentsResponse)
+	err := c.cc.Invoke(ctx, PaymentQueryService_ListPaymentsByDriver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PaymentQueryServiceServer is the server API for PaymentQueryService service.
+// All implementations must embed UnimplementedPaymentQueryServiceServer
+// for forward compatibility.
+//
+// PaymentQueryService answers read-only questions about payments for the API gateway and rider app
+type PaymentQueryServiceServer interface {
+	// GetPayment returns a payment by its ID
+	GetPayment(context.Context, *GetPaymentRequest) (*Payment, error)
+	// GetPaymentByTrip returns the latest payment for a trip
+	GetPaymentByTrip(context.Context, *GetPaymentByTripRequest) (*Payment, error)
+	// ListPaymentsByUser returns a rider's payments, newest first
+	ListPaymentsByUser(context.Context, *ListPaymentsByUserRequest) (*ListPaymentsResponse, error)
+	// ListPaymentsByDriver returns the payments for a driver's trips, newest first
+	ListPaymentsByDriver(context.Context, *ListPaymentsByDriverRequest) (*ListPaymentsResponse, error)
+	mustEmbedUnimplementedPaymentQueryServiceServer()
+}
+
+// UnimplementedPaymentQueryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPaymentQueryServiceServer struct{}
+
+func (UnimplementedPaymentQueryServiceServer) GetPayment(context.Context, *GetPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
+}
+func (UnimplementedPaymentQueryServiceServer) GetPaymentByTrip(context.Context, *GetPaymentByTripRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentByTrip not implemented")
+}
+func (UnimplementedPaymentQueryServiceServer) ListPaymentsByUser(context.Context, *ListPaymentsByUserRequest) (*ListPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPaymentsByUser not implemented")
+}
+func (UnimplementedPaymentQueryServiceServer) ListPaymentsByDriver(context.Context, *ListPaymentsByDriverRequest) (*ListPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPaymentsByDriver not implemented")
+}
+func (UnimplementedPaymentQueryServiceServer) mustEmbedUnimplementedPaymentQueryServiceServer() {}
+func (UnimplementedPaymentQueryServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafePaymentQueryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PaymentQueryServiceServer will
+// result in compilation errors.
+type UnsafePaymentQueryServiceServer interface {
+	mustEmbedUnimplementedPaymentQueryServiceServer()
+}
+
+func RegisterPaymentQueryServiceServer(s grpc.ServiceRegistrar, srv PaymentQueryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPaymentQueryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PaymentQueryService_ServiceDesc, srv)
+}
+
+func _PaymentQueryService_GetPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentQueryServiceServer).GetPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentQueryService_GetPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentQueryServiceServer).GetPayment(ctx, req.(*GetPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentQueryService_GetPaymentByTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentByTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentQueryServiceServer).GetPaymentByTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentQueryService_GetPaymentByTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentQueryServiceServer).GetPaymentByTrip(ctx, req.(*GetPaymentByTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentQueryService_ListPaymentsByUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentsByUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentQueryServiceServer).ListPaymentsByUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentQueryService_ListPaymentsByUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentQueryServiceServer).ListPaymentsByUser(ctx, req.(*ListPaymentsByUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentQueryService_ListPaymentsByDriver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentsByDriverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentQueryServiceServer).ListPaymentsByDriver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentQueryService_ListPaymentsByDriver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentQueryServiceServer).ListPaymentsByDriver(ctx, req.(*ListPaymentsByDriverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PaymentQueryService_ServiceDesc is the grpc.ServiceDesc for PaymentQueryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PaymentQueryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "payment.v1.PaymentQueryService",
+	HandlerType: (*PaymentQueryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPayment",
+			Handler:    _PaymentQueryService_GetPayment_Handler,
+		},
+		{
+			MethodName: "GetPaymentByTrip",
+			Handler:    _PaymentQueryService_GetPaymentByTrip_Handler,
+		},
+		{
+			MethodName: "ListPaymentsByUser",
+			Handler:    _PaymentQueryService_ListPaymentsByUser_Handler,
+		},
+		{
+			MethodName: "ListPaymentsByDriver",
+			Handler:    _PaymentQueryService_ListPaymentsByDriver_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "payment/v1/payment.proto",
+}
@@ -2,20 +2,39 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/rabbitmq/amqp091-go"
 	"github.com/ride4Low/contracts/env"
 	"github.com/ride4Low/contracts/events"
 	"github.com/ride4Low/contracts/pkg/otel"
 	"github.com/ride4Low/contracts/pkg/rabbitmq"
 	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
 	"github.com/ride4Low/payment-service/internal/infrastructure/messaging"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/cash"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/fake"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/resilient"
 	"github.com/ride4Low/payment-service/internal/infrastructure/payment/stripe"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
 	"github.com/ride4Low/payment-service/internal/infrastructure/persistence/mongodb"
+	"github.com/ride4Low/payment-service/internal/interface/auth"
 	"github.com/ride4Low/payment-service/internal/interface/consumer"
+	"github.com/ride4Low/payment-service/internal/interface/grpcapi"
+	"github.com/ride4Low/payment-service/internal/interface/payment"
+	"github.com/ride4Low/payment-service/internal/interface/payout"
+	"github.com/ride4Low/payment-service/internal/interface/webhook"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -23,12 +42,72 @@ var (
 	stripeSecretKey  = env.GetString("STRIPE_SECRET_KEY", "")
 	stripeSuccessURL = env.GetString("STRIPE_SUCCESS_URL", "")
 	stripeCancelURL  = env.GetString("STRIPE_CANCEL_URL", "")
+	stripeWebhookKey = env.GetString("STRIPE_WEBHOOK_SECRET", "")
 	jaegerEndpoint   = env.GetString("JAEGER_ENDPOINT", "jaeger:4317")
+	httpAddr         = env.GetString("HTTP_ADDR", ":8080") // public, serves only the Stripe webhook
+	grpcAddr         = env.GetString("GRPC_ADDR", ":9093")
+
+	// Operator routes such as payout runs listen on an internal-only address and require an admin key,
+	// configured as comma-separated name=key pairs
+	adminHTTPAddr = env.GetString("ADMIN_HTTP_ADDR", "127.0.0.1:8082")
+	adminAPIKeys  = env.GetString("ADMIN_API_KEYS", "")
+
+	// The payment REST API listens apart from the public webhook listener. The API gateway and partners
+	// authenticate REST and gRPC calls with these keys, configured as comma-separated name=key pairs,
+	// and forward the rider or driver they verified. Admin keys are accepted too and may see every payment.
+	apiHTTPAddr    = env.GetString("API_HTTP_ADDR", ":8081")
+	gatewayAPIKeys = env.GetString("GATEWAY_API_KEYS", "")
+
+	stripeConnectRefreshURL = env.GetString("STRIPE_CONNECT_REFRESH_URL", "")
+	stripeConnectReturnURL  = env.GetString("STRIPE_CONNECT_RETURN_URL", "")
+
+	x402FacilitatorURL = env.GetString("X402_FACILITATOR_URL", x402.DefaultFacilitatorURL)
+	x402Network        = env.GetString("X402_NETWORK", "base-sepolia")
+	x402PayTo          = env.GetString("X402_PAY_TO", "")
+	x402Asset          = env.GetString("X402_ASSET", "0x036CbD53842c5426634e7929541eC2318f3dCF7e") // USDC on Base Sepolia
+	x402ResourceURL    = env.GetString("X402_RESOURCE_URL", "")
+	// Networks riders may pay on as comma-separated network=asset:payTo[:feePayer] entries;
+	// when empty, riders pay on X402_NETWORK with X402_ASSET to X402_PAY_TO
+	x402Networks = env.GetString("X402_NETWORKS", "")
+	// JSON-RPC endpoints as comma-separated network=url pairs, used to look up settlements whose outcome
+	// was lost; payments left settling on networks without one are dead-lettered for manual reconciliation
+	x402RPCURLs = env.GetString("X402_RPC_URLS", "")
+	// x402 protocol version the payment requirements handed to riders' wallets are encoded in, 1 or 2
+	x402ProtocolVersion = env.GetString("X402_PROTOCOL_VERSION", "1")
+
+	// Provider routing: comma-separated key=provider lists, e.g. "card=stripe,cash=cash"
+	defaultProvider    = env.GetString("PAYMENT_DEFAULT_PROVIDER", stripe.ProviderName)
+	methodProviders    = env.GetString("PAYMENT_METHOD_PROVIDERS", "card=stripe,cash=cash,crypto=x402")
+	regionProviders    = env.GetString("PAYMENT_REGION_PROVIDERS", "")
+	currencyProviders  = env.GetString("PAYMENT_CURRENCY_PROVIDERS", "")
+	enableFakeProvider = env.GetString("PAYMENT_FAKE_PROVIDER_ENABLED", "false")
+
+	// Fare currency of trips whose record has none; other fares are charged in the trip's currency
+	defaultCurrency = env.GetString("PAYMENT_DEFAULT_CURRENCY", "USD")
+
+	// Fare validation: trip statuses that may be charged, how long a quoted fare stays valid,
+	// and per-currency bounds in minor units as comma-separated currency=min:max pairs
+	payableTripStatuses = env.GetString("PAYMENT_PAYABLE_TRIP_STATUSES", "accepted")
+	fareTTL             = env.GetString("PAYMENT_FARE_TTL", "2h")
+	fareLimits          = env.GetString("PAYMENT_FARE_LIMITS", "USD=50:100000,EUR=50:100000,JPY=100:1000000")
+
+	// Consumer failure handling: comma-separated delays before each retry of a transiently failed message
+	consumerRetryDelays = env.GetString("CONSUMER_RETRY_DELAYS", "5s,30s,2m")
+	deadLetterExchange  = env.GetString("DEAD_LETTER_EXCHANGE", "payment.dlx")
+	deadLetterQueue     = env.GetString("DEAD_LETTER_QUEUE", events.PaymentTripResponseQueue+".dead_letter")
+
+	// platformCommissionBps is the platform's share of each fare in basis points (2000 = 20%)
+	platformCommissionBps = env.GetString("PLATFORM_COMMISSION_BPS", "2000")
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
+	commissionBps, err := strconv.ParseInt(platformCommissionBps, 10, 64)
+	if err != nil || commissionBps < 0 || commissionBps > 10000 {
+		log.Fatalf("invalid PLATFORM_COMMISSION_BPS %q: must be between 0 and 10000", platformCommissionBps)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -64,9 +143,27 @@ func main() {
 		}
 	}()
 
-	// Infrastructure layer: Create MongoDB payment repository (adapter)
+	// Infrastructure layer: Create MongoDB trip and payment repositories (adapters)
 	mongoDB := mongodb.GetDatabase(mongoClient, mongoCfg.Database)
-	paymentRepo := mongodb.NewTripRepository(mongoDB)
+	tripRepo := mongodb.NewTripRepository(mongoDB)
+	paymentRepo := mongodb.NewPaymentRepository(mongoDB)
+	if err := paymentRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to ensure payment indexes: %v", err)
+	}
+	outboxRepo := mongodb.NewOutboxRepository(mongoDB)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to ensure outbox indexes: %v", err)
+	}
+	driverAccountRepo := mongodb.NewDriverAccountRepository(mongoDB)
+	payoutRepo := mongodb.NewPayoutRepository(mongoDB)
+	if err := payoutRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to ensure payout indexes: %v", err)
+	}
+	nonceRepo := mongodb.NewNonceRepository(mongoDB)
+	if err := nonceRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("failed to ensure x402 nonce indexes: %v", err)
+	}
+	transactor := mongodb.NewTransactor(mongoClient)
 
 	rmq, err := rabbitmq.NewRabbitMQ(rabbitMQURI)
 	if err != nil {
@@ -74,27 +171,309 @@ func main() {
 	}
 	defer rmq.Close()
 
-	// Infrastructure layer: Create Stripe payment provider (adapter)
-	stripeProvider := stripe.NewProvider(stripe.PaymentConfig{
-		StripeSecretKey: stripeSecretKey,
-		SuccessURL:      stripeSuccessURL,
-		CancelURL:       stripeCancelURL,
+	// Infrastructure layer: Create Stripe payment provider (adapter), retried with a circuit breaker
+	stripeProvider := resilient.NewProvider(stripe.NewProvider(stripe.PaymentConfig{
+		StripeSecretKey:     stripeSecretKey,
+		StripeWebhookSecret: stripeWebhookKey,
+		SuccessURL:          stripeSuccessURL,
+		CancelURL:           stripeCancelURL,
+	}), resilient.DefaultConfig(stripe.IsRetryable))
+
+	// Infrastructure layer: Register the payment providers (adapters); x402 is enabled when a receiving wallet is set
+	paymentProviders := []application.PaymentProvider{stripeProvider, cash.NewProvider()}
+	cryptoNetworks := parseX402Networks(x402Networks)
+	if len(cryptoNetworks) == 0 && x402PayTo != "" {
+		cryptoNetworks = parseX402Networks(x402Network + "=" + x402Asset + ":" + x402PayTo)
+	}
+	if len(cryptoNetworks) > 0 {
+		protocolVersion, err := strconv.Atoi(x402ProtocolVersion)
+		if err != nil || (protocolVersion != x402.ProtocolV1 && protocolVersion != x402.ProtocolV2) {
+			log.Fatalf("invalid X402_PROTOCOL_VERSION %q: expected 1 or 2", x402ProtocolVersion)
+		}
+		x402Provider := x402.NewProvider(x402.ProviderConfig{
+			Networks:          cryptoNetworks,
+			ResourceURL:       x402ResourceURL,
+			MaxTimeoutSeconds: 300,
+			ProtocolVersion:   protocolVersion,
+		}, x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: x402FacilitatorURL}), nonceRepo, x402.NewRPCChainReader(parseMapping(x402RPCURLs, strings.TrimSpace)))
+		paymentProviders = append(paymentProviders, resilient.NewCryptoProvider(x402Provider, resilient.DefaultConfig(x402.IsRetryable)))
+	}
+	if enableFakeProvider == "true" {
+		paymentProviders = append(paymentProviders, fake.NewProvider())
+	}
+
+	methods := parseMapping(methodProviders, strings.ToLower)
+	if len(cryptoNetworks) == 0 && methods[application.PaymentMethodCrypto] == x402.ProviderName {
+		delete(methods, application.PaymentMethodCrypto)
+	}
+	providerRegistry, err := application.NewProviderRegistry(application.ProviderRegistryConfig{
+		Default:          defaultProvider,
+		Methods:          methods,
+		RegionDefaults:   parseMapping(regionProviders, strings.ToLower),
+		CurrencyDefaults: parseMapping(currencyProviders, strings.ToUpper),
+	}, paymentProviders...)
+	if err != nil {
+		log.Fatalf("invalid payment provider configuration: %v", err)
+	}
+
+	currencyConfig := application.CurrencyConfig{Default: strings.ToUpper(defaultCurrency)}
+	if _, err := domain.CurrencyExponent(currencyConfig.Default); err != nil {
+		log.Fatalf("invalid PAYMENT_DEFAULT_CURRENCY: %v", err)
+	}
+
+	fareTTLDuration, err := time.ParseDuration(fareTTL)
+	if err != nil {
+		log.Fatalf("invalid PAYMENT_FARE_TTL: %v", err)
+	}
+	fareValidator := application.NewFareValidator(application.FarePolicy{
+		PayableStatuses: parseList(payableTripStatuses),
+		FareTTL:         fareTTLDuration,
+		Limits:          parseFareLimits(fareLimits),
 	})
 
-	// Infrastructure layer: Create RabbitMQ event publisher (adapter)
+	// Infrastructure layer: Create Stripe Connect payout provider (adapter)
+	stripePayoutProvider := stripe.NewPayoutProvider(stripe.ConnectConfig{
+		StripeSecretKey:      stripeSecretKey,
+		OnboardingRefreshURL: stripeConnectRefreshURL,
+		OnboardingReturnURL:  stripeConnectReturnURL,
+	})
+
+	// Infrastructure layer: Create event publisher (adapter) that writes to the transactional outbox,
+	// and the relay that drains the outbox to RabbitMQ
 	rmqPublisher := rabbitmq.NewPublisher(rmq)
-	eventPublisher := messaging.NewRabbitMQPublisher(rmqPublisher)
+	eventPublisher := messaging.NewRabbitMQPublisher(messaging.NewOutboxWriter(outboxRepo))
+	outboxRelay := messaging.NewOutboxRelay(outboxRepo, rmqPublisher, messaging.DefaultOutboxRelayConfig())
+	go outboxRelay.Run(ctx)
+
+	// Application layer: Create payment service with provider registry, fare validator, publisher, repositories, and transactor
+	paymentSvc := application.NewPaymentService(providerRegistry, currencyConfig, fareValidator, eventPublisher, tripRepo, paymentRepo, transactor)
+
+	// Application layer: Create payment query service answering status and history lookups
+	paymentQuerySvc := application.NewPaymentQueryService(paymentRepo)
 
-	// Application layer: Create payment service with provider, publisher, and repository
-	paymentSvc := application.NewPaymentService(stripeProvider, eventPublisher, paymentRepo)
+	// Application layer: Create payout service paying drivers their share of captured fares
+	payoutSvc := application.NewPayoutService(stripePayoutProvider, paymentRepo, driverAccountRepo, payoutRepo, transactor, commissionBps)
 
-	// Interface layer: Create event handler with payment service
+	// Interface layer: Create event handler with payment service, dead-lettering messages it cannot process
 	eventHandler := consumer.NewEventHandler(paymentSvc)
+	deadLetterConfig := consumer.DeadLetterConfig{
+		Queue:              events.PaymentTripResponseQueue,
+		DeadLetterExchange: deadLetterExchange,
+		RetryDelays:        parseDurations(consumerRetryDelays),
+	}
+
+	// Infrastructure layer: Declare the dead-letter and retry queues on a dedicated channel
+	amqpConn, err := amqp091.Dial(rabbitMQURI)
+	if err != nil {
+		log.Fatal("failed to connect to RabbitMQ: ", err)
+	}
+	defer amqpConn.Close()
+	deadLetterCh, err := amqpConn.Channel()
+	if err != nil {
+		log.Fatal("failed to open RabbitMQ channel: ", err)
+	}
+	topology := messaging.DeadLetterTopology{
+		Queue:              deadLetterConfig.Queue,
+		DeadLetterExchange: deadLetterConfig.DeadLetterExchange,
+		DeadLetterQueue:    deadLetterQueue,
+	}
+	for i, delay := range deadLetterConfig.RetryDelays {
+		topology.RetryQueues = append(topology.RetryQueues, messaging.RetryQueue{Name: deadLetterConfig.RetryQueue(i + 1), Delay: delay})
+	}
+	if err := messaging.DeclareDeadLetterTopology(deadLetterCh, topology); err != nil {
+		log.Fatalf("failed to declare dead-letter topology: %v", err)
+	}
+	deliveryPublisher, err := messaging.NewAMQPDeliveryPublisher(deadLetterCh)
+	if err != nil {
+		log.Fatalf("failed to create dead-letter publisher: %v", err)
+	}
+	deadLetterHandler := consumer.NewDeadLetterHandler(eventHandler, deliveryPublisher, deadLetterConfig)
 
 	// Start consuming messages
-	msgConsumer := rabbitmq.NewConsumer(rmq, eventHandler)
+	msgConsumer := rabbitmq.NewConsumer(rmq, deadLetterHandler)
 	go msgConsumer.Consume(ctx, events.PaymentTripResponseQueue)
 
+	// Interface layer: Expose Stripe webhooks over HTTP; they authenticate with the webhook signature
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhooks/stripe", webhook.NewStripeHandler(stripe.NewWebhookParser(stripeWebhookKey), paymentSvc))
+
+	httpServer := &http.Server{Addr: httpAddr, Handler: mux}
+	go func() {
+		log.Printf("starting HTTP server on %s", httpAddr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server error: %v", err)
+			cancel()
+		}
+	}()
+
+	// Interface layer: Expose the payment REST API to the gateway and partners on its own listener.
+	// Every request is authenticated and acts for the principal the gateway verified.
+	apiKeys := auth.NewKeyStore()
+	for name, key := range parseMapping(gatewayAPIKeys, strings.TrimSpace) {
+		apiKeys.Add(key, auth.Client{Name: name})
+	}
+	for name, key := range parseMapping(adminAPIKeys, strings.TrimSpace) {
+		apiKeys.Add(key, auth.Client{Name: name, Admin: true})
+	}
+	if gatewayAPIKeys == "" {
+		log.Printf("GATEWAY_API_KEYS is empty, only admin keys can call the payment API")
+	}
+	apiMux := http.NewServeMux()
+	payment.NewHandler(paymentSvc, paymentQuerySvc).Register(apiMux)
+
+	apiServer := &http.Server{Addr: apiHTTPAddr, Handler: auth.RequirePrincipal(apiKeys, apiMux)}
+	go func() {
+		log.Printf("starting API HTTP server on %s", apiHTTPAddr)
+		if err := apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API HTTP server error: %v", err)
+			cancel()
+		}
+	}()
+
+	// Interface layer: Expose driver payouts to operators on the internal admin listener
+	adminKeys := auth.NewKeyStore()
+	for name, key := range parseMapping(adminAPIKeys, strings.TrimSpace) {
+		adminKeys.Add(key, auth.Client{Name: name, Admin: true})
+	}
+	if adminAPIKeys == "" {
+		log.Printf("ADMIN_API_KEYS is empty, admin routes reject every request")
+	}
+	adminMux := http.NewServeMux()
+	payout.NewHandler(payoutSvc).Register(adminMux)
+
+	adminServer := &http.Server{Addr: adminHTTPAddr, Handler: auth.RequireAdmin(adminKeys, adminMux)}
+	go func() {
+		log.Printf("starting admin HTTP server on %s", adminHTTPAddr)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("admin HTTP server error: %v", err)
+			cancel()
+		}
+	}()
+
+	// Interface layer: Expose payment queries over gRPC, traced with the OTel providers from otel.Setup.
+	// Every call is authenticated with the API keys and only sees the payments of its principal.
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.UnaryInterceptor(grpcapi.UnaryAuthInterceptor(apiKeys)),
+	)
+	grpcapi.NewServer(paymentQuerySvc).Register(grpcServer)
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", grpcAddr, err)
+	}
+	go func() {
+		log.Printf("starting gRPC server on %s", grpcAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("gRPC server error: %v", err)
+			cancel()
+		}
+	}()
+
 	<-ctx.Done()
 	log.Println("shutting down consumer")
+	grpcServer.GracefulStop()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shutdown HTTP server: %v", err)
+	}
+	if err := apiServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shutdown API HTTP server: %v", err)
+	}
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shutdown admin HTTP server: %v", err)
+	}
+}
+
+// parseMapping parses a comma-separated list of key=value pairs, normalizing the keys
+func parseMapping(list string, normalizeKey func(string) string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			log.Fatalf("invalid mapping %q: expected key=value", pair)
+		}
+		result[normalizeKey(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+// parseX402Networks parses comma-separated network=asset:payTo[:feePayer] entries such as
+// "base=0xUSDC:0xWallet,solana=USDCMint:SolanaWallet:FeePayer", keeping their order.
+// Solana entries must name the facilitator's fee payer, which riders' wallets sign the transfer for.
+func parseX402Networks(list string) []x402.NetworkConfig {
+	var networks []x402.NetworkConfig
+	for _, entry := range parseList(list) {
+		network, target, ok := strings.Cut(entry, "=")
+		parts := strings.Split(target, ":")
+		if !ok || len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			log.Fatalf("invalid x402 network %q: expected network=asset:payTo[:feePayer]", entry)
+		}
+		network = strings.TrimSpace(network)
+		family, ok := x402.NetworkFamily(network)
+		if !ok {
+			log.Fatalf("unsupported x402 network %q", network)
+		}
+		config := x402.NetworkConfig{Network: network, Asset: parts[0], PayTo: parts[1]}
+		if len(parts) == 3 {
+			config.FeePayer = parts[2]
+		}
+		if family == x402.FamilySVM && config.FeePayer == "" {
+			log.Fatalf("invalid x402 network %q: Solana networks require network=asset:payTo:feePayer", entry)
+		}
+		networks = append(networks, config)
+	}
+	return networks
+}
+
+// parseList parses a comma-separated list, dropping empty items
+func parseList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFareLimits parses comma-separated currency=min:max pairs such as "USD=50:100000"
+func parseFareLimits(list string) map[string]application.FareLimits {
+	limits := make(map[string]application.FareLimits)
+	for currency, bounds := range parseMapping(list, strings.ToUpper) {
+		if _, err := domain.CurrencyExponent(currency); err != nil {
+			log.Fatalf("invalid fare limit currency: %v", err)
+		}
+		minValue, maxValue, ok := strings.Cut(bounds, ":")
+		if !ok {
+			log.Fatalf("invalid fare limits %q for %s: expected min:max", bounds, currency)
+		}
+		minAmount, minErr := strconv.ParseInt(minValue, 10, 64)
+		maxAmount, maxErr := strconv.ParseInt(maxValue, 10, 64)
+		if minErr != nil || maxErr != nil || minAmount < 0 || (maxAmount != 0 && maxAmount < minAmount) {
+			log.Fatalf("invalid fare limits %q for %s", bounds, currency)
+		}
+		limits[currency] = application.FareLimits{Min: minAmount, Max: maxAmount}
+	}
+	return limits
+}
+
+// parseDurations parses a comma-separated list of durations such as "5s,30s,2m"
+func parseDurations(value string) []time.Duration {
+	var durations []time.Duration
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(item))
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid duration %q: %v", item, err)
+		}
+		durations = append(durations, d)
+	}
+	return durations
 }
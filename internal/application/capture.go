@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// AuthorizePaymentWithCard creates a manual-capture session that places a hold for the
+// estimated trip fare; the hold is later captured with CapturePayment or released with ReleasePayment
+func (s *paymentService) AuthorizePaymentWithCard(ctx context.Context, tripID, userID string) error {
+	return s.createSessionForTrip(ctx, PaymentSelection{Method: PaymentMethodCard}, tripID, userID, domain.CaptureMethodManual)
+}
+
+// CapturePayment charges the final fare of a trip from its authorized hold.
+// Amount is in minor units and may not exceed the authorized amount.
+func (s *paymentService) CapturePayment(ctx context.Context, tripID string, amount int64) error {
+	payment, err := s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	// Redelivered capture commands are acknowledged without charging again
+	if payment.Status == domain.PaymentStatusCaptured && payment.CaptureMethod == domain.CaptureMethodManual {
+		return nil
+	}
+
+	if err := payment.CheckCapture(amount); err != nil {
+		return err
+	}
+
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+
+	idempotencyKey := fmt.Sprintf("payment-capture-%s", payment.ID)
+	if err := provider.CapturePayment(ctx, payment.ProviderPaymentID, amount, idempotencyKey); err != nil {
+		return err
+	}
+
+	if err := payment.Capture(amount); err != nil {
+		return err
+	}
+
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentSucceeded(ctx, &PaymentSucceededEvent{
+			UserID:           payment.UserID,
+			PaymentEventData: paymentEventData(payment),
+		})
+	})
+}
+
+// ReleasePayment releases the payment of a cancelled trip without charging the rider: an authorized
+// hold is cancelled, and a checkout the rider has not completed yet is expired so the trip is no
+// longer blocked by its open payment. A checkout the rider completes meanwhile is refunded.
+func (s *paymentService) ReleasePayment(ctx context.Context, tripID string) error {
+	payment, err := s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	// A payment completed while it was being released was already returned to the rider
+	if payment.Status == domain.PaymentStatusCanceled || payment.ReleaseRequested && payment.IsPaid() {
+		return nil
+	}
+
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+
+	// Recorded before the provider call: a completion that reaches the provider first is then
+	// refunded or released by HandleProviderEvent instead of charging the rider for a cancelled trip
+	if !payment.ReleaseRequested {
+		if err := payment.RequestRelease(); err != nil {
+			return err
+		}
+		if err := s.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+	}
+
+	idempotencyKey := fmt.Sprintf("payment-release-%s", payment.ID)
+	if payment.Status == domain.PaymentStatusSessionCreated {
+		err = provider.ExpirePaymentSession(ctx, payment.ProviderSessionID, idempotencyKey)
+	} else {
+		err = provider.CancelPayment(ctx, payment.ProviderPaymentID, idempotencyKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.cancelPayment(ctx, payment)
+}
+
+func (s *paymentService) cancelPayment(ctx context.Context, payment *domain.Payment) error {
+	if err := payment.Cancel(); err != nil {
+		return err
+	}
+
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentCanceled(ctx, &PaymentCanceledEvent{
+			UserID:           payment.UserID,
+			PaymentEventData: paymentEventData(payment),
+		})
+	})
+}
+
+// releaseAuthorizedAfterCancel cancels a hold the provider placed after the trip was cancelled
+func (s *paymentService) releaseAuthorizedAfterCancel(ctx context.Context, payment *domain.Payment) error {
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+	if err := provider.CancelPayment(ctx, payment.ProviderPaymentID, fmt.Sprintf("payment-release-%s", payment.ID)); err != nil {
+		return err
+	}
+	return s.cancelPayment(ctx, payment)
+}
+
+// refundCapturedAfterCancel returns the funds of a payment the rider completed after the trip was cancelled
+func (s *paymentService) refundCapturedAfterCancel(ctx context.Context, payment *domain.Payment) error {
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+
+	const requestID = "trip-cancelled"
+	amount := payment.RefundableAmount()
+	idempotencyKey := fmt.Sprintf("payment-refund-%s-%s", payment.ID, requestID)
+	refundID, err := provider.RefundPayment(ctx, payment.ProviderPaymentID, amount, "trip cancelled", idempotencyKey)
+	if err != nil {
+		return err
+	}
+	if err := payment.ApplyRefund(requestID, refundID, amount, "trip cancelled"); err != nil {
+		return err
+	}
+
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentRefunded(ctx, &PaymentRefundedEvent{
+			UserID:           payment.UserID,
+			PaymentEventData: paymentEventData(payment),
+			RefundedAmount:   payment.RefundedAmount,
+		})
+	})
+}
@@ -0,0 +1,251 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/contracts/types"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newAuthorizedPayment(repo *mockPaymentRepository) *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 2500, "usd", "mock")
+	payment.ID = "payment-1"
+	payment.CaptureMethod = domain.CaptureMethodManual
+	payment.Status = domain.PaymentStatusAuthorized
+	payment.AuthorizedAmount = 2500
+	payment.ProviderPaymentID = "pi_test_123"
+	repo.payments[payment.ID] = payment
+	return payment
+}
+
+func TestPaymentService_AuthorizePaymentWithCard(t *testing.T) {
+	trip := &types.Trip{
+		UserID:   "user-1",
+		Status:   "accepted",
+		RideFare: &types.RideFare{ID: primitive.NewObjectID(), TotalPriceInCents: 2500},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+	repo := newMockPaymentRepository()
+	provider := &mockPaymentProvider{sessionID: "cs_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{trip: trip}, repo, &mockTransactor{})
+
+	if err := svc.AuthorizePaymentWithCard(context.Background(), "trip-1", "user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if provider.authorizations != 1 {
+		t.Errorf("expected an authorization session, got %d", provider.authorizations)
+	}
+
+	payment := repo.payments["payment-trip-1"]
+	if payment.CaptureMethod != domain.CaptureMethodManual || payment.Status != domain.PaymentStatusSessionCreated {
+		t.Errorf("expected manual payment with session, got %s %s", payment.CaptureMethod, payment.Status)
+	}
+}
+
+func TestPaymentService_CapturePayment(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newAuthorizedPayment(repo)
+	provider := &mockPaymentProvider{}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.CapturePayment(context.Background(), "trip-1", 1800); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if provider.captureAmount != 1800 || provider.idempotencyKey != "payment-capture-payment-1" {
+		t.Errorf("unexpected capture call: %d %s", provider.captureAmount, provider.idempotencyKey)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusCaptured || payment.Amount != 1800 {
+		t.Errorf("expected captured 1800, got %s %d", payment.Status, payment.Amount)
+	}
+
+	succeeded, ok := publisher.published[0].(*PaymentSucceededEvent)
+	if !ok || succeeded.Amount != 1800 {
+		t.Errorf("expected succeeded event for 1800, got %+v", publisher.published[0])
+	}
+
+	// A redelivered capture command is acknowledged without charging again
+	if err := svc.CapturePayment(context.Background(), "trip-1", 1800); err != nil {
+		t.Fatalf("unexpected error on redelivery: %v", err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected a single provider capture, got %d", provider.calls)
+	}
+}
+
+func TestPaymentService_CapturePayment_AboveHold(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newAuthorizedPayment(repo)
+	provider := &mockPaymentProvider{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.CapturePayment(context.Background(), "trip-1", 3000)
+	if !errors.Is(err, domain.ErrInvalidCaptureAmount) {
+		t.Fatalf("expected ErrInvalidCaptureAmount, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_ReleasePayment(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newAuthorizedPayment(repo)
+	provider := &mockPaymentProvider{}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !provider.canceled {
+		t.Error("expected provider to cancel the hold")
+	}
+	if repo.payments["payment-1"].Status != domain.PaymentStatusCanceled {
+		t.Errorf("expected status '%s', got '%s'", domain.PaymentStatusCanceled, repo.payments["payment-1"].Status)
+	}
+	if _, ok := publisher.published[0].(*PaymentCanceledEvent); !ok {
+		t.Errorf("expected *PaymentCanceledEvent, got %T", publisher.published[0])
+	}
+}
+
+func TestPaymentService_ReleasePayment_OpenCheckout(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newAuthorizedPayment(repo)
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.ProviderSessionID = "cs_test_123"
+	payment.ProviderPaymentID = ""
+	payment.AuthorizedAmount = 0
+	provider := &mockPaymentProvider{}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !provider.expired || provider.canceled {
+		t.Error("expected provider to expire the checkout session")
+	}
+	stored := repo.payments["payment-1"]
+	if stored.Status != domain.PaymentStatusCanceled || stored.IsOpen() {
+		t.Errorf("expected a closed canceled payment, got '%s'", stored.Status)
+	}
+	if _, ok := publisher.published[0].(*PaymentCanceledEvent); !ok {
+		t.Errorf("expected *PaymentCanceledEvent, got %T", publisher.published[0])
+	}
+}
+
+func TestPaymentService_ReleasePayment_CompletedDuringRelease(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newAuthorizedPayment(repo)
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.CaptureMethod = domain.CaptureMethodAutomatic
+	payment.ProviderSessionID = "cs_test_123"
+	payment.ProviderPaymentID = ""
+	payment.AuthorizedAmount = 0
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	// The rider completes the checkout just before it is expired, so the provider refuses to expire it
+	provider.onExpire = func() {
+		err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+			Type:              ProviderEventPaymentSucceeded,
+			PaymentID:         "payment-1",
+			ProviderPaymentID: "pi_test_456",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error handling the completion: %v", err)
+		}
+		provider.err = errors.New("checkout session is already complete")
+	}
+
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); err == nil {
+		t.Fatal("expected the expiry error")
+	}
+
+	stored := repo.payments["payment-1"]
+	if stored.Status != domain.PaymentStatusRefunded || stored.RefundedAmount != stored.Amount {
+		t.Errorf("expected the completed payment to be refunded in full, got %s %d", stored.Status, stored.RefundedAmount)
+	}
+	if provider.refundAmount != stored.Amount || provider.idempotencyKey != "payment-refund-payment-1-trip-cancelled" {
+		t.Errorf("unexpected refund of %d with key %s", provider.refundAmount, provider.idempotencyKey)
+	}
+	if _, ok := publisher.published[0].(*PaymentRefundedEvent); !ok || len(publisher.published) != 1 {
+		t.Errorf("expected only *PaymentRefundedEvent, got %v", publisher.published)
+	}
+
+	// The redelivered release is acknowledged once the payment was refunded
+	provider.err, provider.onExpire = nil, nil
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); err != nil {
+		t.Fatalf("unexpected error on redelivery: %v", err)
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_AuthorizedAfterRelease(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newAuthorizedPayment(repo)
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.ReleaseRequested = true
+	provider := &mockPaymentProvider{}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:              ProviderEventPaymentAuthorized,
+		PaymentID:         "payment-1",
+		ProviderPaymentID: "pi_test_456",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !provider.canceled || repo.payments["payment-1"].Status != domain.PaymentStatusCanceled {
+		t.Errorf("expected the late hold to be cancelled, got %s", repo.payments["payment-1"].Status)
+	}
+	if _, ok := publisher.published[0].(*PaymentCanceledEvent); !ok {
+		t.Errorf("expected *PaymentCanceledEvent, got %T", publisher.published[0])
+	}
+}
+
+func TestPaymentService_ReleasePayment_Captured(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newAuthorizedPayment(repo)
+	payment.Status = domain.PaymentStatusCaptured
+	provider := &mockPaymentProvider{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_ReleasePayment_ProviderError(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newAuthorizedPayment(repo)
+	provider := &mockPaymentProvider{err: errors.New("stripe unavailable")}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ReleasePayment(context.Background(), "trip-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if repo.payments["payment-1"].Status != domain.PaymentStatusAuthorized {
+		t.Errorf("expected payment to stay authorized, got %s", repo.payments["payment-1"].Status)
+	}
+	if len(publisher.published) != 0 {
+		t.Error("expected no event to be published")
+	}
+}
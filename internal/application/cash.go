@@ -0,0 +1,45 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// ConfirmCashPayment records that the trip's driver collected the fare in cash. The driver keeps the fare,
+// so payout runs debit the platform's commission from their card earnings instead of paying it out.
+func (s *paymentService) ConfirmCashPayment(ctx context.Context, tripID, driverID string) error {
+	payment, err := s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	if payment.DriverID != driverID {
+		return &domain.TripError{TripID: tripID, Err: domain.ErrNotTripDriver, Cause: fmt.Errorf("driver %s", driverID)}
+	}
+
+	cash, err := s.providers.Resolve(PaymentSelection{Method: PaymentMethodCash}, payment.Currency)
+	if err != nil {
+		return err
+	}
+	if payment.Provider != cash.Name() {
+		return fmt.Errorf("%w: payment %s is not a cash payment", domain.ErrPaymentMethodMismatch, payment.ID)
+	}
+
+	// Redelivered confirmations are acknowledged without publishing again
+	if payment.Status == domain.PaymentStatusCaptured {
+		return nil
+	}
+
+	if err := payment.MarkCollectedByDriver(); err != nil {
+		return err
+	}
+
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentSucceeded(ctx, &PaymentSucceededEvent{
+			UserID:           payment.UserID,
+			PaymentEventData: paymentEventData(payment),
+		})
+	})
+}
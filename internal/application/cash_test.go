@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+func newCashPayment(repo *mockPaymentRepository) *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 1850, "USD", "cash")
+	payment.ID = "payment-1"
+	payment.Status = domain.PaymentStatusSessionCreated
+	repo.payments[payment.ID] = payment
+	return payment
+}
+
+func TestPaymentService_ConfirmCashPayment(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCashPayment(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, &mockPaymentProvider{name: "cash"}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ConfirmCashPayment(context.Background(), "trip-1", "driver-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment := repo.payments["payment-1"]; payment.Status != domain.PaymentStatusCaptured || !payment.CollectedByDriver || payment.IsPayable() {
+		t.Errorf("expected a captured fare held by the driver, got %s collected by driver %v", payment.Status, payment.CollectedByDriver)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(publisher.published))
+	}
+
+	// A redelivered confirmation is a no-op
+	if err := svc.ConfirmCashPayment(context.Background(), "trip-1", "driver-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("expected no further events, got %d", len(publisher.published))
+	}
+}
+
+func TestPaymentService_ConfirmCashPayment_Rejected(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCashPayment(repo)
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, &mockPaymentProvider{name: "cash"}), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.ConfirmCashPayment(context.Background(), "trip-1", "driver-2"); !errors.Is(err, domain.ErrNotTripDriver) {
+		t.Errorf("expected ErrNotTripDriver for another driver, got %v", err)
+	}
+
+	payment.Provider = "mock"
+	if err := svc.ConfirmCashPayment(context.Background(), "trip-1", "driver-1"); !errors.Is(err, domain.ErrPaymentMethodMismatch) {
+		t.Errorf("expected ErrPaymentMethodMismatch for a card payment, got %v", err)
+	}
+	if status := repo.payments["payment-1"].Status; status != domain.PaymentStatusSessionCreated {
+		t.Errorf("expected status unchanged, got %s", status)
+	}
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// CreatePaymentSessionWithCrypto starts a crypto payment for the trip fare. The published session ID
+// carries the payment requirements the rider's wallet signs and sends back through PayWithCrypto.
+func (s *paymentService) CreatePaymentSessionWithCrypto(ctx context.Context, tripID, userID string) error {
+	return s.createSessionForTrip(ctx, PaymentSelection{Method: PaymentMethodCrypto}, tripID, userID, domain.CaptureMethodAutomatic)
+}
+
+// PayWithCrypto settles the rider's signed payment payload on-chain and records the settlement transaction
+func (s *paymentService) PayWithCrypto(ctx context.Context, tripID, userID, paymentPayload string) error {
+	payment, err := s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	if payment.UserID != userID {
+		return &domain.TripError{TripID: tripID, Err: domain.ErrNotTripOwner, Cause: fmt.Errorf("user %s", userID)}
+	}
+
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+	cryptoProvider, ok := provider.(CryptoPaymentProvider)
+	if !ok {
+		return fmt.Errorf("%w: payment %s is not a crypto payment", domain.ErrPaymentMethodMismatch, payment.ID)
+	}
+
+	// Redelivered commands are acknowledged without settling again
+	if payment.Status == domain.PaymentStatusCaptured && payment.TransactionHash != "" {
+		return nil
+	}
+
+	payloadHash := hashPayload(paymentPayload)
+	switch payment.Status {
+	case domain.PaymentStatusSettling:
+		if payment.SettlingPayload != payloadHash {
+			return fmt.Errorf("%w: payment %s is settling another payload", domain.ErrInvalidTransition, payment.ID)
+		}
+		return s.reconcileSettlement(ctx, payment, cryptoProvider, paymentPayload)
+	case domain.PaymentStatusSessionCreated:
+	default:
+		return &domain.InvalidTransitionError{PaymentID: payment.ID, From: payment.Status, To: domain.PaymentStatusSettling}
+	}
+
+	// Recorded before settling, so a redelivery after a crash looks the transaction up instead of settling blind
+	if err := payment.StartSettlement(payloadHash); err != nil {
+		return err
+	}
+	if err := s.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+		return err
+	}
+
+	settlement, err := cryptoProvider.SettlePayment(ctx, payment.ProviderSessionID, paymentPayload)
+	if err != nil {
+		// Other failures leave the payment settling, as the payload may still have reached the chain
+		if errors.Is(err, domain.ErrPaymentDeclined) {
+			return s.abandonSettlement(ctx, payment, err)
+		}
+		return err
+	}
+	return s.completeSettlement(ctx, payment, settlement)
+}
+
+// reconcileSettlement resolves a payment left settling by an earlier delivery of the same payload
+func (s *paymentService) reconcileSettlement(ctx context.Context, payment *domain.Payment, cryptoProvider CryptoPaymentProvider, paymentPayload string) error {
+	settlement, err := cryptoProvider.FindSettlement(ctx, payment.ProviderSessionID, paymentPayload)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentDeclined) {
+			return s.abandonSettlement(ctx, payment, err)
+		}
+		if errors.Is(err, errors.ErrUnsupported) {
+			// The provider cannot look this settlement up (e.g. a non-EVM network), so it would stay settling
+			// and block the trip forever. The session is reopened and the dead-lettered command kept for
+			// reconciliation.
+			log.Printf("settlement of payment %s cannot be looked up, reopening its session: %v", payment.ID, err)
+			return s.abandonSettlement(ctx, payment, err)
+		}
+		return err
+	}
+	if settlement == nil {
+		return fmt.Errorf("settlement of payment %s not found on-chain yet", payment.ID)
+	}
+	return s.completeSettlement(ctx, payment, settlement)
+}
+
+func (s *paymentService) completeSettlement(ctx context.Context, payment *domain.Payment, settlement *CryptoSettlement) error {
+	if err := payment.MarkSettled(settlement.TransactionHash); err != nil {
+		return err
+	}
+
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentSucceeded(ctx, &PaymentSucceededEvent{
+			UserID:           payment.UserID,
+			PaymentEventData: paymentEventData(payment),
+			TransactionHash:  payment.TransactionHash,
+		})
+	})
+}
+
+// abandonSettlement reopens the session for another payload and returns the provider's refusal
+func (s *paymentService) abandonSettlement(ctx context.Context, payment *domain.Payment, cause error) error {
+	if err := payment.AbandonSettlement(); err != nil {
+		return err
+	}
+	if err := s.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+		return err
+	}
+	return cause
+}
+
+// hashPayload identifies a payment payload without storing the rider's signature
+func hashPayload(paymentPayload string) string {
+	sum := sha256.Sum256([]byte(paymentPayload))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,237 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ride4Low/contracts/types"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockCryptoProvider is a mock implementation of CryptoPaymentProvider for testing
+type mockCryptoProvider struct {
+	mockPaymentProvider
+	settlement *CryptoSettlement
+	settleErr  error
+	settled    int
+	sessionID  string
+	found      *CryptoSettlement // settlement FindSettlement looks up
+	findErr    error
+	lookups    int
+}
+
+func (m *mockCryptoProvider) Name() string {
+	return "crypto"
+}
+
+func (m *mockCryptoProvider) SettlePayment(ctx context.Context, sessionID string, paymentPayload string) (*CryptoSettlement, error) {
+	m.settled++
+	m.sessionID = sessionID
+	return m.settlement, m.settleErr
+}
+
+func (m *mockCryptoProvider) FindSettlement(ctx context.Context, sessionID string, paymentPayload string) (*CryptoSettlement, error) {
+	m.lookups++
+	return m.found, m.findErr
+}
+
+func newCryptoPayment(repo *mockPaymentRepository) *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 1850, "USD", "crypto")
+	payment.ID = "payment-1"
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.ProviderSessionID = "encoded-requirements"
+	repo.payments[payment.ID] = payment
+	return payment
+}
+
+func TestPaymentService_CreatePaymentSessionWithCrypto(t *testing.T) {
+	trip := &types.Trip{
+		UserID:   "user-1",
+		Status:   "accepted",
+		RideFare: &types.RideFare{ID: primitive.NewObjectID(), TotalPriceInCents: 1850},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+	repo := newMockPaymentRepository()
+	card := &mockPaymentProvider{sessionID: "cs_test"}
+	crypto := &mockCryptoProvider{mockPaymentProvider: mockPaymentProvider{sessionID: "encoded-requirements"}}
+	svc := NewPaymentService(newTestRegistry(card, crypto), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{trip: trip}, repo, &mockTransactor{})
+
+	if err := svc.CreatePaymentSessionWithCrypto(context.Background(), "trip-1", "user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if card.calls != 0 || crypto.calls != 1 {
+		t.Errorf("expected only the crypto provider to be called, got card %d crypto %d", card.calls, crypto.calls)
+	}
+	payment := repo.payments["payment-trip-1"]
+	if payment.Provider != "crypto" || payment.ProviderSessionID != "encoded-requirements" {
+		t.Errorf("unexpected payment: %s %s", payment.Provider, payment.ProviderSessionID)
+	}
+}
+
+func TestPaymentService_PayWithCrypto(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCryptoPayment(repo)
+	crypto := &mockCryptoProvider{settlement: &CryptoSettlement{TransactionHash: "0xtxhash", Network: "base-sepolia"}}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if crypto.sessionID != "encoded-requirements" {
+		t.Errorf("expected session requirements to be settled, got %s", crypto.sessionID)
+	}
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusCaptured || payment.TransactionHash != "0xtxhash" {
+		t.Errorf("expected captured payment with tx hash, got %s %s", payment.Status, payment.TransactionHash)
+	}
+	succeeded, ok := publisher.published[0].(*PaymentSucceededEvent)
+	if !ok || succeeded.TransactionHash != "0xtxhash" {
+		t.Errorf("expected succeeded event with tx hash, got %+v", publisher.published[0])
+	}
+
+	// A redelivered command does not settle twice
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err != nil {
+		t.Fatalf("unexpected error on redelivery: %v", err)
+	}
+	if crypto.settled != 1 {
+		t.Errorf("expected a single settlement, got %d", crypto.settled)
+	}
+}
+
+func TestPaymentService_PayWithCrypto_WrongUser(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCryptoPayment(repo)
+	crypto := &mockCryptoProvider{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-2", "signed-payload"); !errors.Is(err, domain.ErrNotTripOwner) {
+		t.Fatalf("expected ErrNotTripOwner, got %v", err)
+	}
+	if crypto.settled != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_PayWithCrypto_SettlementDeclined(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCryptoPayment(repo)
+	crypto := &mockCryptoProvider{settleErr: &domain.ProviderError{Provider: "crypto", Op: "settle payment", Err: domain.ErrPaymentDeclined, Cause: errors.New("insufficient_funds")}}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err == nil {
+		t.Fatal("expected error")
+	}
+	if repo.payments["payment-1"].Status != domain.PaymentStatusSessionCreated {
+		t.Errorf("expected session to stay open for another attempt, got %s", repo.payments["payment-1"].Status)
+	}
+	if len(publisher.published) != 0 {
+		t.Error("expected no event to be published")
+	}
+}
+
+func TestPaymentService_PayWithCrypto_OutcomeUnknown(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCryptoPayment(repo)
+	crypto := &mockCryptoProvider{settleErr: errors.New("failed to send settle request: connection reset")}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err == nil {
+		t.Fatal("expected error")
+	}
+	if status := repo.payments["payment-1"].Status; status != domain.PaymentStatusSettling {
+		t.Fatalf("expected the payment to stay settling, got %s", status)
+	}
+
+	// The redelivered command looks the transaction up instead of settling the payload again
+	crypto.found = &CryptoSettlement{TransactionHash: "0xtxhash", Network: "base-sepolia"}
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err != nil {
+		t.Fatalf("unexpected error on redelivery: %v", err)
+	}
+	payment := repo.payments["payment-1"]
+	if crypto.settled != 1 || crypto.lookups != 1 {
+		t.Errorf("expected one settlement and one lookup, got %d and %d", crypto.settled, crypto.lookups)
+	}
+	if payment.Status != domain.PaymentStatusCaptured || payment.TransactionHash != "0xtxhash" || len(publisher.published) != 1 {
+		t.Errorf("expected captured payment with tx hash, got %s %s", payment.Status, payment.TransactionHash)
+	}
+}
+
+func TestPaymentService_PayWithCrypto_SettlementNotFoundYet(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCryptoPayment(repo)
+	if err := payment.StartSettlement(hashPayload("signed-payload")); err != nil {
+		t.Fatal(err)
+	}
+	crypto := &mockCryptoProvider{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err == nil {
+		t.Fatal("expected a transient error while the settlement is not found")
+	}
+	if repo.payments["payment-1"].Status != domain.PaymentStatusSettling || crypto.settled != 0 {
+		t.Errorf("expected the payment to stay settling without settling again, got %s", repo.payments["payment-1"].Status)
+	}
+
+	// Once the authorization expired unused, the rider may pay again
+	crypto.findErr = &domain.ProviderError{Provider: "crypto", Op: "find settlement", Err: domain.ErrPaymentDeclined, Cause: errors.New("authorization expired")}
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	if repo.payments["payment-1"].Status != domain.PaymentStatusSessionCreated {
+		t.Errorf("expected the session to reopen, got %s", repo.payments["payment-1"].Status)
+	}
+}
+
+func TestPaymentService_PayWithCrypto_SettlementLookupUnsupported(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCryptoPayment(repo)
+	if err := payment.StartSettlement(hashPayload("signed-payload")); err != nil {
+		t.Fatal(err)
+	}
+	crypto := &mockCryptoProvider{findErr: fmt.Errorf("x402 settlement lookup on solana: %w", errors.ErrUnsupported)}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	stored := repo.payments["payment-1"]
+	if stored.Status != domain.PaymentStatusSessionCreated || stored.SettlingPayload != "" {
+		t.Errorf("expected the session to reopen, got %s", stored.Status)
+	}
+	if crypto.settled != 0 {
+		t.Error("expected the payload not to be settled again")
+	}
+}
+
+func TestPaymentService_PayWithCrypto_OtherPayloadWhileSettling(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCryptoPayment(repo)
+	if err := payment.StartSettlement(hashPayload("signed-payload")); err != nil {
+		t.Fatal(err)
+	}
+	crypto := &mockCryptoProvider{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, crypto), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "other-payload"); !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if crypto.settled != 0 || crypto.lookups != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_PayWithCrypto_NotConfigured(t *testing.T) {
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, newMockPaymentRepository(), &mockTransactor{})
+
+	if err := svc.PayWithCrypto(context.Background(), "trip-1", "user-1", "signed-payload"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,21 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// CurrencyConfig decides which currency a trip fare is charged in
+type CurrencyConfig struct {
+	Default string // ISO 4217 code of fares whose trip record has no currency
+}
+
+// ForTrip returns the currency the trip's fare is charged in: the one the trip service priced it in.
+// The rider's request never decides it, or a fare could be charged as the same number of cheaper units.
+func (c CurrencyConfig) ForTrip(trip *domain.Trip) string {
+	if trip.FareCurrency != "" {
+		return strings.ToUpper(trip.FareCurrency)
+	}
+	return strings.ToUpper(c.Default)
+}
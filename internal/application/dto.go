@@ -1,9 +1,121 @@
 package application
 
-import "github.com/ride4Low/contracts/events"
+import (
+	"errors"
+
+	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
 
 // PaymentSessionCreatedEvent represents the event data when a payment session is created
 type PaymentSessionCreatedEvent struct {
 	UserID string
 	events.PaymentEventSessionCreatedData
+	AmountMinor int64 // exact fare in the currency's minor units; the shared Amount is the same fare as a float in major units
+}
+
+// PaymentEventData is the payload shared by payment outcome events
+type PaymentEventData struct {
+	PaymentID string `json:"paymentID"`
+	TripID    string `json:"tripID"`
+	DriverID  string `json:"driverID"`
+	Amount    int64  `json:"amount"` // amount in the currency's minor units
+	Currency  string `json:"currency"`
+}
+
+// Money returns the event amount with its currency
+func (d PaymentEventData) Money() domain.Money {
+	return domain.Money{Amount: d.Amount, Currency: d.Currency}
+}
+
+// PaymentSucceededEvent represents the event data when a payment is captured
+type PaymentSucceededEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+	TransactionHash string `json:"transactionHash,omitempty"` // set for crypto payments
+}
+
+// PaymentFailedEvent represents the event data when a payment attempt fails
+type PaymentFailedEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+	Reason string `json:"reason"`
+}
+
+// PaymentExpiredEvent represents the event data when a payment session expires unpaid
+type PaymentExpiredEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+}
+
+// PaymentRefundedEvent represents the event data when a payment is fully or partially refunded
+type PaymentRefundedEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+	RefundedAmount int64 `json:"refundedAmount"` // total refunded so far, in minor units
+}
+
+// PaymentAuthorizedEvent represents the event data when a hold is placed for the estimated fare
+type PaymentAuthorizedEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+}
+
+// PaymentCanceledEvent represents the event data when an authorized hold or an unpaid checkout is released
+type PaymentCanceledEvent struct {
+	UserID string `json:"-"`
+	PaymentEventData
+}
+
+// ProviderEventType identifies a payment outcome reported by a payment provider
+type ProviderEventType string
+
+const (
+	ProviderEventPaymentAuthorized ProviderEventType = "payment_authorized"
+	ProviderEventPaymentSucceeded  ProviderEventType = "payment_succeeded"
+	ProviderEventPaymentFailed     ProviderEventType = "payment_failed"
+	// ProviderEventAttemptDeclined reports one declined attempt; the session stays open for the rider to retry
+	ProviderEventAttemptDeclined ProviderEventType = "attempt_declined"
+	ProviderEventSessionExpired  ProviderEventType = "session_expired"
+	ProviderEventPaymentRefunded ProviderEventType = "payment_refunded"
+)
+
+// ErrProviderEventIgnored is returned by provider event parsers for notifications the service does not act on
+var ErrProviderEventIgnored = errors.New("provider event ignored")
+
+// ProviderEvent is a provider-agnostic notification about a payment (e.g. from a Stripe webhook)
+type ProviderEvent struct {
+	Type              ProviderEventType
+	PaymentID         string
+	ProviderSessionID string
+	ProviderPaymentID string
+	AmountRefunded    int64
+	FailureReason     string
+}
+
+// PaymentSelection carries the rider's payment choice from the incoming command; empty fields fall back
+// to the provider registry defaults
+type PaymentSelection struct {
+	Method string // e.g. PaymentMethodCard
+	Region string
+}
+
+// CryptoSettlement is the on-chain result of a settled crypto payment
+type CryptoSettlement struct {
+	TransactionHash string
+	Network         string
+	Payer           string
+}
+
+// PayoutRunResult summarizes a single payout run
+type PayoutRunResult struct {
+	Paid           int // payouts transferred to drivers
+	Failed         int // payouts whose transfer failed; retried by the next run
+	SkippedDrivers int // drivers with earnings but no payout-enabled account
+}
+
+// PaymentList is a page of payments, newest first
+type PaymentList struct {
+	Payments      []*domain.Payment
+	NextPageToken string // passed back to fetch the next page; empty on the last page
 }
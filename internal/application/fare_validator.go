@@ -0,0 +1,119 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ride4Low/contracts/types"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+var (
+	// ErrTripNotPayable is returned when the trip is not in a state in which its fare may be charged
+	ErrTripNotPayable = errors.New("trip is not payable")
+	// ErrFareExpired is returned when the fare was quoted longer ago than the fare policy allows
+	ErrFareExpired = errors.New("fare has expired")
+	// ErrFareOutOfRange is returned when the fare is not positive or outside the bounds for its currency
+	ErrFareOutOfRange = errors.New("fare amount out of range")
+	// ErrDriverNotAssigned is returned when the trip has no driver to be paid
+	ErrDriverNotAssigned = errors.New("no driver assigned to trip")
+	// ErrTripAlreadyPaid is returned when the trip's fare was already collected
+	ErrTripAlreadyPaid = errors.New("trip is already paid")
+	// ErrOpenPaymentMismatch is returned when the trip's open payment was started with another provider,
+	// capture method or fare; it must expire or be released before a payment on the new terms starts
+	ErrOpenPaymentMismatch = errors.New("trip has an open payment on different terms")
+)
+
+// FareValidationError describes why a trip fare was refused before charging the rider
+type FareValidationError struct {
+	TripID string
+	Err    error // one of the fare validation sentinel errors
+	Detail string
+}
+
+func (e *FareValidationError) Error() string {
+	return fmt.Sprintf("trip %s: %v: %s", e.TripID, e.Err, e.Detail)
+}
+
+func (e *FareValidationError) Unwrap() error {
+	return e.Err
+}
+
+// FareLimits bounds a fare in minor units of its currency
+type FareLimits struct {
+	Min int64
+	Max int64 // zero means no upper bound
+}
+
+// FarePolicy decides which trip fares may be charged
+type FarePolicy struct {
+	PayableStatuses []string              // trip statuses in which the fare may be charged; empty allows any status
+	FareTTL         time.Duration         // how long a quoted fare stays chargeable; zero disables the check
+	Limits          map[string]FareLimits // ISO 4217 code -> bounds; other currencies only need a positive fare
+}
+
+// FareValidator checks a trip's fare against the fare policy before a payment is created for it
+type FareValidator struct {
+	policy FarePolicy
+	now    func() time.Time
+}
+
+// NewFareValidator creates a fare validator enforcing policy
+func NewFareValidator(policy FarePolicy) *FareValidator {
+	return &FareValidator{policy: policy, now: time.Now}
+}
+
+// Validate returns the trip fare in currency, or a FareValidationError when it must not be charged
+func (v *FareValidator) Validate(trip *types.Trip, currency string) (domain.Money, error) {
+	tripID := trip.ID.Hex()
+
+	if !v.payableStatus(trip.Status) {
+		return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrTripNotPayable, Detail: fmt.Sprintf("status %q", trip.Status)}
+	}
+	if trip.Driver == nil || trip.Driver.Id == "" {
+		return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrDriverNotAssigned, Detail: "driver missing"}
+	}
+	if trip.RideFare == nil {
+		return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrTripNotPayable, Detail: "fare missing"}
+	}
+
+	if v.policy.FareTTL > 0 {
+		// Fares are stored when quoted, so the fare's ObjectID records the quote time
+		if trip.RideFare.ID.IsZero() {
+			return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrFareExpired, Detail: "fare has no quote time"}
+		}
+		if quotedAt := trip.RideFare.ID.Timestamp(); v.now().Sub(quotedAt) > v.policy.FareTTL {
+			return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrFareExpired, Detail: "quoted at " + quotedAt.UTC().Format(time.RFC3339)}
+		}
+	}
+
+	// The trip service prices fares in minor units of the fare's currency
+	price := trip.RideFare.TotalPriceInCents
+	if math.IsNaN(price) || math.IsInf(price, 0) || price > math.MaxInt64 {
+		return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrFareOutOfRange, Detail: fmt.Sprintf("price %v", price)}
+	}
+	fare, err := domain.NewMoney(int64(math.Round(price)), currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	limits := v.policy.Limits[fare.Currency]
+	if fare.Amount <= 0 || fare.Amount < limits.Min || (limits.Max > 0 && fare.Amount > limits.Max) {
+		return domain.Money{}, &FareValidationError{TripID: tripID, Err: ErrFareOutOfRange, Detail: fare.String()}
+	}
+	return fare, nil
+}
+
+// payableStatus reports whether the policy allows charging trips in status
+func (v *FareValidator) payableStatus(status string) bool {
+	if len(v.policy.PayableStatuses) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(v.policy.PayableStatuses, func(payable string) bool {
+		return strings.EqualFold(payable, status)
+	})
+}
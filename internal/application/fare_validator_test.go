@@ -0,0 +1,96 @@
+package application
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/contracts/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newValidatedTrip(quotedAt time.Time, price float64) *types.Trip {
+	return &types.Trip{
+		ID:       primitive.NewObjectID(),
+		UserID:   "user-1",
+		Status:   "accepted",
+		RideFare: &types.RideFare{ID: primitive.NewObjectIDFromTimestamp(quotedAt), TotalPriceInCents: price},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+}
+
+func TestFareValidator_Validate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	validator := NewFareValidator(FarePolicy{
+		PayableStatuses: []string{"accepted"},
+		FareTTL:         time.Hour,
+		Limits:          map[string]FareLimits{"USD": {Min: 50, Max: 100000}, "JPY": {Min: 100}},
+	})
+	validator.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		trip     func(trip *types.Trip)
+		currency string
+		wantErr  error
+	}{
+		{name: "valid fare", currency: "usd"},
+		{name: "status case insensitive", trip: func(trip *types.Trip) { trip.Status = "ACCEPTED" }, currency: "USD"},
+		{name: "no upper bound", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = 5_000_000 }, currency: "JPY"},
+		{name: "unlisted currency", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = 1 }, currency: "EUR"},
+		{name: "trip not accepted", trip: func(trip *types.Trip) { trip.Status = "pending" }, currency: "USD", wantErr: ErrTripNotPayable},
+		{name: "fare missing", trip: func(trip *types.Trip) { trip.RideFare = nil }, currency: "USD", wantErr: ErrTripNotPayable},
+		{name: "no driver", trip: func(trip *types.Trip) { trip.Driver = nil }, currency: "USD", wantErr: ErrDriverNotAssigned},
+		{name: "empty driver", trip: func(trip *types.Trip) { trip.Driver.Id = "" }, currency: "USD", wantErr: ErrDriverNotAssigned},
+		{name: "expired fare", trip: func(trip *types.Trip) { trip.RideFare.ID = primitive.NewObjectIDFromTimestamp(now.Add(-2 * time.Hour)) }, currency: "USD", wantErr: ErrFareExpired},
+		{name: "fare without quote time", trip: func(trip *types.Trip) { trip.RideFare.ID = primitive.NilObjectID }, currency: "USD", wantErr: ErrFareExpired},
+		{name: "zero fare", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = 0 }, currency: "EUR", wantErr: ErrFareOutOfRange},
+		{name: "negative fare", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = -1050 }, currency: "USD", wantErr: ErrFareOutOfRange},
+		{name: "not a number", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = math.NaN() }, currency: "USD", wantErr: ErrFareOutOfRange},
+		{name: "below minimum", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = 49 }, currency: "USD", wantErr: ErrFareOutOfRange},
+		{name: "above maximum", trip: func(trip *types.Trip) { trip.RideFare.TotalPriceInCents = 100001 }, currency: "USD", wantErr: ErrFareOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := newValidatedTrip(now.Add(-30*time.Minute), 1850)
+			if tt.trip != nil {
+				tt.trip(trip)
+			}
+
+			fare, err := validator.Validate(trip, tt.currency)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if fare.Amount != int64(trip.RideFare.TotalPriceInCents) {
+					t.Errorf("expected fare %v, got %d", trip.RideFare.TotalPriceInCents, fare.Amount)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var validationErr *FareValidationError
+			if !errors.As(err, &validationErr) || validationErr.TripID != trip.ID.Hex() {
+				t.Errorf("expected FareValidationError for trip %s, got %v", trip.ID.Hex(), err)
+			}
+		})
+	}
+}
+
+func TestFareValidator_Validate_NoPolicy(t *testing.T) {
+	trip := newValidatedTrip(time.Now(), 1850)
+	trip.Status = "anything"
+	trip.RideFare.ID = primitive.NilObjectID
+
+	fare, err := NewFareValidator(FarePolicy{}).Validate(trip, "USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fare.Amount != 1850 || fare.Currency != "USD" {
+		t.Errorf("expected 1850 USD, got %s", fare)
+	}
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+const (
+	defaultPaymentPageSize = 20
+	maxPaymentPageSize     = 100
+)
+
+// paymentQueryService implements PaymentQueryService interface
+type paymentQueryService struct {
+	paymentRepo PaymentRepository
+}
+
+// NewPaymentQueryService creates a new payment query service reading from the payment repository
+func NewPaymentQueryService(paymentRepo PaymentRepository) PaymentQueryService {
+	return &paymentQueryService{paymentRepo: paymentRepo}
+}
+
+// GetPayment returns the payment with the given ID
+func (s *paymentQueryService) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	return s.paymentRepo.GetPaymentByID(ctx, paymentID)
+}
+
+// GetPaymentByTrip returns the trip's latest payment
+func (s *paymentQueryService) GetPaymentByTrip(ctx context.Context, tripID string) (*domain.Payment, error) {
+	return s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+}
+
+// ListPaymentsByUser returns a page of the rider's payments, newest first
+func (s *paymentQueryService) ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*PaymentList, error) {
+	return s.list(ctx, s.paymentRepo.ListPaymentsByUser, userID, pageSize, pageToken)
+}
+
+// ListPaymentsByDriver returns a page of the payments for the driver's trips, newest first
+func (s *paymentQueryService) ListPaymentsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*PaymentList, error) {
+	return s.list(ctx, s.paymentRepo.ListPaymentsByDriver, driverID, pageSize, pageToken)
+}
+
+// list fetches one payment more than the page holds to learn whether another page follows.
+// The page token is the ID of the last payment returned.
+func (s *paymentQueryService) list(ctx context.Context, find func(context.Context, string, domain.PaymentPage) ([]*domain.Payment, error), ownerID string, pageSize int, pageToken string) (*PaymentList, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPaymentPageSize
+	}
+	pageSize = min(pageSize, maxPaymentPageSize)
+
+	payments, err := find(ctx, ownerID, domain.PaymentPage{Limit: pageSize + 1, AfterID: pageToken})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &PaymentList{Payments: payments}
+	if len(payments) > pageSize {
+		list.Payments = payments[:pageSize]
+		list.NextPageToken = list.Payments[pageSize-1].ID
+	}
+	return list, nil
+}
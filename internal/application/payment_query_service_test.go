@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+func newQueryTestRepository(count int) *mockPaymentRepository {
+	repo := newMockPaymentRepository()
+	for i := 1; i <= count; i++ {
+		payment := domain.NewPayment(fmt.Sprintf("trip-%02d", i), "user-1", "driver-1", 1850, "USD", "mock")
+		payment.ID = fmt.Sprintf("payment-%02d", i)
+		repo.payments[payment.ID] = payment
+	}
+	other := domain.NewPayment("trip-other", "user-2", "driver-2", 900, "USD", "mock")
+	other.ID = "payment-99"
+	repo.payments[other.ID] = other
+	return repo
+}
+
+func TestPaymentQueryService_ListPaymentsByUser_Pages(t *testing.T) {
+	svc := NewPaymentQueryService(newQueryTestRepository(5))
+
+	first, err := svc.ListPaymentsByUser(context.Background(), "user-1", 2, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Payments) != 2 || first.Payments[0].ID != "payment-05" || first.Payments[1].ID != "payment-04" {
+		t.Fatalf("expected newest two payments, got %v", paymentIDs(first.Payments))
+	}
+	if first.NextPageToken != "payment-04" {
+		t.Errorf("expected next page token payment-04, got %q", first.NextPageToken)
+	}
+
+	second, _ := svc.ListPaymentsByUser(context.Background(), "user-1", 2, first.NextPageToken)
+	last, _ := svc.ListPaymentsByUser(context.Background(), "user-1", 2, second.NextPageToken)
+	if got := paymentIDs(append(second.Payments, last.Payments...)); fmt.Sprint(got) != "[payment-03 payment-02 payment-01]" {
+		t.Errorf("unexpected remaining pages %v", got)
+	}
+	if last.NextPageToken != "" {
+		t.Errorf("expected no token on the last page, got %q", last.NextPageToken)
+	}
+}
+
+func TestPaymentQueryService_ListPaymentsByDriver_PageSize(t *testing.T) {
+	svc := NewPaymentQueryService(newQueryTestRepository(maxPaymentPageSize + 5))
+
+	tests := []struct {
+		name     string
+		pageSize int
+		want     int
+	}{
+		{name: "default", pageSize: 0, want: defaultPaymentPageSize},
+		{name: "capped", pageSize: 1000, want: maxPaymentPageSize},
+		{name: "requested", pageSize: 7, want: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := svc.ListPaymentsByDriver(context.Background(), "driver-1", tt.pageSize, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(list.Payments) != tt.want || list.NextPageToken == "" {
+				t.Errorf("expected %d payments and a next page, got %d and %q", tt.want, len(list.Payments), list.NextPageToken)
+			}
+		})
+	}
+}
+
+func TestPaymentQueryService_GetPayment_NotFound(t *testing.T) {
+	svc := NewPaymentQueryService(newQueryTestRepository(1))
+
+	if _, err := svc.GetPayment(context.Background(), "missing"); !errors.Is(err, domain.ErrPaymentNotFound) {
+		t.Errorf("expected ErrPaymentNotFound, got %v", err)
+	}
+	payment, err := svc.GetPaymentByTrip(context.Background(), "trip-01")
+	if err != nil || payment.ID != "payment-01" {
+		t.Errorf("expected payment-01 for trip-01, got %v %v", payment, err)
+	}
+}
+
+func paymentIDs(payments []*domain.Payment) []string {
+	ids := make([]string, 0, len(payments))
+	for _, payment := range payments {
+		ids = append(ids, payment.ID)
+	}
+	return ids
+}
@@ -2,69 +2,222 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/domain"
 )
 
 // paymentService implements PaymentService interface
 type paymentService struct {
-	provider   PaymentProvider
-	publisher  EventPublisher
-	repository TripRepository
+	providers   *ProviderRegistry
+	currencies  CurrencyConfig
+	fares       *FareValidator
+	publisher   EventPublisher
+	repository  TripRepository
+	paymentRepo PaymentRepository
+	transactor  Transactor
 }
 
-// NewPaymentService creates a new payment service with the given provider, publisher, and repository
-func NewPaymentService(provider PaymentProvider, publisher EventPublisher, repository TripRepository) PaymentService {
+// NewPaymentService creates a new payment service with the given provider registry, currency configuration, fare validator, publisher, repositories, and transactor
+func NewPaymentService(providers *ProviderRegistry, currencies CurrencyConfig, fares *FareValidator, publisher EventPublisher, repository TripRepository, paymentRepo PaymentRepository, transactor Transactor) PaymentService {
 	return &paymentService{
-		provider:   provider,
-		publisher:  publisher,
-		repository: repository,
+		providers:   providers,
+		currencies:  currencies,
+		fares:       fares,
+		publisher:   publisher,
+		repository:  repository,
+		paymentRepo: paymentRepo,
+		transactor:  transactor,
 	}
 }
 
-// CreatePaymentSession creates a payment session using the payment provider
+// CreatePaymentSession records a pending payment and creates a payment session using the payment provider.
+// It is idempotent per trip: while a payment for the trip is still open on the same terms, the existing
+// session is announced again instead of creating a second one.
 func (s *paymentService) CreatePaymentSession(ctx context.Context, tripID, userID, driverID string, amount int64, currency string) error {
-	metadata := map[string]string{
-		"trip_id":   tripID,
-		"user_id":   userID,
-		"driver_id": driverID,
+	fare, err := domain.NewMoney(amount, currency)
+	if err != nil {
+		return err
+	}
+	provider, err := s.providers.Resolve(PaymentSelection{}, fare.Currency)
+	if err != nil {
+		return err
 	}
+	return s.createSession(ctx, provider, tripID, userID, driverID, fare, domain.CaptureMethodAutomatic)
+}
+
+func (s *paymentService) CreatePaymentSessionWithCard(ctx context.Context, tripID, userID string) error {
+	return s.createSessionForTrip(ctx, PaymentSelection{Method: PaymentMethodCard}, tripID, userID, domain.CaptureMethodAutomatic)
+}
+
+// CreatePaymentSessionForTrip starts a payment for the trip fare with the provider serving the rider's selection
+func (s *paymentService) CreatePaymentSessionForTrip(ctx context.Context, tripID, userID string, selection PaymentSelection) error {
+	return s.createSessionForTrip(ctx, selection, tripID, userID, domain.CaptureMethodAutomatic)
+}
 
-	sessionID, err := s.provider.CreatePaymentSession(ctx, amount, currency, metadata)
+// createSession holds the session creation flow shared by immediate charges, authorizations and crypto payments
+func (s *paymentService) createSession(ctx context.Context, provider PaymentProvider, tripID, userID, driverID string, fare domain.Money, captureMethod domain.CaptureMethod) error {
+	payment, err := s.openPaymentForTrip(ctx, tripID)
 	if err != nil {
 		return err
 	}
 
-	msg := &PaymentSessionCreatedEvent{
-		UserID: userID,
-		PaymentEventSessionCreatedData: events.PaymentEventSessionCreatedData{
-			TripID:    tripID,
-			SessionID: sessionID,
-			Amount:    float64(amount) / 100.0,
-			Currency:  currency,
-		},
+	if payment == nil {
+		payment = domain.NewPayment(tripID, userID, driverID, fare.Amount, fare.Currency, provider.Name())
+		payment.CaptureMethod = captureMethod
+		if err := s.paymentRepo.CreatePayment(ctx, payment); err != nil {
+			if !errors.Is(err, domain.ErrPaymentAlreadyExists) {
+				return err
+			}
+			// A concurrent delivery for the same trip won the race, reuse its payment
+			if payment, err = s.openPaymentForTrip(ctx, tripID); err != nil || payment == nil {
+				return fmt.Errorf("failed to load concurrent payment for trip %s: %w", tripID, err)
+			}
+		}
 	}
 
-	// Publish the event from application layer (business logic decides when to publish)
-	if err := s.publisher.PublishPaymentSessionCreated(ctx, msg); err != nil {
+	// Only a redelivery of the same request may reuse the open payment; anything else would charge the
+	// rider through a session they did not ask for, or at a stale fare
+	if err := checkSameTerms(payment, provider.Name(), fare, captureMethod); err != nil {
 		return err
 	}
 
-	return nil
+	if payment.Status != domain.PaymentStatusPending {
+		log.Printf("payment %s for trip %s already has session %s, republishing", payment.ID, tripID, payment.ProviderSessionID)
+		return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentSessionCreated(ctx, sessionCreatedEvent(payment))
+		})
+	}
+
+	metadata := map[string]string{
+		"payment_id": payment.ID,
+		"trip_id":    payment.TripID,
+		"user_id":    payment.UserID,
+		"driver_id":  payment.DriverID,
+	}
+
+	// The payment ID is stable across redeliveries, so the provider de-duplicates retried requests
+	idempotencyKey := "payment-session-" + payment.ID
+	var sessionID string
+	if payment.CaptureMethod == domain.CaptureMethodManual {
+		sessionID, err = provider.CreateAuthorizationSession(ctx, payment.Amount, payment.Currency, metadata, idempotencyKey)
+	} else {
+		sessionID, err = provider.CreatePaymentSession(ctx, payment.Amount, payment.Currency, metadata, idempotencyKey)
+	}
+	if err != nil {
+		if transitionErr := payment.MarkFailed(err.Error()); transitionErr == nil {
+			if updateErr := s.paymentRepo.UpdatePayment(ctx, payment); updateErr != nil {
+				log.Printf("failed to mark payment %s as failed: %v", payment.ID, updateErr)
+			}
+		}
+		return err
+	}
+
+	if err := payment.AttachSession(sessionID); err != nil {
+		return err
+	}
+
+	// Publish the event from application layer (business logic decides when to publish)
+	return s.savePayment(ctx, payment, func(ctx context.Context) error {
+		return s.publisher.PublishPaymentSessionCreated(ctx, sessionCreatedEvent(payment))
+	})
 }
 
-func (s *paymentService) CreatePaymentSessionWithCard(ctx context.Context, tripID, userID string) error {
+// createSessionForTrip loads the trip fare and starts a payment for its rider with the selected provider
+func (s *paymentService) createSessionForTrip(ctx context.Context, selection PaymentSelection, tripID, userID string, captureMethod domain.CaptureMethod) error {
 	trip, err := s.repository.GetTripByID(ctx, tripID)
 	if err != nil {
 		return err
 	}
 
 	if trip.UserID != userID {
-		log.Println("invalid userID")
-		return fmt.Errorf("invalid userID")
+		return &domain.TripError{TripID: tripID, Err: domain.ErrNotTripOwner, Cause: fmt.Errorf("user %s", userID)}
 	}
 
-	return s.CreatePaymentSession(ctx, tripID, userID, trip.Driver.Id, int64(trip.RideFare.TotalPriceInCents), "USD")
+	fare, err := s.fares.Validate(trip.Trip, s.currencies.ForTrip(trip))
+	if err != nil {
+		return err
+	}
+	provider, err := s.providers.Resolve(selection, fare.Currency)
+	if err != nil {
+		return err
+	}
+
+	return s.createSession(ctx, provider, tripID, userID, trip.Driver.Id, fare, captureMethod)
+}
+
+// providerFor returns the provider that handles an existing payment
+func (s *paymentService) providerFor(payment *domain.Payment) (PaymentProvider, error) {
+	return s.providers.Get(payment.Provider)
+}
+
+// savePayment persists the payment and publishes its event in a single unit of work,
+// so an event is recorded if and only if the state change it describes is
+func (s *paymentService) savePayment(ctx context.Context, payment *domain.Payment, publish func(ctx context.Context) error) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+		return publish(ctx)
+	})
+}
+
+// openPaymentForTrip returns the trip's latest payment if it is still open, or nil when a new payment is needed.
+// Trips whose fare was already collected are refused so the rider is never charged twice.
+func (s *paymentService) openPaymentForTrip(ctx context.Context, tripID string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetPaymentByTripID(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if payment.IsPaid() {
+		return nil, &FareValidationError{TripID: tripID, Err: ErrTripAlreadyPaid, Detail: fmt.Sprintf("payment %s is %s", payment.ID, payment.Status)}
+	}
+
+	if !payment.IsOpen() {
+		return nil, nil
+	}
+	return payment, nil
+}
+
+// checkSameTerms reports an ErrOpenPaymentMismatch when the open payment differs from the requested one
+func checkSameTerms(payment *domain.Payment, provider string, fare domain.Money, captureMethod domain.CaptureMethod) error {
+	var differences []string
+	if payment.Provider != provider {
+		differences = append(differences, fmt.Sprintf("provider %s, requested %s", payment.Provider, provider))
+	}
+	if payment.CaptureMethod != captureMethod {
+		differences = append(differences, fmt.Sprintf("capture method %s, requested %s", payment.CaptureMethod, captureMethod))
+	}
+	if payment.Amount != fare.Amount || !strings.EqualFold(payment.Currency, fare.Currency) {
+		differences = append(differences, fmt.Sprintf("fare %d %s, requested %d %s", payment.Amount, payment.Currency, fare.Amount, fare.Currency))
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	return &FareValidationError{
+		TripID: payment.TripID,
+		Err:    ErrOpenPaymentMismatch,
+		Detail: fmt.Sprintf("payment %s has %s", payment.ID, strings.Join(differences, ", ")),
+	}
+}
+
+func sessionCreatedEvent(payment *domain.Payment) *PaymentSessionCreatedEvent {
+	return &PaymentSessionCreatedEvent{
+		UserID: payment.UserID,
+		PaymentEventSessionCreatedData: events.PaymentEventSessionCreatedData{
+			TripID:    payment.TripID,
+			SessionID: payment.ProviderSessionID,
+			Amount:    payment.Money().Major(),
+			Currency:  payment.Currency,
+		},
+		AmountMinor: payment.Amount,
+	}
 }
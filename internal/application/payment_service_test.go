@@ -3,29 +3,123 @@ package application
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ride4Low/contracts/types"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // mockPaymentProvider is a mock implementation of PaymentProvider for testing
 type mockPaymentProvider struct {
-	sessionID string
-	err       error
+	name           string
+	sessionID      string
+	err            error
+	calls          int
+	idempotencyKey string
+	refundID       string
+	refundAmount   int64
+	authorizations int
+	captureAmount  int64
+	canceled       bool
+	expired        bool
+	onRefund       func() // runs while the refund is issued, e.g. to update the payment concurrently
+	onExpire       func() // runs while the session is expired, e.g. to complete it concurrently
+}
+
+func (m *mockPaymentProvider) Name() string {
+	if m.name != "" {
+		return m.name
+	}
+	return "mock"
 }
 
-func (m *mockPaymentProvider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (string, error) {
+func (m *mockPaymentProvider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	m.calls++
+	m.idempotencyKey = idempotencyKey
 	if m.err != nil {
 		return "", m.err
 	}
 	return m.sessionID, nil
 }
 
+func (m *mockPaymentProvider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	m.calls++
+	m.idempotencyKey = idempotencyKey
+	m.refundAmount = amount
+	if m.onRefund != nil {
+		m.onRefund()
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.refundID, nil
+}
+
+func (m *mockPaymentProvider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	m.authorizations++
+	m.idempotencyKey = idempotencyKey
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.sessionID, nil
+}
+
+func (m *mockPaymentProvider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	m.calls++
+	m.captureAmount = amount
+	m.idempotencyKey = idempotencyKey
+	return m.err
+}
+
+func (m *mockPaymentProvider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	m.calls++
+	m.canceled = true
+	return m.err
+}
+
+func (m *mockPaymentProvider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	m.calls++
+	m.expired = true
+	if m.onExpire != nil {
+		m.onExpire()
+	}
+	return m.err
+}
+
+// testCurrencies charges every fare in US dollars
+var testCurrencies = CurrencyConfig{Default: "USD"}
+
+// testFares accepts fares of accepted trips quoted within the last hour
+var testFares = NewFareValidator(FarePolicy{PayableStatuses: []string{"accepted"}, FareTTL: time.Hour})
+
+// newTestRegistry registers the providers with the first one as default and card provider.
+// Each provider also serves the payment method matching its name, e.g. "crypto" or "cash".
+func newTestRegistry(providers ...PaymentProvider) *ProviderRegistry {
+	config := ProviderRegistryConfig{
+		Default: providers[0].Name(),
+		Methods: map[string]string{PaymentMethodCard: providers[0].Name()},
+	}
+	for _, provider := range providers[1:] {
+		config.Methods[provider.Name()] = provider.Name()
+	}
+
+	registry, err := NewProviderRegistry(config, providers...)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
 // mockEventPublisher is a mock implementation of EventPublisher for testing
 type mockEventPublisher struct {
-	called bool
-	event  PaymentSessionCreatedEvent
-	err    error
+	called    bool
+	event     PaymentSessionCreatedEvent
+	published []any
+	err       error
 }
 
 func (m *mockEventPublisher) PublishPaymentSessionCreated(ctx context.Context, event *PaymentSessionCreatedEvent) error {
@@ -34,22 +128,197 @@ func (m *mockEventPublisher) PublishPaymentSessionCreated(ctx context.Context, e
 	return m.err
 }
 
+func (m *mockEventPublisher) PublishPaymentSucceeded(ctx context.Context, event *PaymentSucceededEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
+func (m *mockEventPublisher) PublishPaymentFailed(ctx context.Context, event *PaymentFailedEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
+func (m *mockEventPublisher) PublishPaymentExpired(ctx context.Context, event *PaymentExpiredEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
+func (m *mockEventPublisher) PublishPaymentRefunded(ctx context.Context, event *PaymentRefundedEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
+func (m *mockEventPublisher) PublishPaymentAuthorized(ctx context.Context, event *PaymentAuthorizedEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
+func (m *mockEventPublisher) PublishPaymentCanceled(ctx context.Context, event *PaymentCanceledEvent) error {
+	m.published = append(m.published, event)
+	return m.err
+}
+
 type mockTripRepository struct {
 	getByIDCalled bool
 	getByIDErr    error
 	trip          *types.Trip
+	fareCurrency  string
 }
 
-func (m *mockTripRepository) GetTripByID(ctx context.Context, id string) (*types.Trip, error) {
+func (m *mockTripRepository) GetTripByID(ctx context.Context, id string) (*domain.Trip, error) {
 	m.getByIDCalled = true
-	return m.trip, m.getByIDErr
+	if m.trip == nil {
+		return nil, m.getByIDErr
+	}
+	return &domain.Trip{Trip: m.trip, FareCurrency: m.fareCurrency}, m.getByIDErr
+}
+
+type mockPaymentRepository struct {
+	createErr error
+	updateErr error
+	payments  map[string]*domain.Payment
+}
+
+func newMockPaymentRepository() *mockPaymentRepository {
+	return &mockPaymentRepository{payments: make(map[string]*domain.Payment)}
+}
+
+func (m *mockPaymentRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	for _, existing := range m.payments {
+		if existing.TripID == payment.TripID && existing.IsOpen() {
+			return domain.ErrPaymentAlreadyExists
+		}
+	}
+	if payment.ID == "" {
+		payment.ID = "payment-" + payment.TripID
+	}
+	stored := *payment
+	m.payments[payment.ID] = &stored
+	return nil
+}
+
+func (m *mockPaymentRepository) UpdatePayment(ctx context.Context, payment *domain.Payment) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	existing, ok := m.payments[payment.ID]
+	if !ok {
+		return domain.ErrPaymentNotFound
+	}
+	if existing.Version != payment.Version {
+		return domain.ErrPaymentConflict
+	}
+	payment.Version++
+	stored := *payment
+	m.payments[payment.ID] = &stored
+	return nil
+}
+
+func (m *mockPaymentRepository) GetPaymentByID(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	payment, ok := m.payments[paymentID]
+	if !ok {
+		return nil, domain.ErrPaymentNotFound
+	}
+	found := *payment
+	return &found, nil
+}
+
+func (m *mockPaymentRepository) GetPaymentByTripID(ctx context.Context, tripID string) (*domain.Payment, error) {
+	var latest *domain.Payment
+	for _, payment := range m.payments {
+		if payment.TripID == tripID && (latest == nil || payment.CreatedAt.After(latest.CreatedAt)) {
+			latest = payment
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrPaymentNotFound
+	}
+	found := *latest
+	return &found, nil
+}
+
+func (m *mockPaymentRepository) GetPaymentByProviderPaymentID(ctx context.Context, providerPaymentID string) (*domain.Payment, error) {
+	for _, payment := range m.payments {
+		if payment.ProviderPaymentID == providerPaymentID {
+			found := *payment
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrPaymentNotFound
+}
+
+func (m *mockPaymentRepository) ListPayablePayments(ctx context.Context, createdBefore time.Time) ([]*domain.Payment, error) {
+	var payable []*domain.Payment
+	for _, payment := range m.payments {
+		if payment.IsPayable() && payment.CreatedAt.Before(createdBefore) {
+			found := *payment
+			payable = append(payable, &found)
+		}
+	}
+	slices.SortFunc(payable, func(a, b *domain.Payment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return payable, nil
+}
+
+func (m *mockPaymentRepository) ListCommissionDuePayments(ctx context.Context, createdBefore time.Time) ([]*domain.Payment, error) {
+	var due []*domain.Payment
+	for _, payment := range m.payments {
+		if payment.IsCommissionDue() && payment.CreatedAt.Before(createdBefore) {
+			found := *payment
+			due = append(due, &found)
+		}
+	}
+	slices.SortFunc(due, func(a, b *domain.Payment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return due, nil
+}
+
+func (m *mockPaymentRepository) ClaimPayment(ctx context.Context, paymentID, payoutID string) (bool, error) {
+	payment, ok := m.payments[paymentID]
+	if !ok || !(payment.IsPayable() || payment.IsCommissionDue()) {
+		return false, nil
+	}
+	payment.PayoutID = payoutID
+	return true, nil
+}
+
+func (m *mockPaymentRepository) ListPaymentsByUser(ctx context.Context, userID string, page domain.PaymentPage) ([]*domain.Payment, error) {
+	return m.listPage(func(p *domain.Payment) bool { return p.UserID == userID }, page), nil
+}
+
+func (m *mockPaymentRepository) ListPaymentsByDriver(ctx context.Context, driverID string, page domain.PaymentPage) ([]*domain.Payment, error) {
+	return m.listPage(func(p *domain.Payment) bool { return p.DriverID == driverID }, page), nil
+}
+
+// listPage orders payments by descending ID, as ObjectIDs order them in MongoDB
+func (m *mockPaymentRepository) listPage(match func(*domain.Payment) bool, page domain.PaymentPage) []*domain.Payment {
+	var found []*domain.Payment
+	for _, payment := range m.payments {
+		if match(payment) && (page.AfterID == "" || payment.ID < page.AfterID) {
+			copied := *payment
+			found = append(found, &copied)
+		}
+	}
+	slices.SortFunc(found, func(a, b *domain.Payment) int { return strings.Compare(b.ID, a.ID) })
+	return found[:min(len(found), page.Limit)]
+}
+
+// mockTransactor runs the unit of work inline without a real transaction
+type mockTransactor struct {
+	calls int
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
 }
 
 func TestNewPaymentService(t *testing.T) {
 	provider := &mockPaymentProvider{}
 	publisher := &mockEventPublisher{}
 	tripRepository := &mockTripRepository{}
-	svc := NewPaymentService(provider, publisher, tripRepository)
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, newMockPaymentRepository(), &mockTransactor{})
 
 	if svc == nil {
 		t.Fatal("expected non-nil PaymentService")
@@ -60,7 +329,7 @@ func TestPaymentService_CreatePaymentSession_Success(t *testing.T) {
 	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
 	publisher := &mockEventPublisher{}
 	tripRepository := &mockTripRepository{}
-	svc := NewPaymentService(provider, publisher, tripRepository)
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, newMockPaymentRepository(), &mockTransactor{})
 
 	ctx := context.Background()
 	err := svc.CreatePaymentSession(ctx, "trip-1", "user-1", "driver-1", 1000, "usd")
@@ -80,7 +349,7 @@ func TestPaymentService_CreatePaymentSession_ProviderError(t *testing.T) {
 	provider := &mockPaymentProvider{err: providerErr}
 	publisher := &mockEventPublisher{}
 	tripRepository := &mockTripRepository{}
-	svc := NewPaymentService(provider, publisher, tripRepository)
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, newMockPaymentRepository(), &mockTransactor{})
 
 	ctx := context.Background()
 	err := svc.CreatePaymentSession(ctx, "trip-1", "user-1", "driver-1", 1000, "usd")
@@ -100,7 +369,7 @@ func TestPaymentService_CreatePaymentSession_PublisherError(t *testing.T) {
 	publisher := &mockEventPublisher{err: publisherErr}
 	tripRepository := &mockTripRepository{}
 
-	svc := NewPaymentService(provider, publisher, tripRepository)
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, newMockPaymentRepository(), &mockTransactor{})
 
 	ctx := context.Background()
 	err := svc.CreatePaymentSession(ctx, "trip-1", "user-1", "driver-1", 1000, "usd")
@@ -116,7 +385,7 @@ func TestPaymentService_CreatePaymentSession_EventData(t *testing.T) {
 	publisher := &mockEventPublisher{}
 	tripRepository := &mockTripRepository{}
 
-	svc := NewPaymentService(provider, publisher, tripRepository)
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, newMockPaymentRepository(), &mockTransactor{})
 
 	ctx := context.Background()
 	err := svc.CreatePaymentSession(ctx, "trip-123", "user-456", "driver-789", 2500, "eur")
@@ -144,7 +413,308 @@ func TestPaymentService_CreatePaymentSession_EventData(t *testing.T) {
 		t.Errorf("expected event Amount %f, got %f", expectedAmount, publisher.event.Amount)
 	}
 
-	if publisher.event.Currency != "eur" {
-		t.Errorf("expected event Currency 'eur', got '%s'", publisher.event.Currency)
+	if publisher.event.AmountMinor != 2500 {
+		t.Errorf("expected event AmountMinor 2500, got %d", publisher.event.AmountMinor)
+	}
+
+	if publisher.event.Currency != "EUR" {
+		t.Errorf("expected event Currency 'EUR', got '%s'", publisher.event.Currency)
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_PersistsPayment(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_789"}
+	publisher := &mockEventPublisher{}
+	tripRepository := &mockTripRepository{}
+	paymentRepository := newMockPaymentRepository()
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, paymentRepository, &mockTransactor{})
+
+	err := svc.CreatePaymentSession(context.Background(), "trip-123", "user-456", "driver-789", 2500, "eur")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment, err := paymentRepository.GetPaymentByTripID(context.Background(), "trip-123")
+	if err != nil {
+		t.Fatalf("expected payment to be stored, got error: %v", err)
+	}
+
+	if payment.ProviderSessionID != "cs_test_session_789" {
+		t.Errorf("expected ProviderSessionID 'cs_test_session_789', got '%s'", payment.ProviderSessionID)
+	}
+
+	if payment.Status != domain.PaymentStatusSessionCreated {
+		t.Errorf("expected status '%s', got '%s'", domain.PaymentStatusSessionCreated, payment.Status)
+	}
+
+	if payment.Amount != 2500 || payment.Currency != "EUR" {
+		t.Errorf("expected amount 2500 EUR, got %d %s", payment.Amount, payment.Currency)
+	}
+
+	if payment.Provider != "mock" {
+		t.Errorf("expected provider 'mock', got '%s'", payment.Provider)
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_RepositoryError(t *testing.T) {
+	repoErr := errors.New("mongodb error")
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	publisher := &mockEventPublisher{}
+	tripRepository := &mockTripRepository{}
+	paymentRepository := newMockPaymentRepository()
+	paymentRepository.createErr = repoErr
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, paymentRepository, &mockTransactor{})
+
+	err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd")
+	if !errors.Is(err, repoErr) {
+		t.Fatalf("expected error '%v', got '%v'", repoErr, err)
+	}
+
+	if publisher.called {
+		t.Error("expected publisher not to be called")
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_ProviderErrorMarksPaymentFailed(t *testing.T) {
+	provider := &mockPaymentProvider{err: errors.New("stripe api error")}
+	publisher := &mockEventPublisher{}
+	tripRepository := &mockTripRepository{}
+	paymentRepository := newMockPaymentRepository()
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, tripRepository, paymentRepository, &mockTransactor{})
+
+	if err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	payment, err := paymentRepository.GetPaymentByTripID(context.Background(), "trip-1")
+	if err != nil {
+		t.Fatalf("expected payment to be stored, got error: %v", err)
+	}
+
+	if payment.Status != domain.PaymentStatusFailed {
+		t.Errorf("expected status '%s', got '%s'", domain.PaymentStatusFailed, payment.Status)
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_PublishesWithinTransaction(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	publisher := &mockEventPublisher{}
+	transactor := &mockTransactor{}
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, newMockPaymentRepository(), transactor)
+
+	if err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if transactor.calls != 1 {
+		t.Errorf("expected 1 transaction, got %d", transactor.calls)
+	}
+
+	if !publisher.called {
+		t.Error("expected publisher to be called")
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_Idempotent(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	publisher := &mockEventPublisher{}
+	paymentRepository := newMockPaymentRepository()
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, paymentRepository, &mockTransactor{})
+
+	for i := 0; i < 2; i++ {
+		if err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd"); err != nil {
+			t.Fatalf("unexpected error on delivery %d: %v", i+1, err)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("expected provider to be called once, got %d", provider.calls)
+	}
+
+	if len(paymentRepository.payments) != 1 {
+		t.Errorf("expected 1 stored payment, got %d", len(paymentRepository.payments))
+	}
+
+	// The existing session is announced again so the rider can still pay
+	if publisher.event.SessionID != "cs_test_session_123" {
+		t.Errorf("expected republished SessionID 'cs_test_session_123', got '%s'", publisher.event.SessionID)
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_RetriesPendingPayment(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	paymentRepository := newMockPaymentRepository()
+	pending := domain.NewPayment("trip-1", "user-1", "driver-1", 1000, "usd", "mock")
+	paymentRepository.CreatePayment(context.Background(), pending)
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, paymentRepository, &mockTransactor{})
+
+	if err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paymentRepository.payments) != 1 {
+		t.Errorf("expected pending payment to be reused, got %d payments", len(paymentRepository.payments))
+	}
+
+	if provider.idempotencyKey != "payment-session-"+pending.ID {
+		t.Errorf("expected idempotency key derived from payment ID, got '%s'", provider.idempotencyKey)
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_OpenPaymentOnDifferentTerms(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(payment *domain.Payment)
+	}{
+		{name: "other provider", change: func(payment *domain.Payment) { payment.Provider = "x402" }},
+		{name: "manual capture", change: func(payment *domain.Payment) { payment.CaptureMethod = domain.CaptureMethodManual }},
+		{name: "stale fare", change: func(payment *domain.Payment) { payment.Amount = 800 }},
+		{name: "other currency", change: func(payment *domain.Payment) { payment.Currency = "EUR" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockPaymentProvider{sessionID: "cs_test_session_456"}
+			publisher := &mockEventPublisher{}
+			paymentRepository := newMockPaymentRepository()
+			open := domain.NewPayment("trip-1", "user-1", "driver-1", 1000, "USD", "mock")
+			open.ID = "payment-open"
+			open.Status = domain.PaymentStatusSessionCreated
+			open.ProviderSessionID = "cs_test_session_123"
+			tt.change(open)
+			paymentRepository.payments[open.ID] = open
+
+			svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, paymentRepository, &mockTransactor{})
+
+			err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "USD")
+			if !errors.Is(err, ErrOpenPaymentMismatch) {
+				t.Fatalf("expected ErrOpenPaymentMismatch, got %v", err)
+			}
+			if provider.calls != 0 || publisher.called {
+				t.Errorf("expected the open session not to be republished or replaced, got %d provider calls", provider.calls)
+			}
+		})
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_NewPaymentAfterFailure(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	paymentRepository := newMockPaymentRepository()
+	failed := domain.NewPayment("trip-1", "user-1", "driver-1", 1000, "usd", "mock")
+	failed.ID = "payment-failed"
+	failed.Status = domain.PaymentStatusFailed
+	paymentRepository.payments[failed.ID] = failed
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, paymentRepository, &mockTransactor{})
+
+	if err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paymentRepository.payments) != 2 {
+		t.Errorf("expected a new payment after a failed one, got %d payments", len(paymentRepository.payments))
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_AlreadyPaid(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test_session_123"}
+	paymentRepository := newMockPaymentRepository()
+	paid := domain.NewPayment("trip-1", "user-1", "driver-1", 1000, "usd", "mock")
+	paid.ID = "payment-paid"
+	paid.Status = domain.PaymentStatusRefunded
+	paymentRepository.payments[paid.ID] = paid
+
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, paymentRepository, &mockTransactor{})
+
+	err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "usd")
+	if !errors.Is(err, ErrTripAlreadyPaid) {
+		t.Fatalf("expected ErrTripAlreadyPaid, got %v", err)
+	}
+	if provider.calls != 0 || len(paymentRepository.payments) != 1 {
+		t.Errorf("expected no new charge, got %d provider calls and %d payments", provider.calls, len(paymentRepository.payments))
+	}
+}
+
+func TestPaymentService_CreatePaymentSessionWithCard_RejectedFare(t *testing.T) {
+	trip := &types.Trip{
+		UserID:   "user-1",
+		Status:   "pending",
+		RideFare: &types.RideFare{ID: primitive.NewObjectID(), TotalPriceInCents: 2500},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+	provider := &mockPaymentProvider{sessionID: "cs_test"}
+	paymentRepository := newMockPaymentRepository()
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{trip: trip}, paymentRepository, &mockTransactor{})
+
+	err := svc.CreatePaymentSessionWithCard(context.Background(), "trip-1", "user-1")
+	if !errors.Is(err, ErrTripNotPayable) {
+		t.Fatalf("expected ErrTripNotPayable, got %v", err)
+	}
+	if provider.calls != 0 || len(paymentRepository.payments) != 0 {
+		t.Errorf("expected no payment for a refused fare, got %d provider calls and %d payments", provider.calls, len(paymentRepository.payments))
+	}
+}
+
+func TestPaymentService_CreatePaymentSession_UnsupportedCurrency(t *testing.T) {
+	provider := &mockPaymentProvider{sessionID: "cs_test"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, newMockPaymentRepository(), &mockTransactor{})
+
+	err := svc.CreatePaymentSession(context.Background(), "trip-1", "user-1", "driver-1", 1000, "XYZ")
+	if !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		t.Errorf("expected ErrUnsupportedCurrency, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Errorf("expected no provider call, got %d", provider.calls)
+	}
+}
+
+func TestPaymentService_CreatePaymentSessionForTrip_FareCurrency(t *testing.T) {
+	trip := &types.Trip{
+		UserID:   "user-1",
+		Status:   "accepted",
+		RideFare: &types.RideFare{ID: primitive.NewObjectID(), TotalPriceInCents: 1850},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+	publisher := &mockEventPublisher{}
+	repo := newMockPaymentRepository()
+	trips := &mockTripRepository{trip: trip, fareCurrency: "jpy"}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{sessionID: "cs_test"}), testCurrencies, testFares, publisher, trips, repo, &mockTransactor{})
+
+	// The rider's region selects a provider, never the currency of the trip's fare
+	if err := svc.CreatePaymentSessionForTrip(context.Background(), "trip-1", "user-1", PaymentSelection{Region: "KW"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-trip-1"]
+	if payment.Amount != 1850 || payment.Currency != "JPY" {
+		t.Errorf("expected 1850 JPY, got %d %s", payment.Amount, payment.Currency)
+	}
+	// Yen have no minor unit, so the fare is not divided by 100
+	if publisher.event.Amount != 1850 || publisher.event.Currency != "JPY" {
+		t.Errorf("expected event amount 1850 JPY, got %v %s", publisher.event.Amount, publisher.event.Currency)
+	}
+}
+
+func TestPaymentService_CreatePaymentSessionForTrip_DefaultCurrency(t *testing.T) {
+	trip := &types.Trip{
+		UserID:   "user-1",
+		Status:   "accepted",
+		RideFare: &types.RideFare{ID: primitive.NewObjectID(), TotalPriceInCents: 1850},
+		Driver:   &types.Driver{Id: "driver-1"},
+	}
+	repo := newMockPaymentRepository()
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{sessionID: "cs_test"}), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{trip: trip}, repo, &mockTransactor{})
+
+	if err := svc.CreatePaymentSessionForTrip(context.Background(), "trip-1", "user-1", PaymentSelection{Region: "JP"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment := repo.payments["payment-trip-1"]; payment.Currency != "USD" {
+		t.Errorf("expected a trip without currency to be charged in USD, got %s", payment.Currency)
 	}
 }
@@ -0,0 +1,306 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+const (
+	defaultPayoutListLimit = 20
+	maxPayoutListLimit     = 100
+)
+
+// payoutService implements PayoutService interface
+type payoutService struct {
+	provider      PayoutProvider
+	paymentRepo   PaymentRepository
+	accountRepo   DriverAccountRepository
+	payoutRepo    PayoutRepository
+	transactor    Transactor
+	commissionBps int64
+}
+
+// NewPayoutService creates a new payout service. commissionBps is the platform's share of each fare in basis points.
+func NewPayoutService(provider PayoutProvider, paymentRepo PaymentRepository, accountRepo DriverAccountRepository, payoutRepo PayoutRepository, transactor Transactor, commissionBps int64) PayoutService {
+	return &payoutService{
+		provider:      provider,
+		paymentRepo:   paymentRepo,
+		accountRepo:   accountRepo,
+		payoutRepo:    payoutRepo,
+		transactor:    transactor,
+		commissionBps: commissionBps,
+	}
+}
+
+// CreateOnboardingLink returns a link where the driver sets up their connected account,
+// creating the account on first use
+func (s *payoutService) CreateOnboardingLink(ctx context.Context, driverID string) (string, error) {
+	account, err := s.accountRepo.GetDriverAccount(ctx, driverID)
+	if errors.Is(err, domain.ErrDriverAccountNotFound) {
+		account, err = s.createDriverAccount(ctx, driverID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return s.provider.CreateOnboardingLink(ctx, account.ProviderAccountID)
+}
+
+func (s *payoutService) createDriverAccount(ctx context.Context, driverID string) (*domain.DriverAccount, error) {
+	// Keyed by driver so concurrent onboarding requests share one connected account
+	accountID, err := s.provider.CreateConnectedAccount(ctx, driverID, "driver-account-"+driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := domain.NewDriverAccount(driverID, accountID)
+	if err := s.accountRepo.CreateDriverAccount(ctx, account); err != nil {
+		if errors.Is(err, domain.ErrDriverAccountAlreadyExists) {
+			return s.accountRepo.GetDriverAccount(ctx, driverID)
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListDriverPayouts returns the driver's payout ledger, newest first
+func (s *payoutService) ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]*domain.Payout, error) {
+	if limit <= 0 {
+		limit = defaultPayoutListLimit
+	}
+	limit = min(limit, maxPayoutListLimit)
+	return s.payoutRepo.ListPayoutsByDriver(ctx, driverID, limit)
+}
+
+// RunPayouts transfers the earnings of payments created before cutoff to their drivers, one payout
+// per driver and currency. Payouts left unsettled by earlier runs are retried first.
+// Drivers without a payout-enabled account keep their earnings until a later run.
+// The commission on fares drivers collected themselves, e.g. in cash, is debited from their earnings.
+func (s *payoutService) RunPayouts(ctx context.Context, cutoff time.Time) (*PayoutRunResult, error) {
+	result := &PayoutRunResult{}
+
+	unsettled, err := s.payoutRepo.ListUnsettledPayouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, payout := range unsettled {
+		if err := s.retryTransfer(ctx, payout, result); err != nil {
+			return result, err
+		}
+	}
+
+	payments, err := s.paymentRepo.ListPayablePayments(ctx, cutoff)
+	if err != nil {
+		return result, err
+	}
+
+	payouts, err := s.buildPayouts(ctx, payments, result)
+	if err != nil {
+		return result, err
+	}
+
+	debits, err := s.paymentRepo.ListCommissionDuePayments(ctx, cutoff)
+	if err != nil {
+		return result, err
+	}
+	if err := addCommissionDebits(payouts, debits); err != nil {
+		return result, err
+	}
+
+	for _, payout := range payouts {
+		if err := s.claimPayments(ctx, payout); err != nil {
+			if errors.Is(err, errNothingToPay) {
+				continue
+			}
+			return result, err
+		}
+		if err := s.transfer(ctx, payout, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// buildPayouts groups payable payments into one payout per driver and currency
+func (s *payoutService) buildPayouts(ctx context.Context, payments []*domain.Payment, result *PayoutRunResult) ([]*domain.Payout, error) {
+	accounts := make(map[string]*domain.DriverAccount)
+	byKey := make(map[string]*domain.Payout)
+	var payouts []*domain.Payout
+
+	for _, payment := range payments {
+		account, seen := accounts[payment.DriverID]
+		if !seen {
+			var err error
+			if account, err = s.payableAccount(ctx, payment.DriverID); err != nil {
+				return nil, err
+			}
+			accounts[payment.DriverID] = account
+			if account == nil {
+				result.SkippedDrivers++
+			}
+		}
+		if account == nil {
+			continue
+		}
+
+		key := payment.DriverID + "/" + payment.Currency
+		payout, ok := byKey[key]
+		if !ok {
+			payout = domain.NewPayout(account, payment.Currency, s.commissionBps)
+			byKey[key] = payout
+			payouts = append(payouts, payout)
+		}
+		if err := payout.AddPayment(payment); err != nil {
+			return nil, err
+		}
+	}
+	return payouts, nil
+}
+
+// addCommissionDebits deducts the commission owed on driver-collected fares from the drivers' payouts.
+// A debit that would leave nothing to transfer stays due until the driver has earned enough.
+func addCommissionDebits(payouts []*domain.Payout, debits []*domain.Payment) error {
+	byKey := make(map[string]*domain.Payout, len(payouts))
+	for _, payout := range payouts {
+		byKey[payout.DriverID+"/"+payout.Currency] = payout
+	}
+
+	for _, payment := range debits {
+		payout, ok := byKey[payment.DriverID+"/"+payment.Currency]
+		if !ok {
+			continue
+		}
+		commission := domain.PlatformCommission(payment.Amount-payment.RefundedAmount, payout.CommissionBps)
+		if payout.NetAmount <= commission {
+			log.Printf("driver %s has not earned enough to cover the commission of payment %s", payment.DriverID, payment.ID)
+			continue
+		}
+		if err := payout.AddCommissionDebit(payment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payableAccount returns the driver's account if it can receive transfers, or nil when it cannot yet
+func (s *payoutService) payableAccount(ctx context.Context, driverID string) (*domain.DriverAccount, error) {
+	account, err := s.accountRepo.GetDriverAccount(ctx, driverID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDriverAccountNotFound) {
+			log.Printf("driver %s has earnings but no payout account", driverID)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if account.PayoutsEnabled {
+		return account, nil
+	}
+
+	// Onboarding finishes on the provider's side, so ask whether it is complete
+	enabled, err := s.provider.PayoutsEnabled(ctx, account.ProviderAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		log.Printf("driver %s has not finished payout onboarding", driverID)
+		return nil, nil
+	}
+
+	account.EnablePayouts()
+	if err := s.accountRepo.UpdateDriverAccount(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// errNothingToPay aborts recording a payout left with nothing to transfer once payments claimed elsewhere are dropped
+var errNothingToPay = errors.New("nothing left to pay out")
+
+// claimPayments records the payout and links its payments to it in a single unit of work. Each payment is
+// claimed with a conditional update, so one paid out or refunded concurrently is dropped from the payout
+// instead of being paid out or debited twice.
+func (s *payoutService) claimPayments(ctx context.Context, payout *domain.Payout) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.payoutRepo.CreatePayout(ctx, payout); err != nil {
+			return err
+		}
+
+		var unclaimed []string
+		for _, item := range payout.Items {
+			claimed, err := s.paymentRepo.ClaimPayment(ctx, item.PaymentID, payout.ID)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				log.Printf("payment %s is no longer payable, dropping it from payout %s", item.PaymentID, payout.ID)
+				unclaimed = append(unclaimed, item.PaymentID)
+			}
+		}
+		if len(unclaimed) == 0 {
+			return nil
+		}
+
+		for _, paymentID := range unclaimed {
+			payout.RemovePayment(paymentID)
+		}
+		if payout.NetAmount <= 0 {
+			return errNothingToPay
+		}
+		return s.payoutRepo.UpdatePayout(ctx, payout)
+	})
+}
+
+// retryTransfer settles a payout left unsettled by an earlier run. A failed attempt may still have
+// moved the money, e.g. when the provider timed out after executing the transfer, so the provider
+// is asked for the payout's transfer before anything is sent again.
+func (s *payoutService) retryTransfer(ctx context.Context, payout *domain.Payout, result *PayoutRunResult) error {
+	transferID, err := s.provider.FindTransfer(ctx, payout.ID)
+	if err != nil {
+		log.Printf("failed to look up transfer of payout %s: %v", payout.ID, err)
+		return s.recordFailure(ctx, payout, err, result)
+	}
+	if transferID != "" {
+		log.Printf("payout %s was already transferred in %s", payout.ID, transferID)
+		return s.recordPaid(ctx, payout, transferID, result)
+	}
+	return s.transfer(ctx, payout, result)
+}
+
+// transfer sends the payout's net amount to the driver's connected account.
+// Provider failures are recorded on the payout instead of aborting the run.
+func (s *payoutService) transfer(ctx context.Context, payout *domain.Payout, result *PayoutRunResult) error {
+	metadata := map[string]string{
+		"payout_id": payout.ID,
+		"driver_id": payout.DriverID,
+	}
+
+	// One key per payout, so every attempt within the provider's idempotency window is de-duplicated
+	idempotencyKey := "payout-" + payout.ID
+	transferID, err := s.provider.CreateTransfer(ctx, payout.ProviderAccountID, payout.NetAmount, payout.Currency, metadata, idempotencyKey)
+	if err != nil {
+		log.Printf("failed to transfer payout %s to driver %s: %v", payout.ID, payout.DriverID, err)
+		return s.recordFailure(ctx, payout, err, result)
+	}
+	return s.recordPaid(ctx, payout, transferID, result)
+}
+
+func (s *payoutService) recordPaid(ctx context.Context, payout *domain.Payout, transferID string, result *PayoutRunResult) error {
+	if err := payout.MarkPaid(transferID); err != nil {
+		return err
+	}
+	result.Paid++
+	return s.payoutRepo.UpdatePayout(ctx, payout)
+}
+
+func (s *payoutService) recordFailure(ctx context.Context, payout *domain.Payout, cause error, result *PayoutRunResult) error {
+	if err := payout.MarkFailed(cause.Error()); err != nil {
+		return err
+	}
+	result.Failed++
+	return s.payoutRepo.UpdatePayout(ctx, payout)
+}
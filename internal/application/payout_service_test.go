@@ -0,0 +1,441 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// mockPayoutProvider is a mock implementation of PayoutProvider for testing
+type mockPayoutProvider struct {
+	accountID       string
+	payoutsEnabled  bool
+	err             error
+	accountsCreated int
+	transfers       []int64
+	idempotencyKeys []string
+	sentTransfer    string // transfer the provider holds for the payout, found by FindTransfer
+	lookups         int
+}
+
+func (m *mockPayoutProvider) CreateConnectedAccount(ctx context.Context, driverID string, idempotencyKey string) (string, error) {
+	m.accountsCreated++
+	return m.accountID, m.err
+}
+
+func (m *mockPayoutProvider) CreateOnboardingLink(ctx context.Context, accountID string) (string, error) {
+	return "https://connect.stripe.test/onboarding/" + accountID, m.err
+}
+
+func (m *mockPayoutProvider) PayoutsEnabled(ctx context.Context, accountID string) (bool, error) {
+	return m.payoutsEnabled, nil
+}
+
+func (m *mockPayoutProvider) CreateTransfer(ctx context.Context, accountID string, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	m.idempotencyKeys = append(m.idempotencyKeys, idempotencyKey)
+	if m.err != nil {
+		return "", m.err
+	}
+	m.transfers = append(m.transfers, amount)
+	return "tr_test_1", nil
+}
+
+func (m *mockPayoutProvider) FindTransfer(ctx context.Context, payoutID string) (string, error) {
+	m.lookups++
+	return m.sentTransfer, nil
+}
+
+// mockDriverAccountRepository is an in-memory DriverAccountRepository
+type mockDriverAccountRepository struct {
+	accounts map[string]*domain.DriverAccount
+}
+
+func newMockDriverAccountRepository(accounts ...*domain.DriverAccount) *mockDriverAccountRepository {
+	repo := &mockDriverAccountRepository{accounts: make(map[string]*domain.DriverAccount)}
+	for _, account := range accounts {
+		repo.accounts[account.DriverID] = account
+	}
+	return repo
+}
+
+func (m *mockDriverAccountRepository) CreateDriverAccount(ctx context.Context, account *domain.DriverAccount) error {
+	if _, ok := m.accounts[account.DriverID]; ok {
+		return domain.ErrDriverAccountAlreadyExists
+	}
+	stored := *account
+	m.accounts[account.DriverID] = &stored
+	return nil
+}
+
+func (m *mockDriverAccountRepository) UpdateDriverAccount(ctx context.Context, account *domain.DriverAccount) error {
+	stored := *account
+	m.accounts[account.DriverID] = &stored
+	return nil
+}
+
+func (m *mockDriverAccountRepository) GetDriverAccount(ctx context.Context, driverID string) (*domain.DriverAccount, error) {
+	account, ok := m.accounts[driverID]
+	if !ok {
+		return nil, domain.ErrDriverAccountNotFound
+	}
+	found := *account
+	return &found, nil
+}
+
+// mockPayoutRepository is an in-memory PayoutRepository
+type mockPayoutRepository struct {
+	payouts []*domain.Payout
+}
+
+func (m *mockPayoutRepository) CreatePayout(ctx context.Context, payout *domain.Payout) error {
+	payout.ID = "payout-" + payout.DriverID
+	stored := *payout
+	m.payouts = append(m.payouts, &stored)
+	return nil
+}
+
+func (m *mockPayoutRepository) UpdatePayout(ctx context.Context, payout *domain.Payout) error {
+	for i, existing := range m.payouts {
+		if existing.ID == payout.ID {
+			stored := *payout
+			m.payouts[i] = &stored
+			return nil
+		}
+	}
+	return errors.New("payout not found")
+}
+
+func (m *mockPayoutRepository) ListPayoutsByDriver(ctx context.Context, driverID string, limit int) ([]*domain.Payout, error) {
+	var payouts []*domain.Payout
+	for _, payout := range m.payouts {
+		if payout.DriverID == driverID && len(payouts) < limit {
+			payouts = append(payouts, payout)
+		}
+	}
+	return payouts, nil
+}
+
+func (m *mockPayoutRepository) ListUnsettledPayouts(ctx context.Context) ([]*domain.Payout, error) {
+	var payouts []*domain.Payout
+	for _, payout := range m.payouts {
+		if payout.Status != domain.PayoutStatusPaid {
+			found := *payout
+			payouts = append(payouts, &found)
+		}
+	}
+	return payouts, nil
+}
+
+func addPayablePayment(repo *mockPaymentRepository, id, driverID string, amount, refunded int64) {
+	payment := domain.NewPayment("trip-"+id, "user-1", driverID, amount, "usd", "mock")
+	payment.ID = id
+	payment.Status = domain.PaymentStatusCaptured
+	if refunded > 0 {
+		payment.Status = domain.PaymentStatusPartiallyRefunded
+		payment.RefundedAmount = refunded
+	}
+	payment.CreatedAt = payment.CreatedAt.Add(-time.Hour)
+	repo.payments[id] = payment
+}
+
+func TestPayoutService_CreateOnboardingLink_CreatesAccountOnce(t *testing.T) {
+	provider := &mockPayoutProvider{accountID: "acct_1"}
+	accounts := newMockDriverAccountRepository()
+	svc := NewPayoutService(provider, newMockPaymentRepository(), accounts, &mockPayoutRepository{}, &mockTransactor{}, 2000)
+
+	for range 2 {
+		link, err := svc.CreateOnboardingLink(context.Background(), "driver-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if link != "https://connect.stripe.test/onboarding/acct_1" {
+			t.Errorf("unexpected link %s", link)
+		}
+	}
+
+	if provider.accountsCreated != 1 {
+		t.Errorf("expected a single connected account, got %d", provider.accountsCreated)
+	}
+	if accounts.accounts["driver-1"].ProviderAccountID != "acct_1" {
+		t.Errorf("expected account to be stored, got %+v", accounts.accounts["driver-1"])
+	}
+}
+
+func TestPayoutService_RunPayouts(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 2000, 0)
+	addPayablePayment(payments, "payment-2", "driver-1", 1000, 250)
+	addPayablePayment(payments, "payment-3", "driver-2", 1500, 0)
+
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{}
+	payouts := &mockPayoutRepository{}
+	svc := NewPayoutService(provider, payments, newMockDriverAccountRepository(account), payouts, &mockTransactor{}, 2000)
+
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Paid != 1 || result.Failed != 0 || result.SkippedDrivers != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 2000 + (1000 - 250) gross, 20% commission
+	payout := payouts.payouts[0]
+	if payout.GrossAmount != 2750 || payout.Commission != 550 || payout.NetAmount != 2200 {
+		t.Errorf("unexpected payout amounts: gross %d commission %d net %d", payout.GrossAmount, payout.Commission, payout.NetAmount)
+	}
+	if payout.Status != domain.PayoutStatusPaid || payout.ProviderTransferID != "tr_test_1" {
+		t.Errorf("expected paid payout, got %s %s", payout.Status, payout.ProviderTransferID)
+	}
+	if len(provider.transfers) != 1 || provider.transfers[0] != 2200 {
+		t.Errorf("expected a single transfer of 2200, got %v", provider.transfers)
+	}
+
+	if payments.payments["payment-1"].PayoutID != payout.ID || payments.payments["payment-3"].PayoutID != "" {
+		t.Error("expected only driver-1 payments to be claimed by the payout")
+	}
+
+	// Earnings are paid out once
+	result, err = svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Paid != 0 || len(provider.transfers) != 1 {
+		t.Errorf("expected nothing left to pay, got %+v", result)
+	}
+}
+
+// racingPaymentRepository lets another payout run claim payments right after they are listed
+type racingPaymentRepository struct {
+	*mockPaymentRepository
+	claimedElsewhere []string
+}
+
+func (r *racingPaymentRepository) ListPayablePayments(ctx context.Context, createdBefore time.Time) ([]*domain.Payment, error) {
+	payments, err := r.mockPaymentRepository.ListPayablePayments(ctx, createdBefore)
+	for _, paymentID := range r.claimedElsewhere {
+		r.payments[paymentID].PayoutID = "payout-other-run"
+	}
+	return payments, err
+}
+
+func TestPayoutService_RunPayouts_DropsPaymentClaimedElsewhere(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 2000, 0)
+	addPayablePayment(payments, "payment-2", "driver-1", 1000, 0)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{}
+	payouts := &mockPayoutRepository{}
+	repo := &racingPaymentRepository{mockPaymentRepository: payments, claimedElsewhere: []string{"payment-2"}}
+	svc := NewPayoutService(provider, repo, newMockDriverAccountRepository(account), payouts, &mockTransactor{}, 2000)
+
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payout := payouts.payouts[0]
+	if result.Paid != 1 || len(payout.Items) != 1 || payout.Items[0].PaymentID != "payment-1" {
+		t.Fatalf("expected the payout to keep only payment-1, got %+v %+v", result, payout.Items)
+	}
+	if payout.GrossAmount != 2000 || payout.NetAmount != 1600 || len(provider.transfers) != 1 || provider.transfers[0] != 1600 {
+		t.Errorf("expected a transfer of payment-1's earnings only, got payout %+v transfers %v", payout, provider.transfers)
+	}
+	if payments.payments["payment-2"].PayoutID != "payout-other-run" {
+		t.Errorf("expected payment-2 to stay with the other run, got %s", payments.payments["payment-2"].PayoutID)
+	}
+}
+
+func TestPayoutService_RunPayouts_NothingLeftToClaim(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 2000, 0)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{}
+	repo := &racingPaymentRepository{mockPaymentRepository: payments, claimedElsewhere: []string{"payment-1"}}
+	svc := NewPayoutService(provider, repo, newMockDriverAccountRepository(account), &mockPayoutRepository{}, &mockTransactor{}, 2000)
+
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Paid != 0 || len(provider.transfers) != 0 {
+		t.Errorf("expected no transfer, got %+v %v", result, provider.transfers)
+	}
+}
+
+// addCashPayment stores a cash fare the driver confirmed having collected
+func addCashPayment(t *testing.T, repo *mockPaymentRepository, id, driverID string, amount int64) {
+	t.Helper()
+	payment := domain.NewPayment("trip-"+id, "user-1", driverID, amount, "usd", "cash")
+	payment.ID = id
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.CreatedAt = payment.CreatedAt.Add(-time.Hour)
+	repo.payments[id] = payment
+
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}, &mockPaymentProvider{name: "cash"}), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+	if err := svc.ConfirmCashPayment(context.Background(), "trip-"+id, driverID); err != nil {
+		t.Fatalf("failed to confirm cash payment: %v", err)
+	}
+}
+
+func TestPayoutService_RunPayouts_CashNeverPaidOut(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addCashPayment(t, payments, "payment-cash", "driver-1", 1500)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{}
+	payouts := &mockPayoutRepository{}
+	svc := NewPayoutService(provider, payments, newMockDriverAccountRepository(account), payouts, &mockTransactor{}, 2000)
+
+	// The driver already holds the cash fare, so there is nothing to transfer
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Paid != 0 || len(payouts.payouts) != 0 || len(provider.transfers) != 0 {
+		t.Fatalf("expected no payout for a cash fare, got %+v %v", result, provider.transfers)
+	}
+
+	// Once the driver earns card fares, the cash fare's commission is debited from them
+	addPayablePayment(payments, "payment-card", "driver-1", 2000, 0)
+	result, err = svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payout := payouts.payouts[0]
+	for _, item := range payout.Items {
+		if item.PaymentID == "payment-cash" && !item.CommissionDebit {
+			t.Errorf("expected the cash fare only as a commission debit, got %+v", item)
+		}
+	}
+	// 2000 card fare less 20% commission, less the 300 commission on the 1500 cash fare
+	if len(payout.Items) != 2 || payout.GrossAmount != 2000 || payout.Commission != 700 || payout.NetAmount != 1300 {
+		t.Errorf("unexpected payout: gross %d commission %d net %d items %+v", payout.GrossAmount, payout.Commission, payout.NetAmount, payout.Items)
+	}
+	if len(provider.transfers) != 1 || provider.transfers[0] != 1300 {
+		t.Errorf("expected a transfer of 1300, got %v", provider.transfers)
+	}
+	if payments.payments["payment-cash"].PayoutID != payout.ID {
+		t.Error("expected the debited cash fare to be claimed by the payout")
+	}
+
+	// The commission is debited once
+	if _, err := svc.RunPayouts(context.Background(), time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.transfers) != 1 {
+		t.Errorf("expected no further transfer, got %v", provider.transfers)
+	}
+}
+
+func TestPayoutService_RunPayouts_CommissionDebitExceedsEarnings(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addCashPayment(t, payments, "payment-cash", "driver-1", 10000)
+	addPayablePayment(payments, "payment-card", "driver-1", 1000, 0)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{}
+	svc := NewPayoutService(provider, payments, newMockDriverAccountRepository(account), &mockPayoutRepository{}, &mockTransactor{}, 2000)
+
+	if _, err := svc.RunPayouts(context.Background(), time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(provider.transfers) != 1 || provider.transfers[0] != 800 {
+		t.Errorf("expected the card earnings to be paid out, got %v", provider.transfers)
+	}
+	if !payments.payments["payment-cash"].IsCommissionDue() {
+		t.Error("expected the commission to stay due until the driver earns enough")
+	}
+}
+
+func TestPayoutService_RunPayouts_EnablesOnboardedAccount(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 1000, 0)
+	accounts := newMockDriverAccountRepository(domain.NewDriverAccount("driver-1", "acct_1"))
+	provider := &mockPayoutProvider{payoutsEnabled: true}
+	svc := NewPayoutService(provider, payments, accounts, &mockPayoutRepository{}, &mockTransactor{}, 0)
+
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Paid != 1 || provider.transfers[0] != 1000 {
+		t.Errorf("expected full fare to be paid out, got %+v %v", result, provider.transfers)
+	}
+	if !accounts.accounts["driver-1"].PayoutsEnabled {
+		t.Error("expected account to be marked payout-enabled")
+	}
+}
+
+func TestPayoutService_RunPayouts_RetriesFailedTransfer(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 1000, 0)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	provider := &mockPayoutProvider{err: errors.New("insufficient platform balance")}
+	payouts := &mockPayoutRepository{}
+	svc := NewPayoutService(provider, payments, newMockDriverAccountRepository(account), payouts, &mockTransactor{}, 2000)
+
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Failed != 1 || payouts.payouts[0].Status != domain.PayoutStatusFailed {
+		t.Fatalf("expected failed payout, got %+v %s", result, payouts.payouts[0].Status)
+	}
+
+	provider.err = nil
+	result, err = svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Paid != 1 || payouts.payouts[0].Status != domain.PayoutStatusPaid {
+		t.Errorf("expected retried payout to be paid, got %+v %s", result, payouts.payouts[0].Status)
+	}
+	if provider.lookups != 1 {
+		t.Errorf("expected the retry to look up an earlier transfer, got %d lookups", provider.lookups)
+	}
+	if len(provider.idempotencyKeys) != 2 || provider.idempotencyKeys[0] != "payout-payout-driver-1" || provider.idempotencyKeys[1] != provider.idempotencyKeys[0] {
+		t.Errorf("expected every attempt to reuse the payout's key, got %v", provider.idempotencyKeys)
+	}
+}
+
+func TestPayoutService_RunPayouts_FailedTransferAlreadySent(t *testing.T) {
+	payments := newMockPaymentRepository()
+	addPayablePayment(payments, "payment-1", "driver-1", 1000, 0)
+	account := domain.NewDriverAccount("driver-1", "acct_1")
+	account.EnablePayouts()
+	// the transfer was executed but the response timed out, so the attempt is recorded as failed
+	provider := &mockPayoutProvider{err: errors.New("request timed out")}
+	payouts := &mockPayoutRepository{}
+	svc := NewPayoutService(provider, payments, newMockDriverAccountRepository(account), payouts, &mockTransactor{}, 2000)
+
+	if _, err := svc.RunPayouts(context.Background(), time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	provider.err = nil
+	provider.sentTransfer = "tr_sent"
+	result, err := svc.RunPayouts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Paid != 1 || payouts.payouts[0].Status != domain.PayoutStatusPaid || payouts.payouts[0].ProviderTransferID != "tr_sent" {
+		t.Errorf("expected the payout to be settled with the sent transfer, got %+v %+v", result, payouts.payouts[0])
+	}
+	if len(provider.idempotencyKeys) != 1 || len(provider.transfers) != 0 {
+		t.Errorf("expected no second transfer, got keys %v", provider.idempotencyKeys)
+	}
+}
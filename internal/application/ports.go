@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"time"
 
 	"github.com/ride4Low/payment-service/internal/domain"
 )
@@ -9,20 +10,106 @@ import (
 // TripRepository is re-exported from domain for dependency injection convenience
 type TripRepository = domain.TripRepository
 
+// PaymentRepository is re-exported from domain for dependency injection convenience
+type PaymentRepository = domain.PaymentRepository
+
+// DriverAccountRepository is re-exported from domain for dependency injection convenience
+type DriverAccountRepository = domain.DriverAccountRepository
+
+// PayoutRepository is re-exported from domain for dependency injection convenience
+type PayoutRepository = domain.PayoutRepository
+
 // PaymentService is the application service port (use cases)
 type PaymentService interface {
 	CreatePaymentSession(ctx context.Context, tripID, userID, driverID string, amount int64, currency string) error
 	CreatePaymentSessionWithCard(ctx context.Context, tripID, userID string) error
+	CreatePaymentSessionForTrip(ctx context.Context, tripID, userID string, selection PaymentSelection) error
+	HandleProviderEvent(ctx context.Context, event ProviderEvent) error
+	RefundPayment(ctx context.Context, paymentID, requestID string, amount int64, reason string) error
+	AuthorizePaymentWithCard(ctx context.Context, tripID, userID string) error
+	CapturePayment(ctx context.Context, tripID string, amount int64) error
+	ReleasePayment(ctx context.Context, tripID string) error
+	CreatePaymentSessionWithCrypto(ctx context.Context, tripID, userID string) error
+	PayWithCrypto(ctx context.Context, tripID, userID, paymentPayload string) error
+	ConfirmCashPayment(ctx context.Context, tripID, driverID string) error
+}
+
+// PaymentQueryService is the application service port for reading payments (use cases)
+type PaymentQueryService interface {
+	GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error)
+	GetPaymentByTrip(ctx context.Context, tripID string) (*domain.Payment, error)
+	ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*PaymentList, error)
+	ListPaymentsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*PaymentList, error)
+}
+
+// PayoutService is the application service port for driver payouts (use cases)
+type PayoutService interface {
+	CreateOnboardingLink(ctx context.Context, driverID string) (string, error)
+	ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]*domain.Payout, error)
+	RunPayouts(ctx context.Context, cutoff time.Time) (*PayoutRunResult, error)
 }
 
 // PaymentProvider is the port interface for payment providers (Stripe, PayPal, etc.)
 // This is a secondary/driven port - implemented by infrastructure adapters
 type PaymentProvider interface {
-	CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (string, error)
+	Name() string
+	CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error)
+	RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error)
+	CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error)
+	CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error
+	CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error
+	// ExpirePaymentSession closes a session the rider has not completed, so it can no longer be paid
+	ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error
+}
+
+// CryptoPaymentProvider is the port interface for providers settling on-chain payments (x402, etc.)
+// Sessions created through PaymentProvider carry the payment requirements the rider's wallet signs.
+// This is a secondary/driven port - implemented by infrastructure adapters
+type CryptoPaymentProvider interface {
+	PaymentProvider
+	SettlePayment(ctx context.Context, sessionID string, paymentPayload string) (*CryptoSettlement, error)
+	// FindSettlement looks up the on-chain settlement of a payload whose outcome was not recorded.
+	// It returns nil while the payload is unsettled but may still settle, and domain.ErrPaymentDeclined
+	// once it can no longer settle.
+	FindSettlement(ctx context.Context, sessionID string, paymentPayload string) (*CryptoSettlement, error)
+}
+
+// PayoutProvider is the port interface for paying drivers through connected accounts (Stripe Connect, etc.)
+// This is a secondary/driven port - implemented by infrastructure adapters
+type PayoutProvider interface {
+	CreateConnectedAccount(ctx context.Context, driverID string, idempotencyKey string) (string, error)
+	CreateOnboardingLink(ctx context.Context, accountID string) (string, error)
+	PayoutsEnabled(ctx context.Context, accountID string) (bool, error)
+	CreateTransfer(ctx context.Context, accountID string, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error)
+	// FindTransfer returns the ID of the transfer sent for the payout, or an empty ID when there is none
+	FindTransfer(ctx context.Context, payoutID string) (string, error)
 }
 
 // EventPublisher is the port interface for publishing events
 // This is a secondary/driven port - implemented by infrastructure adapters (e.g., RabbitMQ)
 type EventPublisher interface {
 	PublishPaymentSessionCreated(ctx context.Context, event *PaymentSessionCreatedEvent) error
+	PublishPaymentSucceeded(ctx context.Context, event *PaymentSucceededEvent) error
+	PublishPaymentFailed(ctx context.Context, event *PaymentFailedEvent) error
+	PublishPaymentExpired(ctx context.Context, event *PaymentExpiredEvent) error
+	PublishPaymentRefunded(ctx context.Context, event *PaymentRefundedEvent) error
+	PublishPaymentAuthorized(ctx context.Context, event *PaymentAuthorizedEvent) error
+	PublishPaymentCanceled(ctx context.Context, event *PaymentCanceledEvent) error
+}
+
+// Transactor is the port interface for running work in a single unit of work.
+// Repositories and the event publisher called with the context passed to fn share the transaction.
+// This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NonceStore remembers the crypto payment authorization nonces accepted for settlement, so a payload
+// replayed within its validity window is refused instead of being verified a second time.
+// This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
+type NonceStore interface {
+	// Reserve atomically claims the payer's nonce until expiresAt, returning domain.ErrNonceUsed when it is already claimed
+	Reserve(ctx context.Context, from, nonce string, expiresAt time.Time) error
+	// Release frees a reserved nonce whose settlement failed, so the payer may retry it
+	Release(ctx context.Context, from, nonce string) error
 }
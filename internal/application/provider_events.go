@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// HandleProviderEvent applies a payment outcome reported by the provider and publishes the matching event.
+// Events that were already applied (e.g. webhook redeliveries) are acknowledged without side effects.
+func (s *paymentService) HandleProviderEvent(ctx context.Context, event ProviderEvent) error {
+	payment, err := s.findPaymentForEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case ProviderEventPaymentAuthorized:
+		if payment.Status == domain.PaymentStatusAuthorized {
+			return nil
+		}
+		if err := payment.MarkAuthorized(event.ProviderPaymentID); err != nil {
+			return err
+		}
+		if payment.ReleaseRequested {
+			return s.releaseAuthorizedAfterCancel(ctx, payment)
+		}
+		return s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentAuthorized(ctx, &PaymentAuthorizedEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+			})
+		})
+
+	case ProviderEventPaymentSucceeded:
+		if payment.Status == domain.PaymentStatusCaptured {
+			return nil
+		}
+		if err := payment.MarkCaptured(event.ProviderPaymentID); err != nil {
+			return err
+		}
+		if payment.ReleaseRequested {
+			return s.refundCapturedAfterCancel(ctx, payment)
+		}
+		return s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentSucceeded(ctx, &PaymentSucceededEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+			})
+		})
+
+	case ProviderEventPaymentFailed:
+		if payment.Status == domain.PaymentStatusFailed {
+			return nil
+		}
+		if err := payment.MarkFailed(event.FailureReason); err != nil {
+			return err
+		}
+		return s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentFailed(ctx, &PaymentFailedEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+				Reason:           payment.FailureReason,
+			})
+		})
+
+	case ProviderEventAttemptDeclined:
+		if payment.FailureReason == event.FailureReason {
+			return nil
+		}
+		if err := payment.RecordDeclinedAttempt(event.FailureReason); err != nil {
+			return err
+		}
+		return s.paymentRepo.UpdatePayment(ctx, payment)
+
+	case ProviderEventSessionExpired:
+		if payment.Status == domain.PaymentStatusExpired {
+			return nil
+		}
+		if err := payment.MarkExpired(); err != nil {
+			return err
+		}
+		return s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentExpired(ctx, &PaymentExpiredEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+			})
+		})
+
+	case ProviderEventPaymentRefunded:
+		if event.AmountRefunded <= payment.RefundedAmount {
+			return nil
+		}
+		if err := payment.RecordRefund(event.AmountRefunded); err != nil {
+			return err
+		}
+		return s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentRefunded(ctx, &PaymentRefundedEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+				RefundedAmount:   payment.RefundedAmount,
+			})
+		})
+
+	default:
+		return fmt.Errorf("unsupported provider event type: %s", event.Type)
+	}
+}
+
+// findPaymentForEvent resolves the payment an event refers to, preferring the
+// payment ID we attach as provider metadata over the provider's own reference
+func (s *paymentService) findPaymentForEvent(ctx context.Context, event ProviderEvent) (*domain.Payment, error) {
+	if event.PaymentID != "" {
+		return s.paymentRepo.GetPaymentByID(ctx, event.PaymentID)
+	}
+	if event.ProviderPaymentID != "" {
+		return s.paymentRepo.GetPaymentByProviderPaymentID(ctx, event.ProviderPaymentID)
+	}
+	return nil, fmt.Errorf("%w: provider event %s does not reference a payment", domain.ErrPaymentNotFound, event.Type)
+}
+
+func paymentEventData(payment *domain.Payment) PaymentEventData {
+	return PaymentEventData{
+		PaymentID: payment.ID,
+		TripID:    payment.TripID,
+		DriverID:  payment.DriverID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+	}
+}
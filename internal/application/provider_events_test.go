@@ -0,0 +1,206 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+func newPaymentWithSession(repo *mockPaymentRepository) *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 2000, "usd", "mock")
+	payment.ID = "payment-1"
+	payment.Status = domain.PaymentStatusSessionCreated
+	payment.ProviderSessionID = "cs_test_123"
+	stored := *payment
+	repo.payments[payment.ID] = &stored
+	return payment
+}
+
+func TestPaymentService_HandleProviderEvent_Succeeded(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newPaymentWithSession(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:              ProviderEventPaymentSucceeded,
+		PaymentID:         "payment-1",
+		ProviderPaymentID: "pi_test_456",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusCaptured {
+		t.Errorf("expected status '%s', got '%s'", domain.PaymentStatusCaptured, payment.Status)
+	}
+
+	if payment.ProviderPaymentID != "pi_test_456" {
+		t.Errorf("expected ProviderPaymentID 'pi_test_456', got '%s'", payment.ProviderPaymentID)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected 1 published event, got %d", len(publisher.published))
+	}
+
+	event, ok := publisher.published[0].(*PaymentSucceededEvent)
+	if !ok {
+		t.Fatalf("expected *PaymentSucceededEvent, got %T", publisher.published[0])
+	}
+
+	if event.UserID != "user-1" || event.TripID != "trip-1" || event.Amount != 2000 {
+		t.Errorf("unexpected event data: %+v", event)
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_DuplicateIsIgnored(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newPaymentWithSession(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	event := ProviderEvent{Type: ProviderEventSessionExpired, PaymentID: "payment-1"}
+	for i := 0; i < 2; i++ {
+		if err := svc.HandleProviderEvent(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error on delivery %d: %v", i+1, err)
+		}
+	}
+
+	if len(publisher.published) != 1 {
+		t.Errorf("expected 1 published event, got %d", len(publisher.published))
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_Failed(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newPaymentWithSession(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:          ProviderEventPaymentFailed,
+		PaymentID:     "payment-1",
+		FailureReason: "card declined",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusFailed || payment.FailureReason != "card declined" {
+		t.Errorf("expected failed payment with reason, got %s '%s'", payment.Status, payment.FailureReason)
+	}
+
+	if _, ok := publisher.published[0].(*PaymentFailedEvent); !ok {
+		t.Errorf("expected *PaymentFailedEvent, got %T", publisher.published[0])
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_DeclinedAttemptThenCompleted(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newPaymentWithSession(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:              ProviderEventAttemptDeclined,
+		ProviderPaymentID: "pi_test_456",
+		PaymentID:         "payment-1",
+		FailureReason:     "Your card was declined.",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusSessionCreated || payment.FailureReason != "Your card was declined." {
+		t.Errorf("expected an open payment with the decline reason, got %s '%s'", payment.Status, payment.FailureReason)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("expected no published events for a declined attempt, got %d", len(publisher.published))
+	}
+
+	// The rider retries with another card in the same session
+	err = svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:              ProviderEventPaymentSucceeded,
+		PaymentID:         "payment-1",
+		ProviderPaymentID: "pi_test_456",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment = repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusCaptured || payment.FailureReason != "" {
+		t.Errorf("expected a captured payment without failure reason, got %s '%s'", payment.Status, payment.FailureReason)
+	}
+	if _, ok := publisher.published[0].(*PaymentSucceededEvent); !ok {
+		t.Errorf("expected *PaymentSucceededEvent, got %T", publisher.published[0])
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_RefundByProviderPaymentID(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newPaymentWithSession(repo)
+	repo.payments[payment.ID].Status = domain.PaymentStatusCaptured
+	repo.payments[payment.ID].ProviderPaymentID = "pi_test_456"
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:              ProviderEventPaymentRefunded,
+		ProviderPaymentID: "pi_test_456",
+		AmountRefunded:    500,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored := repo.payments[payment.ID]
+	if stored.Status != domain.PaymentStatusPartiallyRefunded || stored.RefundedAmount != 500 {
+		t.Errorf("expected partially refunded 500, got %s %d", stored.Status, stored.RefundedAmount)
+	}
+
+	refunded, ok := publisher.published[0].(*PaymentRefundedEvent)
+	if !ok {
+		t.Fatalf("expected *PaymentRefundedEvent, got %T", publisher.published[0])
+	}
+	if refunded.RefundedAmount != 500 {
+		t.Errorf("expected RefundedAmount 500, got %d", refunded.RefundedAmount)
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_InvalidTransition(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newPaymentWithSession(repo)
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:           ProviderEventPaymentRefunded,
+		PaymentID:      "payment-1",
+		AmountRefunded: 500,
+	})
+	if !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+
+	if len(publisher.published) != 0 {
+		t.Errorf("expected no published events, got %d", len(publisher.published))
+	}
+}
+
+func TestPaymentService_HandleProviderEvent_PaymentNotFound(t *testing.T) {
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{}), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, newMockPaymentRepository(), &mockTransactor{})
+
+	err := svc.HandleProviderEvent(context.Background(), ProviderEvent{
+		Type:      ProviderEventPaymentSucceeded,
+		PaymentID: "missing",
+	})
+	if !errors.Is(err, domain.ErrPaymentNotFound) {
+		t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+	}
+}
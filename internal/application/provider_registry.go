@@ -0,0 +1,84 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrProviderNotFound is returned when no registered provider matches a payment method or name
+var ErrProviderNotFound = errors.New("payment provider not found")
+
+// Payment methods riders can choose; each is served by the provider configured for it
+const (
+	PaymentMethodCard   = "card"
+	PaymentMethodCrypto = "crypto"
+	PaymentMethodCash   = "cash"
+)
+
+// ProviderRegistryConfig maps payment methods, regions and currencies to registered provider names
+type ProviderRegistryConfig struct {
+	Default          string            // provider used when nothing more specific matches
+	Methods          map[string]string // payment method -> provider, e.g. "card" -> "stripe"
+	RegionDefaults   map[string]string // lowercase region -> provider, used when the request names no method
+	CurrencyDefaults map[string]string // ISO currency code -> provider, used when no region default matches
+}
+
+// ProviderRegistry holds the named payment providers and picks one per request
+type ProviderRegistry struct {
+	config    ProviderRegistryConfig
+	providers map[string]PaymentProvider
+}
+
+// NewProviderRegistry creates a registry of the given providers, keyed by their Name.
+// Every provider referenced by the config must be registered.
+func NewProviderRegistry(config ProviderRegistryConfig, providers ...PaymentProvider) (*ProviderRegistry, error) {
+	r := &ProviderRegistry{config: config, providers: make(map[string]PaymentProvider, len(providers))}
+	for _, provider := range providers {
+		if _, ok := r.providers[provider.Name()]; ok {
+			return nil, fmt.Errorf("payment provider %s registered twice", provider.Name())
+		}
+		r.providers[provider.Name()] = provider
+	}
+
+	if _, err := r.Get(config.Default); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+	for _, mapping := range []map[string]string{config.Methods, config.RegionDefaults, config.CurrencyDefaults} {
+		for key, name := range mapping {
+			if _, err := r.Get(name); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Get returns the provider registered under name
+func (r *ProviderRegistry) Get(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrProviderNotFound, name)
+	}
+	return provider, nil
+}
+
+// Resolve picks the provider for a payment: the rider's chosen method wins, then the region default,
+// then the currency default, then the registry default
+func (r *ProviderRegistry) Resolve(selection PaymentSelection, currency string) (PaymentProvider, error) {
+	if selection.Method != "" {
+		name, ok := r.config.Methods[strings.ToLower(selection.Method)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported payment method %q", ErrProviderNotFound, selection.Method)
+		}
+		return r.Get(name)
+	}
+	region := strings.ToLower(strings.TrimSpace(selection.Region))
+	if name, ok := r.config.RegionDefaults[region]; ok && region != "" {
+		return r.Get(name)
+	}
+	if name, ok := r.config.CurrencyDefaults[strings.ToUpper(currency)]; ok {
+		return r.Get(name)
+	}
+	return r.Get(r.config.Default)
+}
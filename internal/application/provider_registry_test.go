@@ -0,0 +1,77 @@
+package application
+
+import (
+	"errors"
+	"testing"
+)
+
+func newRoutingRegistry(t *testing.T) *ProviderRegistry {
+	t.Helper()
+	registry, err := NewProviderRegistry(ProviderRegistryConfig{
+		Default:          "stripe",
+		Methods:          map[string]string{PaymentMethodCard: "stripe", PaymentMethodCash: "cash"},
+		RegionDefaults:   map[string]string{"latam": "local"},
+		CurrencyDefaults: map[string]string{"BRL": "local"},
+	},
+		&mockPaymentProvider{name: "stripe"},
+		&mockPaymentProvider{name: "cash"},
+		&mockPaymentProvider{name: "local"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return registry
+}
+
+func TestProviderRegistry_Resolve(t *testing.T) {
+	registry := newRoutingRegistry(t)
+
+	tests := []struct {
+		name      string
+		selection PaymentSelection
+		currency  string
+		want      string
+	}{
+		{name: "method wins over region", selection: PaymentSelection{Method: "CASH", Region: "latam"}, currency: "BRL", want: "cash"},
+		{name: "region default", selection: PaymentSelection{Region: "latam"}, currency: "USD", want: "local"},
+		{name: "mixed-case region", selection: PaymentSelection{Region: " LatAm "}, currency: "USD", want: "local"},
+		{name: "currency default", selection: PaymentSelection{Region: "eu"}, currency: "brl", want: "local"},
+		{name: "registry default", currency: "USD", want: "stripe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := registry.Resolve(tt.selection, tt.currency)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if provider.Name() != tt.want {
+				t.Errorf("expected provider %s, got %s", tt.want, provider.Name())
+			}
+		})
+	}
+}
+
+func TestProviderRegistry_Resolve_UnsupportedMethod(t *testing.T) {
+	registry := newRoutingRegistry(t)
+
+	_, err := registry.Resolve(PaymentSelection{Method: PaymentMethodCrypto}, "USD")
+	if !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+}
+
+func TestNewProviderRegistry_InvalidConfig(t *testing.T) {
+	stripe := &mockPaymentProvider{name: "stripe"}
+
+	if _, err := NewProviderRegistry(ProviderRegistryConfig{Default: "stripe"}, stripe, &mockPaymentProvider{name: "stripe"}); err == nil {
+		t.Error("expected error for duplicate provider")
+	}
+	if _, err := NewProviderRegistry(ProviderRegistryConfig{Default: "adyen"}, stripe); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound for unknown default, got %v", err)
+	}
+	config := ProviderRegistryConfig{Default: "stripe", Methods: map[string]string{PaymentMethodCrypto: "x402"}}
+	if _, err := NewProviderRegistry(config, stripe); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound for unknown method provider, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// maxConflictRetries bounds how often an issued refund is reapplied to a payment updated concurrently
+const maxConflictRetries = 3
+
+// RefundPayment refunds part or all of a captured payment through its provider.
+// Amount is in the currency's minor units; it is checked against what was already refunded
+// before the provider is called, so a payment can never be over-refunded. The caller's
+// requestID identifies the refund: a request that was already applied is acknowledged
+// without refunding again, and retries reuse the same provider idempotency key.
+func (s *paymentService) RefundPayment(ctx context.Context, paymentID, requestID string, amount int64, reason string) error {
+	if requestID == "" {
+		return fmt.Errorf("%w: payment %s", domain.ErrRefundRequestIDRequired, paymentID)
+	}
+
+	payment, err := s.paymentRepo.GetPaymentByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := payment.RefundForRequest(requestID); ok {
+		return nil
+	}
+
+	if err := payment.CheckRefund(amount); err != nil {
+		return err
+	}
+
+	if payment.ProviderPaymentID == "" {
+		return fmt.Errorf("payment %s has no provider payment reference to refund", payment.ID)
+	}
+
+	provider, err := s.providerFor(payment)
+	if err != nil {
+		return err
+	}
+
+	// Keyed on the caller's request, not on the refund state, so neither a redelivered command after
+	// a refund webhook nor two distinct refunds of the same amount can be confused at the provider
+	idempotencyKey := fmt.Sprintf("payment-refund-%s-%s", payment.ID, requestID)
+	refundID, err := provider.RefundPayment(ctx, payment.ProviderPaymentID, amount, reason, idempotencyKey)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := payment.ApplyRefund(requestID, refundID, amount, reason); err != nil {
+			return err
+		}
+
+		err := s.savePayment(ctx, payment, func(ctx context.Context) error {
+			return s.publisher.PublishPaymentRefunded(ctx, &PaymentRefundedEvent{
+				UserID:           payment.UserID,
+				PaymentEventData: paymentEventData(payment),
+				RefundedAmount:   payment.RefundedAmount,
+			})
+		})
+		if !errors.Is(err, domain.ErrPaymentConflict) || attempt == maxConflictRetries {
+			return err
+		}
+
+		// Another refund or a webhook updated the payment meanwhile. The provider refund was issued,
+		// so it is applied again on top of the latest state, unless that state already records it.
+		if payment, err = s.paymentRepo.GetPaymentByID(ctx, paymentID); err != nil {
+			return err
+		}
+		if _, ok := payment.RefundForRequest(requestID); ok {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+func newCapturedPayment(repo *mockPaymentRepository) *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 2000, "usd", "mock")
+	payment.ID = "payment-1"
+	payment.Status = domain.PaymentStatusCaptured
+	payment.ProviderPaymentID = "pi_test_123"
+	repo.payments[payment.ID] = payment
+	return payment
+}
+
+func TestPaymentService_RefundPayment_Partial(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, "requested_by_customer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusPartiallyRefunded || payment.RefundedAmount != 500 {
+		t.Errorf("expected partially refunded 500, got %s %d", payment.Status, payment.RefundedAmount)
+	}
+
+	if len(payment.Refunds) != 1 || payment.Refunds[0].ProviderRefundID != "re_test_1" {
+		t.Errorf("expected refund to be recorded, got %+v", payment.Refunds)
+	}
+
+	refunded, ok := publisher.published[0].(*PaymentRefundedEvent)
+	if !ok {
+		t.Fatalf("expected *PaymentRefundedEvent, got %T", publisher.published[0])
+	}
+	if refunded.RefundedAmount != 500 {
+		t.Errorf("expected RefundedAmount 500, got %d", refunded.RefundedAmount)
+	}
+}
+
+func TestPaymentService_RefundPayment_RemainingAmount(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	provider.refundID = "re_test_2"
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-2", 1500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.Status != domain.PaymentStatusRefunded || payment.RefundedAmount != 2000 {
+		t.Errorf("expected fully refunded 2000, got %s %d", payment.Status, payment.RefundedAmount)
+	}
+}
+
+func TestPaymentService_RefundPayment_OverRefund(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCapturedPayment(repo)
+	payment.Status = domain.PaymentStatusPartiallyRefunded
+	payment.RefundedAmount = 1500
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 600, "")
+	if !errors.Is(err, domain.ErrInvalidRefundAmount) {
+		t.Fatalf("expected ErrInvalidRefundAmount, got %v", err)
+	}
+
+	if provider.calls != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_RefundPayment_NotCaptured(t *testing.T) {
+	repo := newMockPaymentRepository()
+	payment := newCapturedPayment(repo)
+	payment.Status = domain.PaymentStatusSessionCreated
+	provider := &mockPaymentProvider{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, "")
+	if !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+
+	if provider.calls != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
+
+func TestPaymentService_RefundPayment_ProviderError(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	providerErr := errors.New("stripe api error")
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(&mockPaymentProvider{err: providerErr}), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, "")
+	if !errors.Is(err, providerErr) {
+		t.Fatalf("expected error '%v', got '%v'", providerErr, err)
+	}
+
+	if repo.payments["payment-1"].RefundedAmount != 0 {
+		t.Error("expected refunded amount to be unchanged")
+	}
+
+	if len(publisher.published) != 0 {
+		t.Error("expected no published events")
+	}
+}
+
+func TestPaymentService_RefundPayment_IdempotencyKey(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.idempotencyKey != "payment-refund-payment-1-refund-request-1" {
+		t.Errorf("unexpected idempotency key %q", provider.idempotencyKey)
+	}
+
+	// a second refund of the same amount is a distinct request with its own key
+	provider.refundID = "re_test_2"
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-2", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.idempotencyKey != "payment-refund-payment-1-refund-request-2" {
+		t.Errorf("unexpected idempotency key %q", provider.idempotencyKey)
+	}
+	if payment := repo.payments["payment-1"]; payment.RefundedAmount != 1000 || len(payment.Refunds) != 2 {
+		t.Errorf("expected two refunds totalling 1000, got %d in %+v", payment.RefundedAmount, payment.Refunds)
+	}
+}
+
+func TestPaymentService_RefundPayment_Redelivered(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	for range 2 {
+		if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("expected a single provider refund, got %d", provider.calls)
+	}
+	if payment := repo.payments["payment-1"]; payment.RefundedAmount != 500 {
+		t.Errorf("expected 500 refunded, got %d", payment.RefundedAmount)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("expected a single refunded event, got %d", len(publisher.published))
+	}
+}
+
+func TestPaymentService_RefundPayment_ProviderRefundAlreadyRecorded(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	// the provider answers with a refund that is already recorded under another request
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-1", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-2", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payment := repo.payments["payment-1"]; payment.RefundedAmount != 500 || len(payment.Refunds) != 1 {
+		t.Errorf("expected the provider refund to be counted once, got %d in %+v", payment.RefundedAmount, payment.Refunds)
+	}
+}
+
+func TestPaymentService_RefundPayment_ConcurrentRefund(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_2"}
+	provider.onRefund = func() {
+		// Another refund request is stored while this one is issued at the provider
+		other, _ := repo.GetPaymentByID(context.Background(), "payment-1")
+		if err := other.ApplyRefund("refund-request-1", "re_test_1", 300, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.UpdatePayment(context.Background(), other); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	publisher := &mockEventPublisher{}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, publisher, &mockTripRepository{}, repo, &mockTransactor{})
+
+	if err := svc.RefundPayment(context.Background(), "payment-1", "refund-request-2", 500, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payment := repo.payments["payment-1"]
+	if payment.RefundedAmount != 800 || len(payment.Refunds) != 2 {
+		t.Errorf("expected both refunds to be kept, got %d %+v", payment.RefundedAmount, payment.Refunds)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected the refund to be issued once, got %d calls", provider.calls)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("expected 1 published event, got %d", len(publisher.published))
+	}
+}
+
+func TestPaymentService_RefundPayment_MissingRequestID(t *testing.T) {
+	repo := newMockPaymentRepository()
+	newCapturedPayment(repo)
+	provider := &mockPaymentProvider{refundID: "re_test_1"}
+	svc := NewPaymentService(newTestRegistry(provider), testCurrencies, testFares, &mockEventPublisher{}, &mockTripRepository{}, repo, &mockTransactor{})
+
+	err := svc.RefundPayment(context.Background(), "payment-1", "", 500, "")
+	if !errors.Is(err, domain.ErrRefundRequestIDRequired) {
+		t.Fatalf("expected ErrRefundRequestIDRequired, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Error("expected provider not to be called")
+	}
+}
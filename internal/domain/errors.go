@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPaymentNotFound is returned when no payment matches the lookup
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrPaymentAlreadyExists is returned when a trip already has an open payment
+	ErrPaymentAlreadyExists = errors.New("open payment already exists for trip")
+	// ErrPaymentConflict is returned when a payment was updated by someone else since it was loaded
+	ErrPaymentConflict = errors.New("payment was modified concurrently")
+	// ErrInvalidTransition is returned when a payment cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+	// ErrInvalidCaptureAmount is returned when a capture does not fit in the authorized hold
+	ErrInvalidCaptureAmount = errors.New("invalid capture amount")
+	// ErrInvalidRefundAmount is returned when a refund would exceed the refundable amount
+	ErrInvalidRefundAmount = errors.New("invalid refund amount")
+	// ErrRefundRequestIDRequired is returned when a refund is requested without the caller's refund request ID
+	ErrRefundRequestIDRequired = errors.New("refund request ID required")
+	// ErrDriverAccountNotFound is returned when a driver has no connected payout account
+	ErrDriverAccountNotFound = errors.New("driver account not found")
+	// ErrUnsupportedCurrency is returned for currencies that are not ISO 4217 codes the service handles
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrDriverAccountAlreadyExists is returned when a driver already has a connected payout account
+	ErrDriverAccountAlreadyExists = errors.New("driver account already exists")
+	// ErrInvalidPageToken is returned when a pagination token does not identify a position in a listing
+	ErrInvalidPageToken = errors.New("invalid page token")
+	// ErrPayoutNotFound is returned when no payout matches the lookup
+	ErrPayoutNotFound = errors.New("payout not found")
+	// ErrTripNotFound is returned when no trip matches the lookup
+	ErrTripNotFound = errors.New("trip not found")
+	// ErrInvalidTripID is returned when a trip ID is not a valid trip identifier
+	ErrInvalidTripID = errors.New("invalid trip ID")
+	// ErrNotTripOwner is returned when a user acts on a trip booked by another rider
+	ErrNotTripOwner = errors.New("user does not own trip")
+	// ErrNotTripDriver is returned when a driver acts on a trip driven by someone else
+	ErrNotTripDriver = errors.New("driver did not drive trip")
+	// ErrPaymentMethodMismatch is returned when an operation does not apply to the payment's method
+	ErrPaymentMethodMismatch = errors.New("payment method mismatch")
+	// ErrProviderUnavailable is returned when a payment provider cannot be reached or keeps failing
+	ErrProviderUnavailable = errors.New("payment provider unavailable")
+	// ErrPaymentDeclined is returned when a payment provider refuses the rider's payment
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrNonceUsed is returned when a crypto payment authorization nonce was already accepted for settlement
+	ErrNonceUsed = errors.New("authorization nonce already used")
+)
+
+// TripError describes a failed trip lookup or access check
+type TripError struct {
+	TripID string
+	Err    error // ErrTripNotFound, ErrInvalidTripID, ErrNotTripOwner or ErrNotTripDriver
+	Cause  error // underlying error, if any
+}
+
+func (e *TripError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("trip %s: %v", e.TripID, e.Err)
+	}
+	return fmt.Sprintf("trip %s: %v: %v", e.TripID, e.Err, e.Cause)
+}
+
+// Unwrap allows errors.Is to match both the sentinel error and the underlying cause
+func (e *TripError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// ProviderError describes a payment provider call that failed
+type ProviderError struct {
+	Provider string
+	Op       string
+	Err      error // ErrProviderUnavailable or ErrPaymentDeclined
+	Cause    error // error or refusal reported by the provider
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s %s: %v: %v", e.Provider, e.Op, e.Err, e.Cause)
+}
+
+// Unwrap allows errors.Is to match both the sentinel error and the provider's error
+func (e *ProviderError) Unwrap() []error {
+	return []error{e.Err, e.Cause}
+}
+
+// InvalidTransitionError describes a rejected payment status transition
+type InvalidTransitionError struct {
+	PaymentID string
+	From      PaymentStatus
+	To        PaymentStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("payment %s: cannot transition from %s to %s", e.PaymentID, e.From, e.To)
+}
+
+// Is allows errors.Is(err, ErrInvalidTransition) to match
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// RefundAmountError describes a refund that does not fit in the refundable amount
+type RefundAmountError struct {
+	PaymentID  string
+	Requested  int64
+	Refundable int64
+}
+
+func (e *RefundAmountError) Error() string {
+	return fmt.Sprintf("payment %s: cannot refund %d, refundable amount is %d", e.PaymentID, e.Requested, e.Refundable)
+}
+
+// Is allows errors.Is(err, ErrInvalidRefundAmount) to match
+func (e *RefundAmountError) Is(target error) bool {
+	return target == ErrInvalidRefundAmount
+}
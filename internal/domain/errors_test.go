@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTripError(t *testing.T) {
+	cause := errors.New("encoding/hex: invalid byte")
+	err := error(&TripError{TripID: "not-hex", Err: ErrInvalidTripID, Cause: cause})
+
+	if !errors.Is(err, ErrInvalidTripID) || !errors.Is(err, cause) {
+		t.Errorf("expected sentinel and cause to match, got %v", err)
+	}
+	if errors.Is(err, ErrTripNotFound) {
+		t.Error("expected ErrTripNotFound not to match")
+	}
+	if err.Error() != "trip not-hex: invalid trip ID: encoding/hex: invalid byte" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	notFound := &TripError{TripID: "trip-1", Err: ErrTripNotFound}
+	if notFound.Error() != "trip trip-1: trip not found" {
+		t.Errorf("unexpected message %q", notFound.Error())
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	cause := errors.New("503 service unavailable")
+	err := error(&ProviderError{Provider: "stripe", Op: "refund payment", Err: ErrProviderUnavailable, Cause: cause})
+
+	if !errors.Is(err, ErrProviderUnavailable) || !errors.Is(err, cause) {
+		t.Errorf("expected sentinel and cause to match, got %v", err)
+	}
+	if errors.Is(err, ErrPaymentDeclined) {
+		t.Error("expected ErrPaymentDeclined not to match")
+	}
+	if err.Error() != "stripe refund payment: payment provider unavailable: 503 service unavailable" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents holds the ISO 4217 minor unit exponent of each supported currency:
+// the number of decimal places between the major unit and the minor unit amounts are stored in
+var currencyExponents = map[string]int{
+	// Zero-decimal currencies: amounts are whole units
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+
+	// Three-decimal currencies: amounts are thousandths
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+
+	// Two-decimal currencies: amounts are hundredths (cents)
+	"AED": 2, "ARS": 2, "AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "COP": 2,
+	"CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "KES": 2, "MAD": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2,
+	"NZD": 2, "PEN": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "ZAR": 2,
+}
+
+// CurrencyExponent returns the number of decimal places of the ISO 4217 currency
+func CurrencyExponent(currency string) (int, error) {
+	exponent, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// Money is an exact amount in a currency's minor units, e.g. 1850 USD is 18.50 dollars
+// while 1850 JPY is 1850 yen
+type Money struct {
+	Amount   int64
+	Currency string // ISO 4217 code, upper case
+}
+
+// NewMoney creates an amount of minor units in the given ISO 4217 currency
+func NewMoney(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if _, err := CurrencyExponent(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Exponent returns the number of decimal places of the currency, 2 for unknown currencies
+func (m Money) Exponent() int {
+	if exponent, err := CurrencyExponent(m.Currency); err == nil {
+		return exponent
+	}
+	return 2
+}
+
+// Decimal formats the amount in major units with the currency's decimal places, e.g. "18.50", "1850" or "1.250"
+func (m Money) Decimal() string {
+	exponent := m.Exponent()
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Major returns the amount in major units as a float, for payloads that cannot carry exact amounts
+func (m Money) Major() float64 {
+	major, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return major
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMoney(t *testing.T) {
+	money, err := NewMoney(1850, "usd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if money.Currency != "USD" {
+		t.Errorf("expected upper-case currency, got %s", money.Currency)
+	}
+
+	if _, err := NewMoney(1850, "XYZ"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("expected ErrUnsupportedCurrency, got %v", err)
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+		major float64
+	}{
+		{money: Money{Amount: 1850, Currency: "USD"}, want: "18.50", major: 18.5},
+		{money: Money{Amount: 5, Currency: "EUR"}, want: "0.05", major: 0.05},
+		{money: Money{Amount: 1850, Currency: "JPY"}, want: "1850", major: 1850},
+		{money: Money{Amount: 3500, Currency: "KRW"}, want: "3500", major: 3500},
+		{money: Money{Amount: 1250, Currency: "KWD"}, want: "1.250", major: 1.25},
+		{money: Money{Amount: 7, Currency: "BHD"}, want: "0.007", major: 0.007},
+		{money: Money{Amount: -1850, Currency: "USD"}, want: "-18.50", major: -18.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.money.String(), func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if got := tt.money.Major(); got != tt.major {
+				t.Errorf("expected %v, got %v", tt.major, got)
+			}
+		})
+	}
+}
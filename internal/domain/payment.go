@@ -1 +1,407 @@
 package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// PaymentStatus represents the lifecycle state of a payment
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusSessionCreated    PaymentStatus = "session_created"
+	PaymentStatusSettling          PaymentStatus = "settling" // crypto payload handed to the chain, outcome not recorded yet
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusCaptured          PaymentStatus = "captured"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusExpired           PaymentStatus = "expired"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusDisputed          PaymentStatus = "disputed"
+	PaymentStatusCanceled          PaymentStatus = "canceled"
+)
+
+// CaptureMethod controls when the funds of a payment are collected
+type CaptureMethod string
+
+const (
+	// CaptureMethodAutomatic charges the rider as soon as the payment is completed
+	CaptureMethodAutomatic CaptureMethod = "automatic"
+	// CaptureMethodManual places a hold that is captured or released later
+	CaptureMethodManual CaptureMethod = "manual"
+)
+
+// paymentTransitions lists the states each payment state may move to.
+// States without an entry are terminal.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {
+		PaymentStatusSessionCreated,
+		PaymentStatusFailed,
+	},
+	PaymentStatusSessionCreated: {
+		PaymentStatusSettling,
+		PaymentStatusAuthorized,
+		PaymentStatusCaptured,
+		PaymentStatusFailed,
+		PaymentStatusExpired,
+		PaymentStatusCanceled,
+	},
+	PaymentStatusSettling: {
+		PaymentStatusCaptured,
+		PaymentStatusSessionCreated,
+	},
+	PaymentStatusAuthorized: {
+		PaymentStatusCaptured,
+		PaymentStatusFailed,
+		PaymentStatusExpired,
+		PaymentStatusCanceled,
+	},
+	PaymentStatusCaptured: {
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusRefunded,
+		PaymentStatusDisputed,
+	},
+	PaymentStatusPartiallyRefunded: {
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusRefunded,
+		PaymentStatusDisputed,
+	},
+	PaymentStatusDisputed: {
+		PaymentStatusCaptured,
+		PaymentStatusRefunded,
+	},
+}
+
+// CanTransitionTo reports whether a payment in status s may move to next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible from s
+func (s PaymentStatus) IsTerminal() bool {
+	return len(paymentTransitions[s]) == 0
+}
+
+// OpenPaymentStatuses returns the states in which a payment may still collect funds
+func OpenPaymentStatuses() []PaymentStatus {
+	return []PaymentStatus{PaymentStatusPending, PaymentStatusSessionCreated, PaymentStatusSettling, PaymentStatusAuthorized}
+}
+
+// PayablePaymentStatuses returns the states in which a payment's earnings may be paid out to the driver
+func PayablePaymentStatuses() []PaymentStatus {
+	return []PaymentStatus{PaymentStatusCaptured, PaymentStatusPartiallyRefunded}
+}
+
+// PaidPaymentStatuses returns the states in which the rider's money was collected, even if refunded since
+func PaidPaymentStatuses() []PaymentStatus {
+	return []PaymentStatus{PaymentStatusCaptured, PaymentStatusPartiallyRefunded, PaymentStatusRefunded, PaymentStatusDisputed}
+}
+
+// Refund records a single refund issued against a payment
+type Refund struct {
+	RequestID        string // caller's ID for the refund request, the idempotency key towards the provider
+	ProviderRefundID string
+	Amount           int64
+	Reason           string
+	CreatedAt        time.Time
+}
+
+// Payment is the aggregate root for a single charge attempt against a trip
+type Payment struct {
+	ID                string
+	TripID            string
+	UserID            string
+	DriverID          string
+	Amount            int64 // amount in the currency's minor units (e.g. cents)
+	AuthorizedAmount  int64 // amount held for manual capture, in minor units
+	Currency          string
+	CaptureMethod     CaptureMethod
+	Provider          string
+	ProviderSessionID string
+	ProviderPaymentID string // provider charge reference (e.g. Stripe payment intent)
+	TransactionHash   string // on-chain settlement transaction of crypto payments
+	SettlingPayload   string // hash of the crypto payload being settled, to recognize its redelivery
+	Status            PaymentStatus
+	FailureReason     string
+	RefundedAmount    int64
+	Refunds           []Refund
+	PayoutID          string // driver payout that included this payment, empty until paid out
+	CollectedByDriver bool   // fare paid to the driver directly, e.g. in cash, so the platform holds none of it
+	ReleaseRequested  bool   // trip cancelled; funds collected after this are returned to the rider
+	Version           int64  // incremented on every stored update, so concurrent writers cannot overwrite each other
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// NewPayment creates a new pending payment for the given trip
+func NewPayment(tripID, userID, driverID string, amount int64, currency, provider string) *Payment {
+	now := time.Now().UTC()
+	return &Payment{
+		TripID:        tripID,
+		UserID:        userID,
+		DriverID:      driverID,
+		Amount:        amount,
+		Currency:      currency,
+		CaptureMethod: CaptureMethodAutomatic,
+		Provider:      provider,
+		Status:        PaymentStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// Money returns the payment amount with its currency
+func (p *Payment) Money() Money {
+	return Money{Amount: p.Amount, Currency: p.Currency}
+}
+
+// IsOpen reports whether the payment may still collect funds, so no other payment should be started for its trip
+func (p *Payment) IsOpen() bool {
+	return slices.Contains(OpenPaymentStatuses(), p.Status)
+}
+
+// IsPaid reports whether the rider's money was collected for the payment
+func (p *Payment) IsPaid() bool {
+	return slices.Contains(PaidPaymentStatuses(), p.Status)
+}
+
+// IsPayable reports whether the payment holds driver earnings that were not paid out yet
+func (p *Payment) IsPayable() bool {
+	return !p.CollectedByDriver && p.PayoutID == "" && slices.Contains(PayablePaymentStatuses(), p.Status)
+}
+
+// IsCommissionDue reports whether the driver collected the fare and still owes the platform its commission
+func (p *Payment) IsCommissionDue() bool {
+	return p.CollectedByDriver && p.PayoutID == "" && slices.Contains(PayablePaymentStatuses(), p.Status)
+}
+
+// AssignPayout records the driver payout that includes this payment's earnings or commission debit
+func (p *Payment) AssignPayout(payoutID string) error {
+	if !p.IsPayable() && !p.IsCommissionDue() {
+		return fmt.Errorf("payment %s in status %s cannot be paid out", p.ID, p.Status)
+	}
+	p.PayoutID = payoutID
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// TransitionTo moves the payment to the next status, enforcing the lifecycle rules
+func (p *Payment) TransitionTo(next PaymentStatus) error {
+	if !p.Status.CanTransitionTo(next) {
+		return &InvalidTransitionError{PaymentID: p.ID, From: p.Status, To: next}
+	}
+	p.Status = next
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// AttachSession records the provider session created for this payment
+func (p *Payment) AttachSession(sessionID string) error {
+	if err := p.TransitionTo(PaymentStatusSessionCreated); err != nil {
+		return err
+	}
+	p.ProviderSessionID = sessionID
+	return nil
+}
+
+// MarkCaptured records that the provider has collected the funds
+func (p *Payment) MarkCaptured(providerPaymentID string) error {
+	if err := p.TransitionTo(PaymentStatusCaptured); err != nil {
+		return err
+	}
+	if providerPaymentID != "" {
+		p.ProviderPaymentID = providerPaymentID
+	}
+	p.FailureReason = ""
+	return nil
+}
+
+// StartSettlement records that the crypto payload with the given hash is being settled on-chain,
+// before it is handed to the provider
+func (p *Payment) StartSettlement(payloadHash string) error {
+	if err := p.TransitionTo(PaymentStatusSettling); err != nil {
+		return err
+	}
+	p.SettlingPayload = payloadHash
+	return nil
+}
+
+// AbandonSettlement reopens the session after the provider refused the payload, so the rider may pay again
+func (p *Payment) AbandonSettlement() error {
+	if err := p.TransitionTo(PaymentStatusSessionCreated); err != nil {
+		return err
+	}
+	p.SettlingPayload = ""
+	return nil
+}
+
+// MarkCollectedByDriver records that the driver collected the fare directly, e.g. in cash.
+// The driver keeps the fare, so the platform's commission is debited from their next payout.
+func (p *Payment) MarkCollectedByDriver() error {
+	if err := p.TransitionTo(PaymentStatusCaptured); err != nil {
+		return err
+	}
+	p.CollectedByDriver = true
+	return nil
+}
+
+// MarkSettled records that a crypto payment was settled on-chain in the given transaction
+func (p *Payment) MarkSettled(transactionHash string) error {
+	if err := p.TransitionTo(PaymentStatusCaptured); err != nil {
+		return err
+	}
+	p.TransactionHash = transactionHash
+	p.SettlingPayload = ""
+	return nil
+}
+
+// MarkAuthorized records that the provider placed a hold for the payment amount
+func (p *Payment) MarkAuthorized(providerPaymentID string) error {
+	if err := p.TransitionTo(PaymentStatusAuthorized); err != nil {
+		return err
+	}
+	p.AuthorizedAmount = p.Amount
+	if providerPaymentID != "" {
+		p.ProviderPaymentID = providerPaymentID
+	}
+	return nil
+}
+
+// CheckCapture validates capturing the given final amount from the authorized hold
+func (p *Payment) CheckCapture(amount int64) error {
+	if p.Status != PaymentStatusAuthorized {
+		return &InvalidTransitionError{PaymentID: p.ID, From: p.Status, To: PaymentStatusCaptured}
+	}
+	if amount <= 0 || amount > p.AuthorizedAmount {
+		return fmt.Errorf("%w: payment %s: cannot capture %d, authorized amount is %d", ErrInvalidCaptureAmount, p.ID, amount, p.AuthorizedAmount)
+	}
+	return nil
+}
+
+// Capture charges the final amount from the authorized hold, releasing the rest
+func (p *Payment) Capture(amount int64) error {
+	if err := p.CheckCapture(amount); err != nil {
+		return err
+	}
+	if err := p.TransitionTo(PaymentStatusCaptured); err != nil {
+		return err
+	}
+	p.Amount = amount
+	return nil
+}
+
+// RequestRelease records that the trip was cancelled before the provider is asked to release the payment,
+// so a completion racing the release is recognized and returned to the rider
+func (p *Payment) RequestRelease() error {
+	if !p.Status.CanTransitionTo(PaymentStatusCanceled) {
+		return &InvalidTransitionError{PaymentID: p.ID, From: p.Status, To: PaymentStatusCanceled}
+	}
+	p.ReleaseRequested = true
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Cancel releases the authorized hold, or abandons the unpaid checkout, without charging the rider
+func (p *Payment) Cancel() error {
+	return p.TransitionTo(PaymentStatusCanceled)
+}
+
+// MarkFailed records that the payment attempt failed with the given reason
+func (p *Payment) MarkFailed(reason string) error {
+	if err := p.TransitionTo(PaymentStatusFailed); err != nil {
+		return err
+	}
+	p.FailureReason = reason
+	return nil
+}
+
+// RecordDeclinedAttempt keeps the reason a payment attempt was declined while the payment stays open,
+// so the rider may retry with another method
+func (p *Payment) RecordDeclinedAttempt(reason string) error {
+	if !p.IsOpen() {
+		return &InvalidTransitionError{PaymentID: p.ID, From: p.Status, To: p.Status}
+	}
+	p.FailureReason = reason
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// MarkExpired records that the provider session expired without payment
+func (p *Payment) MarkExpired() error {
+	return p.TransitionTo(PaymentStatusExpired)
+}
+
+// RefundableAmount returns how much of the payment can still be refunded
+func (p *Payment) RefundableAmount() int64 {
+	return p.Amount - p.RefundedAmount
+}
+
+// CheckRefund validates a refund of the given amount without changing the payment
+func (p *Payment) CheckRefund(amount int64) error {
+	next := PaymentStatusPartiallyRefunded
+	if amount == p.RefundableAmount() {
+		next = PaymentStatusRefunded
+	}
+	if !p.Status.CanTransitionTo(next) {
+		return &InvalidTransitionError{PaymentID: p.ID, From: p.Status, To: next}
+	}
+	if amount <= 0 || amount > p.RefundableAmount() {
+		return &RefundAmountError{PaymentID: p.ID, Requested: amount, Refundable: p.RefundableAmount()}
+	}
+	return nil
+}
+
+// RefundForRequest returns the refund recorded for the caller's refund request, if any
+func (p *Payment) RefundForRequest(requestID string) (Refund, bool) {
+	i := slices.IndexFunc(p.Refunds, func(r Refund) bool { return r.RequestID == requestID })
+	if i < 0 {
+		return Refund{}, false
+	}
+	return p.Refunds[i], true
+}
+
+// ApplyRefund records a refund issued by the provider on top of earlier refunds.
+// A provider refund that is already recorded is not counted again.
+func (p *Payment) ApplyRefund(requestID, providerRefundID string, amount int64, reason string) error {
+	if slices.ContainsFunc(p.Refunds, func(r Refund) bool { return r.ProviderRefundID == providerRefundID }) {
+		return nil
+	}
+	if err := p.CheckRefund(amount); err != nil {
+		return err
+	}
+	if err := p.RecordRefund(p.RefundedAmount + amount); err != nil {
+		return err
+	}
+	p.Refunds = append(p.Refunds, Refund{
+		RequestID:        requestID,
+		ProviderRefundID: providerRefundID,
+		Amount:           amount,
+		Reason:           reason,
+		CreatedAt:        p.UpdatedAt,
+	})
+	return nil
+}
+
+// RecordRefund records the total amount refunded so far for this payment
+func (p *Payment) RecordRefund(totalRefunded int64) error {
+	if totalRefunded <= 0 || totalRefunded > p.Amount {
+		return &RefundAmountError{PaymentID: p.ID, Requested: totalRefunded - p.RefundedAmount, Refundable: p.Amount - p.RefundedAmount}
+	}
+
+	next := PaymentStatusPartiallyRefunded
+	if totalRefunded == p.Amount {
+		next = PaymentStatusRefunded
+	}
+	if err := p.TransitionTo(next); err != nil {
+		return err
+	}
+	p.RefundedAmount = totalRefunded
+	return nil
+}
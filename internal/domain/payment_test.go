@@ -1 +1,280 @@
 package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPayment(t *testing.T) {
+	payment := NewPayment("trip-1", "user-1", "driver-1", 1500, "usd", "stripe")
+
+	if payment.Status != PaymentStatusPending {
+		t.Errorf("expected status '%s', got '%s'", PaymentStatusPending, payment.Status)
+	}
+
+	if payment.Amount != 1500 {
+		t.Errorf("expected amount 1500, got %d", payment.Amount)
+	}
+
+	if payment.CreatedAt.IsZero() || !payment.CreatedAt.Equal(payment.UpdatedAt) {
+		t.Errorf("expected CreatedAt and UpdatedAt to be set to the same instant")
+	}
+}
+
+func TestPayment_AttachSession(t *testing.T) {
+	payment := NewPayment("trip-1", "user-1", "driver-1", 1500, "usd", "stripe")
+	payment.AttachSession("cs_test_123")
+
+	if payment.ProviderSessionID != "cs_test_123" {
+		t.Errorf("expected ProviderSessionID 'cs_test_123', got '%s'", payment.ProviderSessionID)
+	}
+
+	if payment.Status != PaymentStatusSessionCreated {
+		t.Errorf("expected status '%s', got '%s'", PaymentStatusSessionCreated, payment.Status)
+	}
+}
+
+func TestPaymentStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    PaymentStatus
+		to      PaymentStatus
+		allowed bool
+	}{
+		{"pending to session created", PaymentStatusPending, PaymentStatusSessionCreated, true},
+		{"pending to failed", PaymentStatusPending, PaymentStatusFailed, true},
+		{"pending to captured", PaymentStatusPending, PaymentStatusCaptured, false},
+		{"pending to refunded", PaymentStatusPending, PaymentStatusRefunded, false},
+		{"session created to authorized", PaymentStatusSessionCreated, PaymentStatusAuthorized, true},
+		{"session created to captured", PaymentStatusSessionCreated, PaymentStatusCaptured, true},
+		{"session created to failed", PaymentStatusSessionCreated, PaymentStatusFailed, true},
+		{"session created to expired", PaymentStatusSessionCreated, PaymentStatusExpired, true},
+		{"session created to canceled", PaymentStatusSessionCreated, PaymentStatusCanceled, true},
+		{"session created to settling", PaymentStatusSessionCreated, PaymentStatusSettling, true},
+		{"settling to captured", PaymentStatusSettling, PaymentStatusCaptured, true},
+		{"settling back to session created", PaymentStatusSettling, PaymentStatusSessionCreated, true},
+		{"settling to canceled", PaymentStatusSettling, PaymentStatusCanceled, false},
+		{"session created to refunded", PaymentStatusSessionCreated, PaymentStatusRefunded, false},
+		{"authorized to captured", PaymentStatusAuthorized, PaymentStatusCaptured, true},
+		{"authorized to expired", PaymentStatusAuthorized, PaymentStatusExpired, true},
+		{"authorized to refunded", PaymentStatusAuthorized, PaymentStatusRefunded, false},
+		{"captured to refunded", PaymentStatusCaptured, PaymentStatusRefunded, true},
+		{"captured to partially refunded", PaymentStatusCaptured, PaymentStatusPartiallyRefunded, true},
+		{"captured to disputed", PaymentStatusCaptured, PaymentStatusDisputed, true},
+		{"captured to failed", PaymentStatusCaptured, PaymentStatusFailed, false},
+		{"partially refunded to partially refunded", PaymentStatusPartiallyRefunded, PaymentStatusPartiallyRefunded, true},
+		{"partially refunded to refunded", PaymentStatusPartiallyRefunded, PaymentStatusRefunded, true},
+		{"disputed to captured", PaymentStatusDisputed, PaymentStatusCaptured, true},
+		{"disputed to refunded", PaymentStatusDisputed, PaymentStatusRefunded, true},
+		{"failed to session created", PaymentStatusFailed, PaymentStatusSessionCreated, false},
+		{"expired to captured", PaymentStatusExpired, PaymentStatusCaptured, false},
+		{"refunded to captured", PaymentStatusRefunded, PaymentStatusCaptured, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{ID: "payment-1", Status: tt.from}
+
+			err := payment.TransitionTo(tt.to)
+
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("expected transition to be allowed, got error: %v", err)
+				}
+				if payment.Status != tt.to {
+					t.Errorf("expected status '%s', got '%s'", tt.to, payment.Status)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("expected ErrInvalidTransition, got %v", err)
+			}
+
+			var transitionErr *InvalidTransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("expected *InvalidTransitionError, got %T", err)
+			}
+			if transitionErr.From != tt.from || transitionErr.To != tt.to {
+				t.Errorf("expected transition %s -> %s, got %s -> %s", tt.from, tt.to, transitionErr.From, transitionErr.To)
+			}
+			if payment.Status != tt.from {
+				t.Errorf("expected status to remain '%s', got '%s'", tt.from, payment.Status)
+			}
+		})
+	}
+}
+
+func TestPaymentStatus_IsTerminal(t *testing.T) {
+	terminal := []PaymentStatus{PaymentStatusFailed, PaymentStatusExpired, PaymentStatusRefunded}
+	for _, status := range terminal {
+		if !status.IsTerminal() {
+			t.Errorf("expected '%s' to be terminal", status)
+		}
+	}
+
+	if PaymentStatusCaptured.IsTerminal() {
+		t.Errorf("expected '%s' not to be terminal", PaymentStatusCaptured)
+	}
+}
+
+func TestPayment_AttachSession_InvalidState(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Status: PaymentStatusCaptured}
+
+	if err := payment.AttachSession("cs_test_123"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+
+	if payment.ProviderSessionID != "" {
+		t.Errorf("expected ProviderSessionID to stay empty, got '%s'", payment.ProviderSessionID)
+	}
+}
+
+func TestPayment_RecordRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       PaymentStatus
+		refunded   int64
+		total      int64
+		wantStatus PaymentStatus
+		wantErr    error
+	}{
+		{"partial refund", PaymentStatusCaptured, 0, 400, PaymentStatusPartiallyRefunded, nil},
+		{"full refund", PaymentStatusCaptured, 0, 1000, PaymentStatusRefunded, nil},
+		{"completes partial refund", PaymentStatusPartiallyRefunded, 400, 1000, PaymentStatusRefunded, nil},
+		{"exceeds amount", PaymentStatusCaptured, 0, 1200, PaymentStatusCaptured, ErrInvalidRefundAmount},
+		{"zero amount", PaymentStatusCaptured, 0, 0, PaymentStatusCaptured, ErrInvalidRefundAmount},
+		{"not captured", PaymentStatusSessionCreated, 0, 400, PaymentStatusSessionCreated, ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{ID: "payment-1", Amount: 1000, Status: tt.from, RefundedAmount: tt.refunded}
+
+			err := payment.RecordRefund(tt.total)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if payment.Status != tt.wantStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.wantStatus, payment.Status)
+			}
+		})
+	}
+}
+
+func TestPayment_ApplyRefund(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 1000, Status: PaymentStatusCaptured}
+
+	if err := payment.ApplyRefund("request-1", "re_1", 300, "requested_by_customer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payment.ApplyRefund("request-2", "re_2", 800, ""); !errors.Is(err, ErrInvalidRefundAmount) {
+		t.Fatalf("expected ErrInvalidRefundAmount, got %v", err)
+	}
+	if err := payment.ApplyRefund("request-2", "re_2", 700, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payment.Status != PaymentStatusRefunded {
+		t.Errorf("expected status '%s', got '%s'", PaymentStatusRefunded, payment.Status)
+	}
+
+	if payment.RefundedAmount != 1000 || payment.RefundableAmount() != 0 {
+		t.Errorf("expected 1000 refunded and nothing refundable, got %d and %d", payment.RefundedAmount, payment.RefundableAmount())
+	}
+
+	if len(payment.Refunds) != 2 || payment.Refunds[0].ProviderRefundID != "re_1" || payment.Refunds[1].Amount != 700 {
+		t.Errorf("unexpected refunds: %+v", payment.Refunds)
+	}
+}
+
+func TestPayment_Capture(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 2500, Status: PaymentStatusSessionCreated, CaptureMethod: CaptureMethodManual}
+
+	if err := payment.MarkAuthorized("pi_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.AuthorizedAmount != 2500 || payment.ProviderPaymentID != "pi_1" {
+		t.Fatalf("expected hold of 2500 on pi_1, got %d on %s", payment.AuthorizedAmount, payment.ProviderPaymentID)
+	}
+
+	if err := payment.Capture(3000); !errors.Is(err, ErrInvalidCaptureAmount) {
+		t.Fatalf("expected ErrInvalidCaptureAmount, got %v", err)
+	}
+	if err := payment.Capture(1800); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payment.Status != PaymentStatusCaptured || payment.Amount != 1800 {
+		t.Errorf("expected captured 1800, got %s %d", payment.Status, payment.Amount)
+	}
+	if err := payment.Capture(1800); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition on second capture, got %v", err)
+	}
+}
+
+func TestPayment_Cancel(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 2500, Status: PaymentStatusAuthorized}
+
+	if err := payment.Cancel(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Status != PaymentStatusCanceled || !payment.Status.IsTerminal() {
+		t.Errorf("expected terminal status '%s', got '%s'", PaymentStatusCanceled, payment.Status)
+	}
+
+	captured := &Payment{ID: "payment-2", Status: PaymentStatusCaptured}
+	if err := captured.Cancel(); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestPayment_RequestRelease(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 2500, Status: PaymentStatusSessionCreated}
+
+	if err := payment.RequestRelease(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !payment.ReleaseRequested || payment.Status != PaymentStatusSessionCreated {
+		t.Errorf("expected an open payment marked for release, got %s %v", payment.Status, payment.ReleaseRequested)
+	}
+
+	captured := &Payment{ID: "payment-2", Status: PaymentStatusCaptured}
+	if err := captured.RequestRelease(); !errors.Is(err, ErrInvalidTransition) || captured.ReleaseRequested {
+		t.Errorf("expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestPayment_RecordDeclinedAttempt(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 2500, Status: PaymentStatusSessionCreated}
+
+	if err := payment.RecordDeclinedAttempt("card declined"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Status != PaymentStatusSessionCreated || payment.FailureReason != "card declined" {
+		t.Errorf("expected an open payment with the decline reason, got %s '%s'", payment.Status, payment.FailureReason)
+	}
+
+	captured := &Payment{ID: "payment-2", Status: PaymentStatusCaptured}
+	if err := captured.RecordDeclinedAttempt("card declined"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestPayment_AssignPayout(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Amount: 1000, Status: PaymentStatusCaptured}
+
+	if !payment.IsPayable() {
+		t.Fatal("expected captured payment to be payable")
+	}
+	if err := payment.AssignPayout("payout-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.IsPayable() {
+		t.Error("expected paid out payment not to be payable")
+	}
+	if err := payment.AssignPayout("payout-2"); err == nil {
+		t.Error("expected error when assigning a second payout")
+	}
+}
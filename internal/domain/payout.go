@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// PayoutStatus represents the lifecycle state of a driver payout
+type PayoutStatus string
+
+const (
+	PayoutStatusPending PayoutStatus = "pending"
+	PayoutStatusPaid    PayoutStatus = "paid"
+	PayoutStatusFailed  PayoutStatus = "failed"
+)
+
+// basisPointsPerUnit is the number of basis points in 100%
+const basisPointsPerUnit = 10000
+
+// DriverAccount links a driver to their connected account at the payout provider
+type DriverAccount struct {
+	DriverID          string
+	ProviderAccountID string
+	PayoutsEnabled    bool // set once the driver finished onboarding and the provider accepts transfers
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// NewDriverAccount creates a driver account for a freshly created connected account
+func NewDriverAccount(driverID, providerAccountID string) *DriverAccount {
+	now := time.Now().UTC()
+	return &DriverAccount{
+		DriverID:          driverID,
+		ProviderAccountID: providerAccountID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// EnablePayouts records that the provider now accepts transfers to the account
+func (a *DriverAccount) EnablePayouts() {
+	a.PayoutsEnabled = true
+	a.UpdatedAt = time.Now().UTC()
+}
+
+// PlatformCommission returns the platform's share of amount for a commission rate in basis points,
+// rounded half up to the nearest minor unit
+func PlatformCommission(amount, commissionBps int64) int64 {
+	return (amount*commissionBps + basisPointsPerUnit/2) / basisPointsPerUnit
+}
+
+// PayoutItem is the driver's share of a single trip payment
+type PayoutItem struct {
+	PaymentID       string
+	TripID          string
+	GrossAmount     int64 // amount kept from the rider after refunds, in minor units
+	Commission      int64
+	NetAmount       int64
+	CommissionDebit bool // commission owed on a fare the driver collected, with a negative NetAmount
+}
+
+// Payout is a ledger entry for a single transfer of earnings to a driver
+type Payout struct {
+	ID                 string
+	DriverID           string
+	ProviderAccountID  string
+	Currency           string
+	GrossAmount        int64
+	Commission         int64
+	NetAmount          int64
+	CommissionBps      int64
+	Items              []PayoutItem
+	Status             PayoutStatus
+	ProviderTransferID string
+	FailureReason      string
+	Attempts           int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// NewPayout creates an empty pending payout to the driver's connected account
+func NewPayout(account *DriverAccount, currency string, commissionBps int64) *Payout {
+	now := time.Now().UTC()
+	return &Payout{
+		DriverID:          account.DriverID,
+		ProviderAccountID: account.ProviderAccountID,
+		Currency:          currency,
+		CommissionBps:     commissionBps,
+		Status:            PayoutStatusPending,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// AddPayment adds the driver's earnings from a captured payment, taking the platform commission
+func (p *Payout) AddPayment(payment *Payment) error {
+	if payment.DriverID != p.DriverID || payment.Currency != p.Currency {
+		return fmt.Errorf("payment %s does not belong to payout for driver %s in %s", payment.ID, p.DriverID, p.Currency)
+	}
+	if !payment.IsPayable() {
+		return fmt.Errorf("payment %s in status %s cannot be paid out", payment.ID, payment.Status)
+	}
+
+	gross := payment.Amount - payment.RefundedAmount
+	commission := PlatformCommission(gross, p.CommissionBps)
+	p.Items = append(p.Items, PayoutItem{
+		PaymentID:   payment.ID,
+		TripID:      payment.TripID,
+		GrossAmount: gross,
+		Commission:  commission,
+		NetAmount:   gross - commission,
+	})
+	p.GrossAmount += gross
+	p.Commission += commission
+	p.NetAmount += gross - commission
+	return nil
+}
+
+// AddCommissionDebit deducts the platform commission on a fare the driver collected, e.g. in cash
+func (p *Payout) AddCommissionDebit(payment *Payment) error {
+	if payment.DriverID != p.DriverID || payment.Currency != p.Currency {
+		return fmt.Errorf("payment %s does not belong to payout for driver %s in %s", payment.ID, p.DriverID, p.Currency)
+	}
+	if !payment.IsCommissionDue() {
+		return fmt.Errorf("payment %s owes no commission", payment.ID)
+	}
+
+	commission := PlatformCommission(payment.Amount-payment.RefundedAmount, p.CommissionBps)
+	p.Items = append(p.Items, PayoutItem{
+		PaymentID:       payment.ID,
+		TripID:          payment.TripID,
+		Commission:      commission,
+		NetAmount:       -commission,
+		CommissionDebit: true,
+	})
+	p.Commission += commission
+	p.NetAmount -= commission
+	return nil
+}
+
+// RemovePayment takes a payment's earnings or commission debit back out of a payout that was not transferred yet
+func (p *Payout) RemovePayment(paymentID string) {
+	for i, item := range p.Items {
+		if item.PaymentID != paymentID {
+			continue
+		}
+		p.GrossAmount -= item.GrossAmount
+		p.Commission -= item.Commission
+		p.NetAmount -= item.NetAmount
+		p.Items = slices.Delete(p.Items, i, i+1)
+		return
+	}
+}
+
+// MarkPaid records the provider transfer that paid out the driver's earnings
+func (p *Payout) MarkPaid(providerTransferID string) error {
+	if p.Status == PayoutStatusPaid {
+		return fmt.Errorf("payout %s is already paid", p.ID)
+	}
+	p.Status = PayoutStatusPaid
+	p.ProviderTransferID = providerTransferID
+	p.FailureReason = ""
+	p.Attempts++
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// MarkFailed records a failed transfer attempt; failed payouts are retried by the next payout run
+func (p *Payout) MarkFailed(reason string) error {
+	if p.Status == PayoutStatusPaid {
+		return fmt.Errorf("payout %s is already paid", p.ID)
+	}
+	p.Status = PayoutStatusFailed
+	p.FailureReason = reason
+	p.Attempts++
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
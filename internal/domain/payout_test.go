@@ -0,0 +1,114 @@
+package domain
+
+import "testing"
+
+func TestPlatformCommission(t *testing.T) {
+	tests := []struct {
+		amount, bps, want int64
+	}{
+		{amount: 2000, bps: 2000, want: 400},
+		{amount: 999, bps: 1500, want: 150}, // 149.85 rounds up
+		{amount: 1001, bps: 1250, want: 125},
+		{amount: 1000, bps: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := PlatformCommission(tt.amount, tt.bps); got != tt.want {
+			t.Errorf("PlatformCommission(%d, %d) = %d, want %d", tt.amount, tt.bps, got, tt.want)
+		}
+	}
+}
+
+func TestPayout_AddPayment(t *testing.T) {
+	payout := NewPayout(NewDriverAccount("driver-1", "acct_1"), "usd", 2000)
+
+	captured := &Payment{ID: "payment-1", TripID: "trip-1", DriverID: "driver-1", Currency: "usd", Amount: 2000, Status: PaymentStatusCaptured}
+	refunded := &Payment{ID: "payment-2", TripID: "trip-2", DriverID: "driver-1", Currency: "usd", Amount: 1000, RefundedAmount: 500, Status: PaymentStatusPartiallyRefunded}
+	if err := payout.AddPayment(captured); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payout.AddPayment(refunded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payout.GrossAmount != 2500 || payout.Commission != 500 || payout.NetAmount != 2000 {
+		t.Errorf("unexpected totals: gross %d commission %d net %d", payout.GrossAmount, payout.Commission, payout.NetAmount)
+	}
+	if len(payout.Items) != 2 || payout.Items[1].NetAmount != 400 {
+		t.Errorf("unexpected items: %+v", payout.Items)
+	}
+
+	otherDriver := &Payment{ID: "payment-3", DriverID: "driver-2", Currency: "usd", Amount: 1000, Status: PaymentStatusCaptured}
+	if err := payout.AddPayment(otherDriver); err == nil {
+		t.Error("expected error for another driver's payment")
+	}
+	fullyRefunded := &Payment{ID: "payment-4", DriverID: "driver-1", Currency: "usd", Amount: 1000, RefundedAmount: 1000, Status: PaymentStatusRefunded}
+	if err := payout.AddPayment(fullyRefunded); err == nil {
+		t.Error("expected error for a refunded payment")
+	}
+}
+
+func TestPayout_AddCommissionDebit(t *testing.T) {
+	payout := NewPayout(NewDriverAccount("driver-1", "acct_1"), "usd", 2000)
+	card := &Payment{ID: "payment-1", DriverID: "driver-1", Currency: "usd", Amount: 2000, Status: PaymentStatusCaptured}
+	cash := &Payment{ID: "payment-2", DriverID: "driver-1", Currency: "usd", Amount: 1500, Status: PaymentStatusCaptured, CollectedByDriver: true}
+
+	if err := payout.AddPayment(cash); err == nil {
+		t.Error("expected a driver-collected fare not to be paid out")
+	}
+	if err := payout.AddPayment(card); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payout.AddCommissionDebit(card); err == nil {
+		t.Error("expected no commission debit for a fare the platform collected")
+	}
+	if err := payout.AddCommissionDebit(cash); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payout.GrossAmount != 2000 || payout.Commission != 700 || payout.NetAmount != 1300 {
+		t.Errorf("unexpected totals: gross %d commission %d net %d", payout.GrossAmount, payout.Commission, payout.NetAmount)
+	}
+	if debit := payout.Items[1]; !debit.CommissionDebit || debit.NetAmount != -300 {
+		t.Errorf("unexpected debit: %+v", debit)
+	}
+}
+
+func TestPayout_RemovePayment(t *testing.T) {
+	payout := NewPayout(NewDriverAccount("driver-1", "acct_1"), "usd", 2000)
+	for _, payment := range []*Payment{
+		{ID: "payment-1", DriverID: "driver-1", Currency: "usd", Amount: 2000, Status: PaymentStatusCaptured},
+		{ID: "payment-2", DriverID: "driver-1", Currency: "usd", Amount: 1000, Status: PaymentStatusCaptured},
+	} {
+		if err := payout.AddPayment(payment); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	payout.RemovePayment("payment-1")
+
+	if len(payout.Items) != 1 || payout.Items[0].PaymentID != "payment-2" {
+		t.Fatalf("unexpected items: %+v", payout.Items)
+	}
+	if payout.GrossAmount != 1000 || payout.Commission != 200 || payout.NetAmount != 800 {
+		t.Errorf("unexpected totals: gross %d commission %d net %d", payout.GrossAmount, payout.Commission, payout.NetAmount)
+	}
+}
+
+func TestPayout_MarkPaid(t *testing.T) {
+	payout := NewPayout(NewDriverAccount("driver-1", "acct_1"), "usd", 2000)
+
+	if err := payout.MarkFailed("account restricted"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payout.MarkPaid("tr_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payout.Status != PayoutStatusPaid || payout.FailureReason != "" || payout.Attempts != 2 {
+		t.Errorf("unexpected payout state: %s %q %d", payout.Status, payout.FailureReason, payout.Attempts)
+	}
+	if err := payout.MarkFailed("late failure"); err == nil {
+		t.Error("expected error when failing a paid payout")
+	}
+}
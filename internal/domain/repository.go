@@ -2,12 +2,69 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/ride4Low/contracts/types"
 )
 
+// Trip is a trip as recorded by the trip service
+type Trip struct {
+	*types.Trip
+	// FareCurrency is the ISO 4217 code the trip service priced the fare in. The contracts fare type
+	// does not carry it yet, so it is read from the trip record; it is empty for trips priced before
+	// the trip service recorded it.
+	FareCurrency string
+}
+
 // TripRepository is the port interface for payment persistence
 // This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
 type TripRepository interface {
-	GetTripByID(ctx context.Context, tripID string) (*types.Trip, error)
+	GetTripByID(ctx context.Context, tripID string) (*Trip, error)
+}
+
+// PaymentRepository is the port interface for payment aggregate persistence
+// This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, payment *Payment) error
+	// UpdatePayment stores the payment only if it was not updated since it was loaded, returning
+	// ErrPaymentConflict otherwise, and increments its Version
+	UpdatePayment(ctx context.Context, payment *Payment) error
+	GetPaymentByID(ctx context.Context, paymentID string) (*Payment, error)
+	GetPaymentByTripID(ctx context.Context, tripID string) (*Payment, error)
+	GetPaymentByProviderPaymentID(ctx context.Context, providerPaymentID string) (*Payment, error)
+	// ListPayablePayments returns payments created before the cutoff whose earnings were not paid out yet
+	ListPayablePayments(ctx context.Context, createdBefore time.Time) ([]*Payment, error)
+	// ListCommissionDuePayments returns fares the driver collected before the cutoff whose commission was not debited yet
+	ListCommissionDuePayments(ctx context.Context, createdBefore time.Time) ([]*Payment, error)
+	// ClaimPayment links the payment to the payout only if it is not in a payout yet, reporting whether it did
+	ClaimPayment(ctx context.Context, paymentID, payoutID string) (bool, error)
+	// ListPaymentsByUser returns a page of the rider's payments, newest first
+	ListPaymentsByUser(ctx context.Context, userID string, page PaymentPage) ([]*Payment, error)
+	// ListPaymentsByDriver returns a page of the payments for the driver's trips, newest first
+	ListPaymentsByDriver(ctx context.Context, driverID string, page PaymentPage) ([]*Payment, error)
+}
+
+// PaymentPage selects a page of payments ordered newest first
+type PaymentPage struct {
+	Limit   int
+	AfterID string // ID of the last payment on the previous page; empty for the first page
+}
+
+// DriverAccountRepository is the port interface for driver payout account persistence
+// This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
+type DriverAccountRepository interface {
+	CreateDriverAccount(ctx context.Context, account *DriverAccount) error
+	UpdateDriverAccount(ctx context.Context, account *DriverAccount) error
+	GetDriverAccount(ctx context.Context, driverID string) (*DriverAccount, error)
+}
+
+// PayoutRepository is the port interface for the driver payout ledger
+// This is a secondary/driven port - implemented by infrastructure adapters (e.g., MongoDB)
+type PayoutRepository interface {
+	CreatePayout(ctx context.Context, payout *Payout) error
+	UpdatePayout(ctx context.Context, payout *Payout) error
+	// ListPayoutsByDriver returns the driver's payouts, newest first
+	ListPayoutsByDriver(ctx context.Context, driverID string, limit int) ([]*Payout, error)
+	// ListUnsettledPayouts returns pending and failed payouts that still need a transfer
+	ListUnsettledPayouts(ctx context.Context) ([]*Payout, error)
 }
@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// RetryQueue is a delay queue: messages wait in it for Delay and then return to the consumed queue
+type RetryQueue struct {
+	Name  string
+	Delay time.Duration
+}
+
+// DeadLetterTopology describes the exchanges and queues receiving a consumer's failed messages
+type DeadLetterTopology struct {
+	Queue              string // queue the consumer reads, where retried messages return
+	DeadLetterExchange string
+	DeadLetterQueue    string // durable queue bound to DeadLetterExchange for inspection and replay
+	RetryQueues        []RetryQueue
+}
+
+// DeclareDeadLetterTopology declares the dead-letter exchange and queue and the retry delay queues
+func DeclareDeadLetterTopology(ch *amqp091.Channel, topology DeadLetterTopology) error {
+	if err := ch.ExchangeDeclare(topology.DeadLetterExchange, amqp091.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", topology.DeadLetterExchange, err)
+	}
+	if _, err := ch.QueueDeclare(topology.DeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", topology.DeadLetterQueue, err)
+	}
+	if err := ch.QueueBind(topology.DeadLetterQueue, "", topology.DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", topology.DeadLetterQueue, err)
+	}
+
+	// One queue per attempt with a fixed TTL, so a long delay never holds back a shorter one
+	for _, retry := range topology.RetryQueues {
+		args := amqp091.Table{
+			"x-message-ttl":             retry.Delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": topology.Queue,
+		}
+		if _, err := ch.QueueDeclare(retry.Name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", retry.Name, err)
+		}
+	}
+	return nil
+}
+
+// AMQPDeliveryPublisher publishes raw messages on an AMQP channel in confirm mode
+type AMQPDeliveryPublisher struct {
+	ch *amqp091.Channel
+}
+
+// NewAMQPDeliveryPublisher puts the channel in confirm mode and creates a publisher on it
+func NewAMQPDeliveryPublisher(ch *amqp091.Channel) (*AMQPDeliveryPublisher, error) {
+	if err := ch.Confirm(false); err != nil {
+		return nil, fmt.Errorf("failed to put channel in confirm mode: %w", err)
+	}
+	return &AMQPDeliveryPublisher{ch: ch}, nil
+}
+
+// Publish sends msg to exchange with the given routing key and waits for the broker to confirm it.
+// Callers ack the original delivery only after Publish returns, so a message the broker did not
+// take is redelivered instead of lost.
+func (p *AMQPDeliveryPublisher) Publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
+	confirmation, err := p.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, msg)
+	if err != nil {
+		return err
+	}
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for publish confirmation: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message published to %q with routing key %q", exchange, routingKey)
+	}
+	return nil
+}
+
+// DeadLetterQueue reads a dead-letter queue and republishes its messages on an AMQP channel
+type DeadLetterQueue struct {
+	*AMQPDeliveryPublisher
+	queue string
+}
+
+// NewDeadLetterQueue creates a reader of the given dead-letter queue, republishing in confirm mode
+func NewDeadLetterQueue(ch *amqp091.Channel, queue string) (*DeadLetterQueue, error) {
+	publisher, err := NewAMQPDeliveryPublisher(ch)
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetterQueue{AMQPDeliveryPublisher: publisher, queue: queue}, nil
+}
+
+// Get takes the next message off the queue; it is redelivered unless acknowledged
+func (q *DeadLetterQueue) Get(ctx context.Context) (amqp091.Delivery, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return amqp091.Delivery{}, false, err
+	}
+	return q.ch.Get(q.queue, false)
+}
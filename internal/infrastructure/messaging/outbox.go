@@ -0,0 +1,139 @@
+package messaging
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ride4Low/contracts/events"
+)
+
+// OutboxMessage is a message persisted for later delivery to the broker
+type OutboxMessage struct {
+	ID            string
+	RoutingKey    string
+	Message       events.AmqpMessage
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DispatchedAt  *time.Time
+}
+
+// OutboxStore is the persistence port for the transactional outbox
+type OutboxStore interface {
+	AddOutboxMessage(ctx context.Context, message *OutboxMessage) error
+	FetchPendingOutboxMessages(ctx context.Context, limit int) ([]*OutboxMessage, error)
+	MarkOutboxMessageDispatched(ctx context.Context, id string) error
+	MarkOutboxMessageFailed(ctx context.Context, id string, lastError string, nextAttemptAt time.Time) error
+}
+
+// OutboxWriter implements MessagePublisher by writing messages to the outbox.
+// Called with a transactional context, the message commits atomically with the caller's other writes.
+type OutboxWriter struct {
+	store OutboxStore
+}
+
+// NewOutboxWriter creates a new outbox writer
+func NewOutboxWriter(store OutboxStore) *OutboxWriter {
+	return &OutboxWriter{store: store}
+}
+
+// PublishMessage stores the message in the outbox for the relay to deliver
+func (w *OutboxWriter) PublishMessage(ctx context.Context, routingKey string, message events.AmqpMessage) error {
+	now := time.Now().UTC()
+	return w.store.AddOutboxMessage(ctx, &OutboxMessage{
+		RoutingKey:    routingKey,
+		Message:       message,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
+
+// OutboxRelayConfig holds the polling and retry settings of the outbox relay
+type OutboxRelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// DefaultOutboxRelayConfig returns the default outbox relay settings
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		MinBackoff:   time.Second,
+		MaxBackoff:   5 * time.Minute,
+	}
+}
+
+// OutboxRelay drains the outbox to the broker, giving at-least-once delivery
+type OutboxRelay struct {
+	store     OutboxStore
+	publisher MessagePublisher
+	config    OutboxRelayConfig
+}
+
+// NewOutboxRelay creates a new outbox relay that publishes through the given publisher
+func NewOutboxRelay(store OutboxStore, publisher MessagePublisher, config OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Run polls the outbox until the context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending publishes one batch of due messages and returns how many were delivered.
+// It stops at the first broker failure so a broker outage does not burn through retries.
+func (r *OutboxRelay) DispatchPending(ctx context.Context) (int, error) {
+	messages, err := r.store.FetchPendingOutboxMessages(ctx, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := 0
+	for _, msg := range messages {
+		if err := r.publisher.PublishMessage(ctx, msg.RoutingKey, msg.Message); err != nil {
+			next := time.Now().UTC().Add(r.backoff(msg.Attempts + 1))
+			if markErr := r.store.MarkOutboxMessageFailed(ctx, msg.ID, err.Error(), next); markErr != nil {
+				return dispatched, markErr
+			}
+			return dispatched, err
+		}
+
+		if err := r.store.MarkOutboxMessageDispatched(ctx, msg.ID); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+// backoff returns the exponential delay before the given attempt, capped at MaxBackoff
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	delay := r.config.MinBackoff
+	for i := 1; i < attempt && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
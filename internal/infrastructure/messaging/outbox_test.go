@@ -0,0 +1,173 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/contracts/events"
+)
+
+// mockOutboxStore is an in-memory implementation of OutboxStore for testing
+type mockOutboxStore struct {
+	messages []*OutboxMessage
+}
+
+func (m *mockOutboxStore) AddOutboxMessage(ctx context.Context, message *OutboxMessage) error {
+	message.ID = fmt.Sprintf("msg-%d", len(m.messages)+1)
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *mockOutboxStore) FetchPendingOutboxMessages(ctx context.Context, limit int) ([]*OutboxMessage, error) {
+	var pending []*OutboxMessage
+	now := time.Now().UTC()
+	for _, msg := range m.messages {
+		if msg.DispatchedAt == nil && !msg.NextAttemptAt.After(now) && len(pending) < limit {
+			pending = append(pending, msg)
+		}
+	}
+	return pending, nil
+}
+
+func (m *mockOutboxStore) MarkOutboxMessageDispatched(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	m.find(id).DispatchedAt = &now
+	return nil
+}
+
+func (m *mockOutboxStore) MarkOutboxMessageFailed(ctx context.Context, id string, lastError string, nextAttemptAt time.Time) error {
+	msg := m.find(id)
+	msg.Attempts++
+	msg.LastError = lastError
+	msg.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (m *mockOutboxStore) find(id string) *OutboxMessage {
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return msg
+		}
+	}
+	return nil
+}
+
+func TestOutboxWriter_PublishMessage(t *testing.T) {
+	store := &mockOutboxStore{}
+	writer := NewOutboxWriter(store)
+
+	err := writer.PublishMessage(context.Background(), events.PaymentEventSessionCreated, events.AmqpMessage{OwnerID: "user-123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.messages) != 1 {
+		t.Fatalf("expected 1 outbox message, got %d", len(store.messages))
+	}
+
+	msg := store.messages[0]
+	if msg.RoutingKey != events.PaymentEventSessionCreated || msg.Message.OwnerID != "user-123" {
+		t.Errorf("unexpected outbox message: %+v", msg)
+	}
+
+	if msg.DispatchedAt != nil {
+		t.Error("expected message to be pending")
+	}
+}
+
+func TestOutboxRelay_DispatchPending_Success(t *testing.T) {
+	store := &mockOutboxStore{}
+	writer := NewOutboxWriter(store)
+	writer.PublishMessage(context.Background(), PaymentEventSucceeded, events.AmqpMessage{OwnerID: "user-1"})
+	writer.PublishMessage(context.Background(), PaymentEventFailed, events.AmqpMessage{OwnerID: "user-2"})
+
+	broker := &mockMessagePublisher{}
+	relay := NewOutboxRelay(store, broker, DefaultOutboxRelayConfig())
+
+	dispatched, err := relay.DispatchPending(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dispatched != 2 {
+		t.Errorf("expected 2 dispatched messages, got %d", dispatched)
+	}
+
+	for _, msg := range store.messages {
+		if msg.DispatchedAt == nil {
+			t.Errorf("expected message %s to be marked dispatched", msg.ID)
+		}
+	}
+
+	if broker.routingKey != PaymentEventFailed {
+		t.Errorf("expected messages to be published in order, last routing key was '%s'", broker.routingKey)
+	}
+}
+
+func TestOutboxRelay_DispatchPending_BrokerDown(t *testing.T) {
+	store := &mockOutboxStore{}
+	writer := NewOutboxWriter(store)
+	writer.PublishMessage(context.Background(), PaymentEventSucceeded, events.AmqpMessage{OwnerID: "user-1"})
+	writer.PublishMessage(context.Background(), PaymentEventFailed, events.AmqpMessage{OwnerID: "user-2"})
+
+	brokerErr := errors.New("connection refused")
+	broker := &mockMessagePublisher{err: brokerErr}
+	relay := NewOutboxRelay(store, broker, DefaultOutboxRelayConfig())
+
+	dispatched, err := relay.DispatchPending(context.Background())
+	if !errors.Is(err, brokerErr) {
+		t.Fatalf("expected error '%v', got '%v'", brokerErr, err)
+	}
+
+	if dispatched != 0 {
+		t.Errorf("expected 0 dispatched messages, got %d", dispatched)
+	}
+
+	first := store.messages[0]
+	if first.Attempts != 1 || first.LastError != brokerErr.Error() {
+		t.Errorf("expected first message to record the failed attempt, got %+v", first)
+	}
+
+	if !first.NextAttemptAt.After(time.Now().UTC()) {
+		t.Error("expected failed message to be scheduled for a later attempt")
+	}
+
+	if store.messages[1].Attempts != 0 {
+		t.Error("expected relay to stop at the first broker failure")
+	}
+
+	// Once the broker recovers the message is delivered on a later attempt
+	broker.err = nil
+	first.NextAttemptAt = time.Now().UTC()
+	if dispatched, err := relay.DispatchPending(context.Background()); err != nil || dispatched != 2 {
+		t.Fatalf("expected 2 dispatched messages after recovery, got %d (err: %v)", dispatched, err)
+	}
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := NewOutboxRelay(&mockOutboxStore{}, &mockMessagePublisher{}, OutboxRelayConfig{
+		MinBackoff: time.Second,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,21 @@ package messaging
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/ride4Low/contracts/events"
 	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// Routing keys for payment outcome events, published alongside events.PaymentEventSessionCreated
+const (
+	PaymentEventAuthorized = "payment.event.authorized"
+	PaymentEventSucceeded  = "payment.event.succeeded"
+	PaymentEventFailed     = "payment.event.failed"
+	PaymentEventExpired    = "payment.event.expired"
+	PaymentEventRefunded   = "payment.event.refunded"
+	PaymentEventCanceled   = "payment.event.canceled"
 )
 
 // MessagePublisher is the interface for publishing messages (allows mocking in tests)
@@ -23,20 +35,78 @@ func NewRabbitMQPublisher(publisher MessagePublisher) *RabbitMQPublisher {
 	return &RabbitMQPublisher{publisher: publisher}
 }
 
+// EventSchemaVersion is published as schemaVersion in every event payload.
+// Version 2 added amountMinor and amountDecimal, the exact amount as integer minor units and as a
+// decimal string in major units. The version 1 amount field is kept until consumers have migrated:
+// it is a float in major units for session_created and already in minor units for the other events.
+const EventSchemaVersion = 2
+
 // PublishPaymentSessionCreated publishes a payment session created event
 func (p *RabbitMQPublisher) PublishPaymentSessionCreated(ctx context.Context, event *application.PaymentSessionCreatedEvent) error {
+	amount := domain.Money{Amount: event.AmountMinor, Currency: event.Currency}
+	return p.publish(ctx, events.PaymentEventSessionCreated, event.UserID, amount, event.PaymentEventSessionCreatedData)
+}
+
+// PublishPaymentSucceeded publishes a payment succeeded event
+func (p *RabbitMQPublisher) PublishPaymentSucceeded(ctx context.Context, event *application.PaymentSucceededEvent) error {
+	return p.publish(ctx, PaymentEventSucceeded, event.UserID, event.Money(), event)
+}
+
+// PublishPaymentFailed publishes a payment failed event
+func (p *RabbitMQPublisher) PublishPaymentFailed(ctx context.Context, event *application.PaymentFailedEvent) error {
+	return p.publish(ctx, PaymentEventFailed, event.UserID, event.Money(), event)
+}
+
+// PublishPaymentExpired publishes a payment expired event
+func (p *RabbitMQPublisher) PublishPaymentExpired(ctx context.Context, event *application.PaymentExpiredEvent) error {
+	return p.publish(ctx, PaymentEventExpired, event.UserID, event.Money(), event.PaymentEventData)
+}
+
+// PublishPaymentRefunded publishes a payment refunded event
+func (p *RabbitMQPublisher) PublishPaymentRefunded(ctx context.Context, event *application.PaymentRefundedEvent) error {
+	return p.publish(ctx, PaymentEventRefunded, event.UserID, event.Money(), event)
+}
+
+// PublishPaymentAuthorized publishes a payment authorized event
+func (p *RabbitMQPublisher) PublishPaymentAuthorized(ctx context.Context, event *application.PaymentAuthorizedEvent) error {
+	return p.publish(ctx, PaymentEventAuthorized, event.UserID, event.Money(), event.PaymentEventData)
+}
 
-	payloadBytes, err := json.Marshal(event.PaymentEventSessionCreatedData)
+// PublishPaymentCanceled publishes a payment canceled event
+func (p *RabbitMQPublisher) PublishPaymentCanceled(ctx context.Context, event *application.PaymentCanceledEvent) error {
+	return p.publish(ctx, PaymentEventCanceled, event.UserID, event.Money(), event.PaymentEventData)
+}
+
+// publish marshals the versioned payload and sends it to the given routing key, owned by ownerID
+func (p *RabbitMQPublisher) publish(ctx context.Context, routingKey, ownerID string, amount domain.Money, payload any) error {
+	payloadBytes, err := versionedPayload(amount, payload)
 	if err != nil {
 		return err
 	}
 
 	return p.publisher.PublishMessage(
 		ctx,
-		events.PaymentEventSessionCreated,
+		routingKey,
 		events.AmqpMessage{
-			OwnerID: event.UserID,
+			OwnerID: ownerID,
 			Data:    payloadBytes,
 		},
 	)
 }
+
+// versionedPayload adds the schema version and the exact amount to the payload's JSON object
+func versionedPayload(amount domain.Money, payload any) ([]byte, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(payloadBytes, &fields); err != nil {
+		return nil, fmt.Errorf("event payload must be a JSON object: %w", err)
+	}
+	fields["schemaVersion"] = EventSchemaVersion
+	fields["amountMinor"] = amount.Amount
+	fields["amountDecimal"] = amount.Decimal()
+	return json.Marshal(fields)
+}
@@ -2,6 +2,7 @@ package messaging
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -71,6 +72,59 @@ func TestRabbitMQPublisher_PublishPaymentSessionCreated_Success(t *testing.T) {
 	}
 }
 
+func TestRabbitMQPublisher_PublishPaymentSessionCreated_ExactAmount(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		amountMinor int64
+		currency    string
+		wantDecimal string
+	}{
+		{name: "two decimals", amount: 10.5, amountMinor: 1050, currency: "USD", wantDecimal: "10.50"},
+		{name: "zero decimals", amount: 1200, amountMinor: 1200, currency: "JPY", wantDecimal: "1200"},
+		{name: "three decimals", amount: 1.234, amountMinor: 1234, currency: "KWD", wantDecimal: "1.234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPub := &mockMessagePublisher{}
+			publisher := NewRabbitMQPublisher(mockPub)
+
+			err := publisher.PublishPaymentSessionCreated(context.Background(), &application.PaymentSessionCreatedEvent{
+				UserID: "user-123",
+				PaymentEventSessionCreatedData: events.PaymentEventSessionCreatedData{
+					TripID:    "trip-456",
+					SessionID: "cs_session_789",
+					Amount:    tt.amount,
+					Currency:  tt.currency,
+				},
+				AmountMinor: tt.amountMinor,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var payload map[string]any
+			if err := json.Unmarshal(mockPub.message.Data, &payload); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+
+			if payload["schemaVersion"] != float64(EventSchemaVersion) {
+				t.Errorf("expected schemaVersion %d, got %v", EventSchemaVersion, payload["schemaVersion"])
+			}
+			if payload["amountMinor"] != float64(tt.amountMinor) {
+				t.Errorf("expected amountMinor %d, got %v", tt.amountMinor, payload["amountMinor"])
+			}
+			if payload["amountDecimal"] != tt.wantDecimal {
+				t.Errorf("expected amountDecimal %s, got %v", tt.wantDecimal, payload["amountDecimal"])
+			}
+			if _, ok := payload["AmountMinor"]; ok {
+				t.Error("expected AmountMinor to be published only as amountMinor")
+			}
+		})
+	}
+}
+
 func TestRabbitMQPublisher_PublishPaymentSessionCreated_Error(t *testing.T) {
 	publishErr := errors.New("connection failed")
 	mockPub := &mockMessagePublisher{err: publishErr}
@@ -96,3 +150,141 @@ func TestRabbitMQPublisher_PublishPaymentSessionCreated_Error(t *testing.T) {
 		t.Errorf("expected error '%v', got '%v'", publishErr, err)
 	}
 }
+
+func TestRabbitMQPublisher_PublishPaymentOutcomeEvents(t *testing.T) {
+	data := application.PaymentEventData{
+		PaymentID: "payment-1",
+		TripID:    "trip-456",
+		DriverID:  "driver-789",
+		Amount:    1050,
+		Currency:  "usd",
+	}
+
+	tests := []struct {
+		name       string
+		routingKey string
+		publish    func(p *RabbitMQPublisher) error
+	}{
+		{
+			name:       "succeeded",
+			routingKey: PaymentEventSucceeded,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentSucceeded(context.Background(), &application.PaymentSucceededEvent{UserID: "user-123", PaymentEventData: data})
+			},
+		},
+		{
+			name:       "failed",
+			routingKey: PaymentEventFailed,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentFailed(context.Background(), &application.PaymentFailedEvent{UserID: "user-123", PaymentEventData: data, Reason: "card declined"})
+			},
+		},
+		{
+			name:       "expired",
+			routingKey: PaymentEventExpired,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentExpired(context.Background(), &application.PaymentExpiredEvent{UserID: "user-123", PaymentEventData: data})
+			},
+		},
+		{
+			name:       "refunded",
+			routingKey: PaymentEventRefunded,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentRefunded(context.Background(), &application.PaymentRefundedEvent{UserID: "user-123", PaymentEventData: data, RefundedAmount: 500})
+			},
+		},
+		{
+			name:       "authorized",
+			routingKey: PaymentEventAuthorized,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentAuthorized(context.Background(), &application.PaymentAuthorizedEvent{UserID: "user-123", PaymentEventData: data})
+			},
+		},
+		{
+			name:       "canceled",
+			routingKey: PaymentEventCanceled,
+			publish: func(p *RabbitMQPublisher) error {
+				return p.PublishPaymentCanceled(context.Background(), &application.PaymentCanceledEvent{UserID: "user-123", PaymentEventData: data})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPub := &mockMessagePublisher{}
+			publisher := NewRabbitMQPublisher(mockPub)
+
+			if err := tt.publish(publisher); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if mockPub.routingKey != tt.routingKey {
+				t.Errorf("expected routing key '%s', got '%s'", tt.routingKey, mockPub.routingKey)
+			}
+
+			if mockPub.message.OwnerID != "user-123" {
+				t.Errorf("expected OwnerID 'user-123', got '%s'", mockPub.message.OwnerID)
+			}
+
+			var payload map[string]any
+			if err := json.Unmarshal(mockPub.message.Data, &payload); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+
+			if payload["paymentID"] != "payment-1" || payload["tripID"] != "trip-456" {
+				t.Errorf("unexpected payload: %v", payload)
+			}
+
+			if payload["amount"] != float64(1050) {
+				t.Errorf("expected amount 1050, got %v", payload["amount"])
+			}
+
+			if payload["schemaVersion"] != float64(EventSchemaVersion) {
+				t.Errorf("expected schemaVersion %d, got %v", EventSchemaVersion, payload["schemaVersion"])
+			}
+
+			if payload["amountMinor"] != float64(1050) || payload["amountDecimal"] != "10.50" {
+				t.Errorf("expected exact amount 1050 / 10.50, got %v / %v", payload["amountMinor"], payload["amountDecimal"])
+			}
+
+			if _, ok := payload["UserID"]; ok {
+				t.Error("expected UserID to be carried by OwnerID only")
+			}
+		})
+	}
+}
+
+func TestRabbitMQPublisher_PublishPaymentFailed_Reason(t *testing.T) {
+	mockPub := &mockMessagePublisher{}
+	publisher := NewRabbitMQPublisher(mockPub)
+
+	err := publisher.PublishPaymentFailed(context.Background(), &application.PaymentFailedEvent{
+		UserID:           "user-123",
+		PaymentEventData: application.PaymentEventData{PaymentID: "payment-1"},
+		Reason:           "card declined",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var payload application.PaymentFailedEvent
+	if err := json.Unmarshal(mockPub.message.Data, &payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+
+	if payload.Reason != "card declined" {
+		t.Errorf("expected reason 'card declined', got '%s'", payload.Reason)
+	}
+}
+
+func TestRabbitMQPublisher_PublishPaymentSucceeded_Error(t *testing.T) {
+	publishErr := errors.New("connection failed")
+	mockPub := &mockMessagePublisher{err: publishErr}
+	publisher := NewRabbitMQPublisher(mockPub)
+
+	err := publisher.PublishPaymentSucceeded(context.Background(), &application.PaymentSucceededEvent{UserID: "user-123"})
+
+	if !errors.Is(err, publishErr) {
+		t.Errorf("expected error '%v', got '%v'", publishErr, err)
+	}
+}
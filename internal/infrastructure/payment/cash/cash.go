@@ -0,0 +1,59 @@
+package cash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ProviderName identifies cash as the provider of a payment
+const ProviderName = "cash"
+
+// Provider implements application.PaymentProvider for fares the rider pays to the driver in cash.
+// No money moves through the platform: the session only references the payment, and the driver
+// confirms collection once the trip ends.
+type Provider struct{}
+
+// NewProvider creates a new cash payment provider
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Name returns the provider name stored on payments
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// CreatePaymentSession returns a reference for the cash payment; nothing is charged
+func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	paymentID := metadata["payment_id"]
+	if paymentID == "" {
+		return "", fmt.Errorf("cash payment session requires a payment_id")
+	}
+	return "cash_" + paymentID, nil
+}
+
+// CreateAuthorizationSession is not supported: cash cannot be held
+func (p *Provider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return "", fmt.Errorf("cash authorization holds: %w", errors.ErrUnsupported)
+}
+
+// CapturePayment is not supported: cash cannot be held
+func (p *Provider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	return fmt.Errorf("cash captures: %w", errors.ErrUnsupported)
+}
+
+// CancelPayment is not supported: cash cannot be held
+func (p *Provider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	return fmt.Errorf("cash cancellations: %w", errors.ErrUnsupported)
+}
+
+// ExpirePaymentSession has nothing to expire: a cash session is only a reference
+func (p *Provider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	return nil
+}
+
+// RefundPayment is not supported: cash refunds are settled by support outside the platform
+func (p *Provider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	return "", fmt.Errorf("cash refunds: %w", errors.ErrUnsupported)
+}
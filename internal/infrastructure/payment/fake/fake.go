@@ -0,0 +1,54 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// ProviderName identifies the fake provider as the provider of a payment
+const ProviderName = "fake"
+
+// Provider implements application.PaymentProvider without calling any external service.
+// Every operation succeeds, which makes it suitable for local development and end-to-end tests.
+type Provider struct {
+	seq atomic.Int64
+}
+
+// NewProvider creates a new fake payment provider
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Name returns the provider name stored on payments
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return p.nextID("fake_cs"), nil
+}
+
+func (p *Provider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return p.nextID("fake_cs"), nil
+}
+
+func (p *Provider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	return nil
+}
+
+func (p *Provider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	return nil
+}
+
+func (p *Provider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	return nil
+}
+
+func (p *Provider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	return p.nextID("fake_re"), nil
+}
+
+func (p *Provider) nextID(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, p.seq.Add(1))
+}
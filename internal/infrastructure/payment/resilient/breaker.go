@@ -0,0 +1,110 @@
+package resilient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its circuit breaker is open
+var ErrCircuitOpen = errors.New("payment provider circuit breaker is open")
+
+// State is the state of a circuit breaker, exported as the breaker metric value
+type State int64
+
+const (
+	StateClosed   State = 0 // calls flow to the provider
+	StateOpen     State = 1 // calls fail fast until the open timeout elapses
+	StateHalfOpen State = 2 // a single probe call decides whether to close or reopen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+// outcome is how a call through the breaker affects the provider's health
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // the provider answered, even if it rejected the request
+	outcomeFailure                // the provider was unavailable
+	outcomeIgnored                // the caller gave up, which says nothing about the provider
+)
+
+// breaker opens after threshold consecutive provider failures
+type breaker struct {
+	mu          sync.Mutex
+	state       State
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+}
+
+func newBreaker(threshold int, openTimeout time.Duration) *breaker {
+	return &breaker{threshold: threshold, openTimeout: openTimeout, now: time.Now}
+}
+
+// State returns the current state, moving an expired open breaker to half-open
+func (b *breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.state
+}
+
+// allow reserves a call, or returns ErrCircuitOpen when the provider must not be called
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+
+	switch b.state {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record reports the outcome of a call reserved with allow
+func (b *breaker) record(o outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	halfOpen := b.state == StateHalfOpen
+	b.probing = false
+
+	switch o {
+	case outcomeSuccess:
+		b.state = StateClosed
+		b.failures = 0
+	case outcomeFailure:
+		b.failures++
+		if halfOpen || b.failures >= b.threshold {
+			b.state = StateOpen
+			b.openedAt = b.now()
+		}
+	}
+}
+
+func (b *breaker) expire() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+}
@@ -0,0 +1,227 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/ride4Low/payment-service/internal/infrastructure/payment/resilient"
+
+// Config tunes retries and the circuit breaker around a payment provider
+type Config struct {
+	MaxAttempts      int           // calls per operation, including the first
+	BaseDelay        time.Duration // backoff before the first retry, doubled for each further retry
+	MaxDelay         time.Duration // upper bound of a single backoff
+	FailureThreshold int           // consecutive provider failures that open the breaker
+	OpenTimeout      time.Duration // how long the breaker stays open before probing the provider
+	Retryable        func(error) bool
+}
+
+// DefaultConfig returns the retry and breaker settings used in production with the provider's error classifier
+func DefaultConfig(retryable func(error) bool) Config {
+	return Config{
+		MaxAttempts:      3,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         2 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		Retryable:        retryable,
+	}
+}
+
+// Provider decorates an application.PaymentProvider with retries and a circuit breaker.
+// Only errors classified as retryable are retried and count against the provider's health;
+// every call carries the caller's idempotency key, so repeating it cannot charge twice.
+type Provider struct {
+	next    application.PaymentProvider
+	config  Config
+	breaker *breaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewProvider wraps next and exports its breaker state as a gauge
+func NewProvider(next application.PaymentProvider, config Config) *Provider {
+	p := &Provider{
+		next:    next,
+		config:  config,
+		breaker: newBreaker(config.FailureThreshold, config.OpenTimeout),
+		sleep:   sleep,
+	}
+	if err := p.registerMetrics(otel.Meter(meterName)); err != nil {
+		log.Printf("failed to register circuit breaker metric for %s: %v", next.Name(), err)
+	}
+	return p
+}
+
+// Name returns the wrapped provider's name, so payments keep referring to it
+func (p *Provider) Name() string {
+	return p.next.Name()
+}
+
+// State returns the provider's circuit breaker state
+func (p *Provider) State() State {
+	return p.breaker.State()
+}
+
+func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	var sessionID string
+	err := p.do(ctx, "create payment session", func(ctx context.Context) (err error) {
+		sessionID, err = p.next.CreatePaymentSession(ctx, amount, currency, metadata, idempotencyKey)
+		return err
+	})
+	return sessionID, err
+}
+
+func (p *Provider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	var sessionID string
+	err := p.do(ctx, "create authorization session", func(ctx context.Context) (err error) {
+		sessionID, err = p.next.CreateAuthorizationSession(ctx, amount, currency, metadata, idempotencyKey)
+		return err
+	})
+	return sessionID, err
+}
+
+func (p *Provider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	return p.do(ctx, "capture payment", func(ctx context.Context) error {
+		return p.next.CapturePayment(ctx, providerPaymentID, amount, idempotencyKey)
+	})
+}
+
+func (p *Provider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	return p.do(ctx, "cancel payment", func(ctx context.Context) error {
+		return p.next.CancelPayment(ctx, providerPaymentID, idempotencyKey)
+	})
+}
+
+func (p *Provider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	return p.do(ctx, "expire payment session", func(ctx context.Context) error {
+		return p.next.ExpirePaymentSession(ctx, sessionID, idempotencyKey)
+	})
+}
+
+func (p *Provider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	var refundID string
+	err := p.do(ctx, "refund payment", func(ctx context.Context) (err error) {
+		refundID, err = p.next.RefundPayment(ctx, providerPaymentID, amount, reason, idempotencyKey)
+		return err
+	})
+	return refundID, err
+}
+
+// CryptoProvider decorates an application.CryptoPaymentProvider, keeping it usable for crypto payments
+type CryptoProvider struct {
+	*Provider
+	next application.CryptoPaymentProvider
+}
+
+// NewCryptoProvider wraps next and exports its breaker state as a gauge
+func NewCryptoProvider(next application.CryptoPaymentProvider, config Config) *CryptoProvider {
+	return &CryptoProvider{Provider: NewProvider(next, config), next: next}
+}
+
+// SettlePayment is retried only for errors the classifier reports the provider cannot have acted on:
+// a settlement whose outcome is unknown is returned to the caller to be looked up with FindSettlement
+func (p *CryptoProvider) SettlePayment(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	var settlement *application.CryptoSettlement
+	err := p.do(ctx, "settle payment", func(ctx context.Context) (err error) {
+		settlement, err = p.next.SettlePayment(ctx, sessionID, paymentPayload)
+		return err
+	})
+	return settlement, err
+}
+
+func (p *CryptoProvider) FindSettlement(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	var settlement *application.CryptoSettlement
+	err := p.do(ctx, "find settlement", func(ctx context.Context) (err error) {
+		settlement, err = p.next.FindSettlement(ctx, sessionID, paymentPayload)
+		return err
+	})
+	return settlement, err
+}
+
+// do calls fn until it succeeds, fails terminally or runs out of attempts, backing off between calls.
+// An open circuit and exhausted retries are reported as domain.ErrProviderUnavailable.
+func (p *Provider) do(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		if err := p.breaker.allow(); err != nil {
+			return p.unavailable(op, err)
+		}
+
+		err := fn(ctx)
+		retryable := err != nil && p.config.Retryable != nil && p.config.Retryable(err)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			p.breaker.record(outcomeIgnored)
+			return err
+		case retryable:
+			p.breaker.record(outcomeFailure)
+		default:
+			p.breaker.record(outcomeSuccess)
+			return err
+		}
+
+		if attempt >= p.config.MaxAttempts {
+			return p.unavailable(op, err)
+		}
+		delay := p.backoff(attempt)
+		log.Printf("%s %s failed (attempt %d/%d), retrying in %s: %v", p.next.Name(), op, attempt, p.config.MaxAttempts, delay, err)
+		if sleepErr := p.sleep(ctx, delay); sleepErr != nil {
+			return errors.Join(err, sleepErr)
+		}
+	}
+}
+
+func (p *Provider) unavailable(op string, cause error) error {
+	return &domain.ProviderError{Provider: p.next.Name(), Op: op, Err: domain.ErrProviderUnavailable, Cause: cause}
+}
+
+// backoff returns the delay before retry number attempt: exponential, capped, with equal jitter
+// so that consumers retrying the same outage spread out
+func (p *Provider) backoff(attempt int) time.Duration {
+	delay := p.config.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.config.MaxDelay {
+		delay = p.config.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
+
+func (p *Provider) registerMetrics(meter metric.Meter) error {
+	gauge, err := meter.Int64ObservableGauge(
+		"payment.provider.circuit_breaker.state",
+		metric.WithDescription("Circuit breaker state per payment provider: 0 closed, 1 open, 2 half-open"),
+	)
+	if err != nil {
+		return err
+	}
+
+	provider := metric.WithAttributes(attribute.String("provider", p.next.Name()))
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		observer.ObserveInt64(gauge, int64(p.breaker.State()), provider)
+		return nil
+	}, gauge)
+	return err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
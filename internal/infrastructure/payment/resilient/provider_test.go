@@ -0,0 +1,242 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+var (
+	errTransient = errors.New("provider unavailable")
+	errDeclined  = errors.New("card declined")
+)
+
+// mockPaymentProvider fails with the queued errors before succeeding
+type mockPaymentProvider struct {
+	errs  []error
+	calls int
+	keys  []string
+}
+
+func (m *mockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (m *mockPaymentProvider) result(key string) error {
+	m.calls++
+	m.keys = append(m.keys, key)
+	if len(m.errs) == 0 {
+		return nil
+	}
+	err := m.errs[0]
+	m.errs = m.errs[1:]
+	return err
+}
+
+func (m *mockPaymentProvider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	if err := m.result(idempotencyKey); err != nil {
+		return "", err
+	}
+	return "cs_test", nil
+}
+
+func (m *mockPaymentProvider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return "cs_test", m.result(idempotencyKey)
+}
+
+func (m *mockPaymentProvider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	return m.result(idempotencyKey)
+}
+
+func (m *mockPaymentProvider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	return m.result(idempotencyKey)
+}
+
+func (m *mockPaymentProvider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	return m.result(idempotencyKey)
+}
+
+func (m *mockPaymentProvider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	return "re_test", m.result(idempotencyKey)
+}
+
+type mockCryptoProvider struct {
+	mockPaymentProvider
+}
+
+func (m *mockCryptoProvider) SettlePayment(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	if err := m.result(sessionID); err != nil {
+		return nil, err
+	}
+	return &application.CryptoSettlement{TransactionHash: "0xtx"}, nil
+}
+
+func (m *mockCryptoProvider) FindSettlement(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	if err := m.result(sessionID); err != nil {
+		return nil, err
+	}
+	return &application.CryptoSettlement{TransactionHash: "0xtx"}, nil
+}
+
+func testConfig() Config {
+	return Config{
+		MaxAttempts:      3,
+		BaseDelay:        10 * time.Millisecond,
+		MaxDelay:         40 * time.Millisecond,
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		Retryable:        func(err error) bool { return errors.Is(err, errTransient) },
+	}
+}
+
+func newTestProvider(next application.PaymentProvider) (*Provider, *[]time.Duration) {
+	p := NewProvider(next, testConfig())
+	var delays []time.Duration
+	p.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return p, &delays
+}
+
+func TestProvider_RetriesTransientErrors(t *testing.T) {
+	next := &mockPaymentProvider{errs: []error{errTransient, errTransient}}
+	p, delays := newTestProvider(next)
+	p.breaker.threshold = 5
+
+	sessionID, err := p.CreatePaymentSession(context.Background(), 1850, "USD", nil, "session-trip-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sessionID != "cs_test" || next.calls != 3 {
+		t.Errorf("expected success on third call, got %q after %d calls", sessionID, next.calls)
+	}
+	for _, key := range next.keys {
+		if key != "session-trip-1" {
+			t.Errorf("expected every attempt to reuse the idempotency key, got %s", key)
+		}
+	}
+
+	// Equal jitter keeps each delay within [d/2, d] of the exponential schedule
+	if len(*delays) != 2 {
+		t.Fatalf("expected 2 backoffs, got %d", len(*delays))
+	}
+	for i, max := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond} {
+		if d := (*delays)[i]; d < max/2 || d > max {
+			t.Errorf("backoff %d: expected within [%s, %s], got %s", i, max/2, max, d)
+		}
+	}
+}
+
+func TestProvider_TerminalErrorNotRetried(t *testing.T) {
+	next := &mockPaymentProvider{errs: []error{errDeclined}}
+	p, _ := newTestProvider(next)
+
+	err := p.CapturePayment(context.Background(), "pi_1", 1850, "capture-1")
+	if !errors.Is(err, errDeclined) || errors.Is(err, domain.ErrProviderUnavailable) {
+		t.Errorf("expected decline as returned by the provider, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected 1 call, got %d", next.calls)
+	}
+	if p.State() != StateClosed {
+		t.Errorf("expected terminal errors to leave the breaker closed, got %s", p.State())
+	}
+}
+
+func TestProvider_GivesUpAfterMaxAttempts(t *testing.T) {
+	next := &mockPaymentProvider{errs: []error{errTransient, errTransient, errTransient, errTransient}}
+	p, _ := newTestProvider(next)
+	p.breaker.threshold = 5
+
+	_, err := p.RefundPayment(context.Background(), "pi_1", 500, "", "refund-1")
+	if !errors.Is(err, errTransient) || !errors.Is(err, domain.ErrProviderUnavailable) {
+		t.Errorf("expected transient error reported as provider unavailable, got %v", err)
+	}
+	if next.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", next.calls)
+	}
+}
+
+func TestProvider_CircuitBreaker(t *testing.T) {
+	next := &mockPaymentProvider{errs: []error{errTransient, errTransient, errTransient}}
+	p, _ := newTestProvider(next)
+	now := time.Now()
+	p.breaker.now = func() time.Time { return now }
+
+	// Two consecutive failures open the breaker and stop the retries
+	if err := p.CancelPayment(context.Background(), "pi_1", "cancel-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if next.calls != 2 || p.State() != StateOpen {
+		t.Fatalf("expected open breaker after 2 calls, got %s after %d", p.State(), next.calls)
+	}
+
+	// Calls fail fast while open
+	if err := p.CancelPayment(context.Background(), "pi_1", "cancel-1"); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, domain.ErrProviderUnavailable) {
+		t.Errorf("expected ErrCircuitOpen reported as provider unavailable, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("expected no call while open, got %d", next.calls)
+	}
+
+	// A failed probe reopens the breaker
+	now = now.Add(time.Minute)
+	if p.State() != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s", p.State())
+	}
+	if err := p.CancelPayment(context.Background(), "pi_1", "cancel-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if next.calls != 3 || p.State() != StateOpen {
+		t.Errorf("expected reopened breaker after probe, got %s after %d", p.State(), next.calls)
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	if err := p.CancelPayment(context.Background(), "pi_1", "cancel-1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if p.State() != StateClosed {
+		t.Errorf("expected closed breaker, got %s", p.State())
+	}
+}
+
+func TestProvider_StopsOnCanceledContext(t *testing.T) {
+	next := &mockPaymentProvider{errs: []error{errTransient}}
+	p := NewProvider(next, testConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.CreateAuthorizationSession(ctx, 1850, "USD", nil, "auth-1")
+	if !errors.Is(err, errTransient) {
+		t.Errorf("expected provider error, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected 1 call, got %d", next.calls)
+	}
+}
+
+func TestCryptoProvider_SettlePayment(t *testing.T) {
+	next := &mockCryptoProvider{mockPaymentProvider{errs: []error{errTransient}}}
+	p := NewCryptoProvider(next, testConfig())
+	p.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	var provider application.PaymentProvider = p
+	crypto, ok := provider.(application.CryptoPaymentProvider)
+	if !ok {
+		t.Fatal("expected the decorated provider to remain a crypto provider")
+	}
+
+	settlement, err := crypto.SettlePayment(context.Background(), "requirements", "payload")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settlement.TransactionHash != "0xtx" || next.calls != 2 {
+		t.Errorf("expected settlement after retry, got %+v after %d calls", settlement, next.calls)
+	}
+}
@@ -0,0 +1,143 @@
+package stripe
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/account"
+	"github.com/stripe/stripe-go/v81/accountlink"
+	"github.com/stripe/stripe-go/v81/transfer"
+)
+
+type ConnectConfig struct {
+	StripeSecretKey      string `json:"stripeSecretKey"`
+	OnboardingRefreshURL string `json:"onboardingRefreshURL"`
+	OnboardingReturnURL  string `json:"onboardingReturnURL"`
+}
+
+// AccountCreator defines a function that creates a connected account
+type AccountCreator func(params *stripe.AccountParams) (*stripe.Account, error)
+
+// AccountGetter defines a function that retrieves a connected account
+type AccountGetter func(id string, params *stripe.AccountParams) (*stripe.Account, error)
+
+// AccountLinkCreator defines a function that creates an account onboarding link
+type AccountLinkCreator func(params *stripe.AccountLinkParams) (*stripe.AccountLink, error)
+
+// TransferCreator defines a function that creates a transfer to a connected account
+type TransferCreator func(params *stripe.TransferParams) (*stripe.Transfer, error)
+
+// TransferFinder defines a function that returns the first transfer matching the list parameters, or nil
+type TransferFinder func(params *stripe.TransferListParams) (*stripe.Transfer, error)
+
+// PayoutProvider implements application.PayoutProvider with Stripe Connect Express accounts.
+// Riders are charged on the platform account and drivers are paid with separate transfers.
+type PayoutProvider struct {
+	config            ConnectConfig
+	createAccount     AccountCreator
+	getAccount        AccountGetter
+	createAccountLink AccountLinkCreator
+	createTransfer    TransferCreator
+	findTransfer      TransferFinder
+}
+
+// NewPayoutProvider creates a new Stripe Connect payout provider
+func NewPayoutProvider(config ConnectConfig) *PayoutProvider {
+	stripe.Key = config.StripeSecretKey
+	return &PayoutProvider{
+		config:            config,
+		createAccount:     account.New,
+		getAccount:        account.GetByID,
+		createAccountLink: accountlink.New,
+		createTransfer:    transfer.New,
+		findTransfer:      firstTransfer,
+	}
+}
+
+// CreateConnectedAccount creates an Express account able to receive transfers and returns its ID
+func (p *PayoutProvider) CreateConnectedAccount(ctx context.Context, driverID string, idempotencyKey string) (string, error) {
+	params := &stripe.AccountParams{
+		Type: stripe.String(string(stripe.AccountTypeExpress)),
+		Capabilities: &stripe.AccountCapabilitiesParams{
+			Transfers: &stripe.AccountCapabilitiesTransfersParams{Requested: stripe.Bool(true)},
+		},
+	}
+	params.AddMetadata("driver_id", driverID)
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	result, err := p.createAccount(params)
+	if err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// CreateOnboardingLink returns a single-use URL where the driver completes the account onboarding
+func (p *PayoutProvider) CreateOnboardingLink(ctx context.Context, accountID string) (string, error) {
+	result, err := p.createAccountLink(&stripe.AccountLinkParams{
+		Account:    stripe.String(accountID),
+		RefreshURL: stripe.String(p.config.OnboardingRefreshURL),
+		ReturnURL:  stripe.String(p.config.OnboardingReturnURL),
+		Type:       stripe.String(string(stripe.AccountLinkTypeAccountOnboarding)),
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// PayoutsEnabled reports whether the connected account finished onboarding and can be paid out
+func (p *PayoutProvider) PayoutsEnabled(ctx context.Context, accountID string) (bool, error) {
+	result, err := p.getAccount(accountID, nil)
+	if err != nil {
+		return false, err
+	}
+	return result.PayoutsEnabled, nil
+}
+
+// CreateTransfer moves amount from the platform balance to the connected account and returns the transfer ID
+func (p *PayoutProvider) CreateTransfer(ctx context.Context, accountID string, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	params := &stripe.TransferParams{
+		Amount:      stripe.Int64(amount),
+		Currency:    stripe.String(strings.ToLower(currency)),
+		Destination: stripe.String(accountID),
+		Metadata:    metadata,
+	}
+	if payoutID := metadata["payout_id"]; payoutID != "" {
+		params.TransferGroup = stripe.String(payoutID)
+	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	result, err := p.createTransfer(params)
+	if err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// FindTransfer returns the transfer sent for the payout, found by the transfer group CreateTransfer
+// sets to the payout ID, or an empty ID when there is none
+func (p *PayoutProvider) FindTransfer(ctx context.Context, payoutID string) (string, error) {
+	params := &stripe.TransferListParams{TransferGroup: stripe.String(payoutID)}
+	params.Context = ctx
+	params.Limit = stripe.Int64(1)
+
+	result, err := p.findTransfer(params)
+	if err != nil || result == nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+func firstTransfer(params *stripe.TransferListParams) (*stripe.Transfer, error) {
+	iter := transfer.List(params)
+	if iter.Next() {
+		return iter.Transfer(), nil
+	}
+	return nil, iter.Err()
+}
@@ -0,0 +1,144 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stripe/stripe-go/v81"
+)
+
+func TestPayoutProvider_CreateConnectedAccount(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{StripeSecretKey: "sk_test_123"})
+	provider.createAccount = func(params *stripe.AccountParams) (*stripe.Account, error) {
+		if *params.Type != string(stripe.AccountTypeExpress) {
+			t.Errorf("expected express account, got %s", *params.Type)
+		}
+		if !*params.Capabilities.Transfers.Requested {
+			t.Error("expected transfers capability to be requested")
+		}
+		if params.Metadata["driver_id"] != "driver-1" {
+			t.Errorf("expected driver ID metadata, got %v", params.Metadata)
+		}
+		if *params.IdempotencyKey != "driver-account-driver-1" {
+			t.Errorf("unexpected idempotency key %s", *params.IdempotencyKey)
+		}
+		return &stripe.Account{ID: "acct_1"}, nil
+	}
+
+	accountID, err := provider.CreateConnectedAccount(context.Background(), "driver-1", "driver-account-driver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accountID != "acct_1" {
+		t.Errorf("expected acct_1, got %s", accountID)
+	}
+}
+
+func TestPayoutProvider_CreateOnboardingLink(t *testing.T) {
+	config := ConnectConfig{
+		OnboardingRefreshURL: "http://localhost:3000/payouts/refresh",
+		OnboardingReturnURL:  "http://localhost:3000/payouts/done",
+	}
+	provider := NewPayoutProvider(config)
+	provider.createAccountLink = func(params *stripe.AccountLinkParams) (*stripe.AccountLink, error) {
+		if *params.Account != "acct_1" || *params.Type != string(stripe.AccountLinkTypeAccountOnboarding) {
+			t.Errorf("unexpected account link params: %s %s", *params.Account, *params.Type)
+		}
+		if *params.RefreshURL != config.OnboardingRefreshURL || *params.ReturnURL != config.OnboardingReturnURL {
+			t.Errorf("unexpected onboarding URLs: %s %s", *params.RefreshURL, *params.ReturnURL)
+		}
+		return &stripe.AccountLink{URL: "https://connect.stripe.com/setup/e/acct_1"}, nil
+	}
+
+	url, err := provider.CreateOnboardingLink(context.Background(), "acct_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url != "https://connect.stripe.com/setup/e/acct_1" {
+		t.Errorf("unexpected URL %s", url)
+	}
+}
+
+func TestPayoutProvider_PayoutsEnabled(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{})
+	provider.getAccount = func(id string, params *stripe.AccountParams) (*stripe.Account, error) {
+		return &stripe.Account{ID: id, PayoutsEnabled: true}, nil
+	}
+
+	enabled, err := provider.PayoutsEnabled(context.Background(), "acct_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !enabled {
+		t.Error("expected payouts to be enabled")
+	}
+}
+
+func TestPayoutProvider_CreateTransfer(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{})
+	provider.createTransfer = func(params *stripe.TransferParams) (*stripe.Transfer, error) {
+		if *params.Amount != 2200 || *params.Currency != "usd" || *params.Destination != "acct_1" {
+			t.Errorf("unexpected transfer params: %d %s %s", *params.Amount, *params.Currency, *params.Destination)
+		}
+		if *params.TransferGroup != "payout-1" {
+			t.Errorf("expected transfer group payout-1, got %s", *params.TransferGroup)
+		}
+		if *params.IdempotencyKey != "payout-payout-1" {
+			t.Errorf("unexpected idempotency key %s", *params.IdempotencyKey)
+		}
+		return &stripe.Transfer{ID: "tr_1"}, nil
+	}
+
+	transferID, err := provider.CreateTransfer(context.Background(), "acct_1", 2200, "USD", map[string]string{"payout_id": "payout-1"}, "payout-payout-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transferID != "tr_1" {
+		t.Errorf("expected tr_1, got %s", transferID)
+	}
+}
+
+func TestPayoutProvider_CreateTransfer_Failure(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{})
+	provider.createTransfer = func(params *stripe.TransferParams) (*stripe.Transfer, error) {
+		return nil, errors.New("insufficient funds")
+	}
+
+	if _, err := provider.CreateTransfer(context.Background(), "acct_1", 100, "usd", nil, ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPayoutProvider_FindTransfer(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{})
+	provider.findTransfer = func(params *stripe.TransferListParams) (*stripe.Transfer, error) {
+		if *params.TransferGroup != "payout-1" {
+			t.Errorf("expected transfer group payout-1, got %s", *params.TransferGroup)
+		}
+		return &stripe.Transfer{ID: "tr_1"}, nil
+	}
+
+	transferID, err := provider.FindTransfer(context.Background(), "payout-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transferID != "tr_1" {
+		t.Errorf("expected tr_1, got %s", transferID)
+	}
+}
+
+func TestPayoutProvider_FindTransfer_None(t *testing.T) {
+	provider := NewPayoutProvider(ConnectConfig{})
+	provider.findTransfer = func(params *stripe.TransferListParams) (*stripe.Transfer, error) {
+		return nil, nil
+	}
+
+	transferID, err := provider.FindTransfer(context.Background(), "payout-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transferID != "" {
+		t.Errorf("expected no transfer, got %s", transferID)
+	}
+}
@@ -0,0 +1,41 @@
+package stripe
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/stripe/stripe-go/v81"
+)
+
+// IsRetryable reports whether a Stripe call that failed with err may succeed if repeated.
+// Stripe requests carry idempotency keys, so repeating them after a timeout is safe.
+func IsRetryable(err error) bool {
+	var stripeErr *stripe.Error
+	if errors.As(err, &stripeErr) {
+		switch {
+		case stripeErr.HTTPStatusCode == http.StatusTooManyRequests,
+			stripeErr.HTTPStatusCode >= http.StatusInternalServerError,
+			stripeErr.Type == stripe.ErrorTypeAPI,
+			stripeErr.Code == stripe.ErrorCodeLockTimeout,
+			stripeErr.Code == stripe.ErrorCodeRateLimit:
+			return true
+		}
+		// Card declines, invalid requests and authentication errors fail the same way every time
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// providerError reports a card error that will fail the same way again as domain.ErrPaymentDeclined,
+// so callers can fail the payment; other errors are returned unchanged
+func providerError(op string, err error) error {
+	var stripeErr *stripe.Error
+	if errors.As(err, &stripeErr) && stripeErr.Type == stripe.ErrorTypeCard && !IsRetryable(err) {
+		return &domain.ProviderError{Provider: ProviderName, Op: op, Err: domain.ErrPaymentDeclined, Cause: err}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package stripe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/stripe/stripe-go/v81"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "server error", err: &stripe.Error{HTTPStatusCode: 500, Type: stripe.ErrorTypeAPI}, want: true},
+		{name: "rate limited", err: &stripe.Error{HTTPStatusCode: 429, Code: stripe.ErrorCodeRateLimit}, want: true},
+		{name: "lock timeout", err: &stripe.Error{HTTPStatusCode: 400, Code: stripe.ErrorCodeLockTimeout}, want: true},
+		{name: "wrapped network error", err: fmt.Errorf("create session: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), want: true},
+		{name: "card declined", err: &stripe.Error{HTTPStatusCode: 402, Type: stripe.ErrorTypeCard}, want: false},
+		{name: "invalid request", err: &stripe.Error{HTTPStatusCode: 400, Type: stripe.ErrorTypeInvalidRequest}, want: false},
+		{name: "unknown error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		declined bool
+	}{
+		{name: "card declined", err: &stripe.Error{HTTPStatusCode: 402, Type: stripe.ErrorTypeCard, Code: stripe.ErrorCodeCardDeclined}, declined: true},
+		{name: "card rate limited", err: &stripe.Error{HTTPStatusCode: 429, Type: stripe.ErrorTypeCard, Code: stripe.ErrorCodeRateLimit}, declined: false},
+		{name: "invalid request", err: &stripe.Error{HTTPStatusCode: 400, Type: stripe.ErrorTypeInvalidRequest}, declined: false},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("refused")}, declined: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := providerError("capture payment", tt.err)
+			if got := errors.Is(err, domain.ErrPaymentDeclined); got != tt.declined {
+				t.Errorf("expected declined %v, got %v", tt.declined, got)
+			}
+			var stripeErr *stripe.Error
+			var netErr net.Error
+			if !errors.As(err, &stripeErr) && !errors.As(err, &netErr) {
+				t.Errorf("expected the Stripe error to be kept as the cause, got %v", err)
+			}
+		})
+	}
+}
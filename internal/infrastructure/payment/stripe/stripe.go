@@ -2,11 +2,19 @@ package stripe
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/ride4Low/payment-service/internal/domain"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/checkout/session"
+	"github.com/stripe/stripe-go/v81/paymentintent"
+	"github.com/stripe/stripe-go/v81/refund"
 )
 
+// ProviderName identifies Stripe as the provider of a payment
+const ProviderName = "stripe"
+
 type PaymentConfig struct {
 	StripeSecretKey     string `json:"stripeSecretKey"`
 	StripeWebhookSecret string `json:"stripeWebhookSecret"`
@@ -18,18 +26,38 @@ type PaymentConfig struct {
 // SessionCreator defines a function that creates a checkout session
 type SessionCreator func(params *stripe.CheckoutSessionParams) (*stripe.CheckoutSession, error)
 
+// RefundCreator defines a function that creates a refund
+type RefundCreator func(params *stripe.RefundParams) (*stripe.Refund, error)
+
+// PaymentIntentCapturer defines a function that captures a payment intent
+type PaymentIntentCapturer func(id string, params *stripe.PaymentIntentCaptureParams) (*stripe.PaymentIntent, error)
+
+// PaymentIntentCanceler defines a function that cancels a payment intent
+type PaymentIntentCanceler func(id string, params *stripe.PaymentIntentCancelParams) (*stripe.PaymentIntent, error)
+
+// SessionExpirer defines a function that expires a checkout session
+type SessionExpirer func(id string, params *stripe.CheckoutSessionExpireParams) (*stripe.CheckoutSession, error)
+
 // Provider implements application.PaymentProvider for Stripe
 type Provider struct {
-	config        PaymentConfig
-	createSession SessionCreator
+	config               PaymentConfig
+	createSession        SessionCreator
+	createRefund         RefundCreator
+	capturePaymentIntent PaymentIntentCapturer
+	cancelPaymentIntent  PaymentIntentCanceler
+	expireSession        SessionExpirer
 }
 
 // NewProvider creates a new Stripe payment provider
 func NewProvider(config PaymentConfig) *Provider {
 	stripe.Key = config.StripeSecretKey
 	return &Provider{
-		config:        config,
-		createSession: session.New,
+		config:               config,
+		createSession:        session.New,
+		createRefund:         refund.New,
+		capturePaymentIntent: paymentintent.Capture,
+		cancelPaymentIntent:  paymentintent.Cancel,
+		expireSession:        session.Expire,
 	}
 }
 
@@ -37,23 +65,52 @@ func NewProvider(config PaymentConfig) *Provider {
 func NewProviderWithCreator(config PaymentConfig, creator SessionCreator) *Provider {
 	stripe.Key = config.StripeSecretKey
 	return &Provider{
-		config:        config,
-		createSession: creator,
+		config:               config,
+		createSession:        creator,
+		createRefund:         refund.New,
+		capturePaymentIntent: paymentintent.Capture,
+		cancelPaymentIntent:  paymentintent.Cancel,
+		expireSession:        session.Expire,
 	}
 }
 
-// CreatePaymentSession creates a Stripe checkout session
-func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (string, error) {
+// Name returns the provider identifier recorded on payments
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// CreatePaymentSession creates a Stripe checkout session, de-duplicated by Stripe on the idempotency key
+func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return p.createCheckoutSession(amount, currency, metadata, idempotencyKey, stripe.PaymentIntentCaptureMethodAutomatic)
+}
+
+// CreateAuthorizationSession creates a Stripe checkout session that only authorizes the amount;
+// the payment intent waits in requires_capture until CapturePayment or CancelPayment is called
+func (p *Provider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return p.createCheckoutSession(amount, currency, metadata, idempotencyKey, stripe.PaymentIntentCaptureMethodManual)
+}
+
+func (p *Provider) createCheckoutSession(amount int64, currency string, metadata map[string]string, idempotencyKey string, captureMethod stripe.PaymentIntentCaptureMethod) (string, error) {
+	// Stripe takes zero-decimal amounts in whole units like the service does, but charges
+	// three-decimal currencies only in multiples of ten minor units
+	if exponent, err := domain.CurrencyExponent(currency); err == nil && exponent == 3 && amount%10 != 0 {
+		return "", fmt.Errorf("stripe charges %s in multiples of 10 minor units, got %d", strings.ToUpper(currency), amount)
+	}
 
 	params := &stripe.CheckoutSessionParams{
 		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
 		SuccessURL: stripe.String(p.config.SuccessURL),
 		CancelURL:  stripe.String(p.config.CancelURL),
 		Metadata:   metadata,
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			CaptureMethod: stripe.String(string(captureMethod)),
+			// Copy metadata so payment intent webhooks can be matched to the payment
+			Metadata: metadata,
+		},
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
 				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency: stripe.String(currency),
+					Currency: stripe.String(strings.ToLower(currency)),
 					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
 						Name: stripe.String("Test Product"),
 					},
@@ -64,9 +121,79 @@ func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, curre
 		},
 	}
 
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
 	result, err := p.createSession(params)
 	if err != nil {
-		return "", err
+		return "", providerError("create checkout session", err)
+	}
+
+	return result.ID, nil
+}
+
+// CapturePayment captures the final amount of an authorized payment intent; the remainder of the hold is released
+func (p *Provider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	params := &stripe.PaymentIntentCaptureParams{
+		AmountToCapture: stripe.Int64(amount),
+	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	_, err := p.capturePaymentIntent(providerPaymentID, params)
+	return providerError("capture payment", err)
+}
+
+// CancelPayment cancels an authorized payment intent, releasing the hold on the rider's card
+func (p *Provider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	params := &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonAbandoned)),
+	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	_, err := p.cancelPaymentIntent(providerPaymentID, params)
+	return providerError("cancel payment", err)
+}
+
+// ExpirePaymentSession expires an open checkout session so the rider can no longer complete it
+func (p *Provider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	params := &stripe.CheckoutSessionExpireParams{}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	_, err := p.expireSession(sessionID, params)
+	return providerError("expire payment session", err)
+}
+
+// RefundPayment refunds the given amount of a payment intent and returns the Stripe refund ID
+func (p *Provider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(providerPaymentID),
+		Amount:        stripe.Int64(amount),
+	}
+
+	// Stripe only accepts a fixed set of reasons; anything else is kept as metadata
+	switch stripe.RefundReason(reason) {
+	case stripe.RefundReasonDuplicate, stripe.RefundReasonFraudulent, stripe.RefundReasonRequestedByCustomer:
+		params.Reason = stripe.String(reason)
+	default:
+		if reason != "" {
+			params.AddMetadata("reason", reason)
+		}
+	}
+
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	result, err := p.createRefund(params)
+	if err != nil {
+		return "", providerError("refund payment", err)
 	}
 
 	return result.ID, nil
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ride4Low/payment-service/internal/domain"
 	"github.com/stripe/stripe-go/v81"
 )
 
@@ -38,7 +39,7 @@ func TestProvider_CreatePaymentSession_Success(t *testing.T) {
 	provider := NewProviderWithCreator(config, mockCreator)
 
 	ctx := context.Background()
-	sessionID, err := provider.CreatePaymentSession(ctx, 1000, "usd", nil)
+	sessionID, err := provider.CreatePaymentSession(ctx, 1000, "usd", nil, "")
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -72,7 +73,7 @@ func TestProvider_CreatePaymentSession_LinkItems(t *testing.T) {
 	}
 
 	provider := NewProviderWithCreator(config, mockCreator)
-	provider.CreatePaymentSession(context.Background(), 500, "eur", nil)
+	provider.CreatePaymentSession(context.Background(), 500, "eur", nil, "")
 }
 
 func TestProvider_CreatePaymentSession_Failure(t *testing.T) {
@@ -89,7 +90,7 @@ func TestProvider_CreatePaymentSession_Failure(t *testing.T) {
 	provider := NewProviderWithCreator(config, mockCreator)
 
 	ctx := context.Background()
-	_, err := provider.CreatePaymentSession(ctx, 1000, "usd", nil)
+	_, err := provider.CreatePaymentSession(ctx, 1000, "usd", nil, "")
 
 	if err == nil {
 		t.Fatal("expected error, got nil")
@@ -99,3 +100,203 @@ func TestProvider_CreatePaymentSession_Failure(t *testing.T) {
 		t.Errorf("expected error %v, got %v", expectedErr, err)
 	}
 }
+
+func TestProvider_CreatePaymentSession_IdempotencyKey(t *testing.T) {
+	config := PaymentConfig{
+		StripeSecretKey: "sk_test_123",
+	}
+
+	mockCreator := func(params *stripe.CheckoutSessionParams) (*stripe.CheckoutSession, error) {
+		if params.IdempotencyKey == nil || *params.IdempotencyKey != "payment-session-123" {
+			t.Errorf("expected idempotency key 'payment-session-123', got %v", params.IdempotencyKey)
+		}
+
+		if params.PaymentIntentData == nil || params.PaymentIntentData.Metadata["payment_id"] != "123" {
+			t.Error("expected metadata to be copied to the payment intent")
+		}
+
+		return &stripe.CheckoutSession{ID: "sess_123"}, nil
+	}
+
+	provider := NewProviderWithCreator(config, mockCreator)
+
+	metadata := map[string]string{"payment_id": "123"}
+	if _, err := provider.CreatePaymentSession(context.Background(), 1000, "usd", metadata, "payment-session-123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProvider_RefundPayment(t *testing.T) {
+	tests := []struct {
+		name           string
+		reason         string
+		wantReason     string
+		wantMetaReason string
+	}{
+		{"stripe reason", "requested_by_customer", "requested_by_customer", ""},
+		{"free-form reason", "driver no-show", "", "driver no-show"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+			provider.createRefund = func(params *stripe.RefundParams) (*stripe.Refund, error) {
+				if *params.PaymentIntent != "pi_test_123" {
+					t.Errorf("expected payment intent 'pi_test_123', got '%s'", *params.PaymentIntent)
+				}
+				if *params.Amount != 500 {
+					t.Errorf("expected amount 500, got %d", *params.Amount)
+				}
+				if *params.IdempotencyKey != "refund-key" {
+					t.Errorf("expected idempotency key 'refund-key', got '%s'", *params.IdempotencyKey)
+				}
+
+				gotReason := ""
+				if params.Reason != nil {
+					gotReason = *params.Reason
+				}
+				if gotReason != tt.wantReason {
+					t.Errorf("expected reason '%s', got '%s'", tt.wantReason, gotReason)
+				}
+				if params.Metadata["reason"] != tt.wantMetaReason {
+					t.Errorf("expected metadata reason '%s', got '%s'", tt.wantMetaReason, params.Metadata["reason"])
+				}
+
+				return &stripe.Refund{ID: "re_test_123"}, nil
+			}
+
+			refundID, err := provider.RefundPayment(context.Background(), "pi_test_123", 500, tt.reason, "refund-key")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if refundID != "re_test_123" {
+				t.Errorf("expected refund ID 're_test_123', got '%s'", refundID)
+			}
+		})
+	}
+}
+
+func TestProvider_RefundPayment_Failure(t *testing.T) {
+	expectedErr := errors.New("charge already refunded")
+
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+	provider.createRefund = func(params *stripe.RefundParams) (*stripe.Refund, error) {
+		return nil, expectedErr
+	}
+
+	_, err := provider.RefundPayment(context.Background(), "pi_test_123", 500, "", "")
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("expected error %v, got %v", expectedErr, err)
+	}
+}
+
+func TestProvider_CreateAuthorizationSession_ManualCapture(t *testing.T) {
+	mockCreator := func(params *stripe.CheckoutSessionParams) (*stripe.CheckoutSession, error) {
+		if params.PaymentIntentData == nil || *params.PaymentIntentData.CaptureMethod != string(stripe.PaymentIntentCaptureMethodManual) {
+			t.Errorf("expected manual capture method, got %+v", params.PaymentIntentData)
+		}
+		if params.PaymentIntentData.Metadata["payment_id"] != "payment-1" {
+			t.Errorf("expected payment ID on the payment intent, got %v", params.PaymentIntentData.Metadata)
+		}
+		return &stripe.CheckoutSession{ID: "cs_test_auth"}, nil
+	}
+
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, mockCreator)
+	sessionID, err := provider.CreateAuthorizationSession(context.Background(), 2500, "usd", map[string]string{"payment_id": "payment-1"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sessionID != "cs_test_auth" {
+		t.Errorf("expected session ID cs_test_auth, got %s", sessionID)
+	}
+}
+
+func TestProvider_CapturePayment(t *testing.T) {
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+	provider.capturePaymentIntent = func(id string, params *stripe.PaymentIntentCaptureParams) (*stripe.PaymentIntent, error) {
+		if id != "pi_test_123" {
+			t.Errorf("expected payment intent pi_test_123, got %s", id)
+		}
+		if *params.AmountToCapture != 1800 {
+			t.Errorf("expected amount to capture 1800, got %d", *params.AmountToCapture)
+		}
+		if *params.IdempotencyKey != "payment-capture-payment-1" {
+			t.Errorf("unexpected idempotency key %s", *params.IdempotencyKey)
+		}
+		return &stripe.PaymentIntent{ID: id}, nil
+	}
+
+	if err := provider.CapturePayment(context.Background(), "pi_test_123", 1800, "payment-capture-payment-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProvider_CapturePayment_CardDeclined(t *testing.T) {
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+	provider.capturePaymentIntent = func(id string, params *stripe.PaymentIntentCaptureParams) (*stripe.PaymentIntent, error) {
+		return nil, &stripe.Error{HTTPStatusCode: 402, Type: stripe.ErrorTypeCard, Code: stripe.ErrorCodeCardDeclined}
+	}
+
+	err := provider.CapturePayment(context.Background(), "pi_test_123", 1800, "")
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Error("expected a declined card not to be retried")
+	}
+}
+
+func TestProvider_CancelPayment(t *testing.T) {
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+	provider.cancelPaymentIntent = func(id string, params *stripe.PaymentIntentCancelParams) (*stripe.PaymentIntent, error) {
+		if id != "pi_test_123" {
+			t.Errorf("expected payment intent pi_test_123, got %s", id)
+		}
+		return nil, errors.New("payment intent already captured")
+	}
+
+	if err := provider.CancelPayment(context.Background(), "pi_test_123", ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestProvider_ExpirePaymentSession(t *testing.T) {
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, nil)
+	provider.expireSession = func(id string, params *stripe.CheckoutSessionExpireParams) (*stripe.CheckoutSession, error) {
+		if id != "cs_test_123" {
+			t.Errorf("expected checkout session cs_test_123, got %s", id)
+		}
+		if params.IdempotencyKey == nil || *params.IdempotencyKey != "payment-release-payment-1" {
+			t.Errorf("unexpected idempotency key %v", params.IdempotencyKey)
+		}
+		return &stripe.CheckoutSession{ID: id, Status: stripe.CheckoutSessionStatusExpired}, nil
+	}
+
+	if err := provider.ExpirePaymentSession(context.Background(), "cs_test_123", "payment-release-payment-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProvider_CreatePaymentSession_Currencies(t *testing.T) {
+	var params *stripe.CheckoutSessionParams
+	provider := NewProviderWithCreator(PaymentConfig{StripeSecretKey: "sk_test_123"}, func(p *stripe.CheckoutSessionParams) (*stripe.CheckoutSession, error) {
+		params = p
+		return &stripe.CheckoutSession{ID: "cs_test"}, nil
+	})
+
+	// Zero-decimal amounts are passed through as whole yen
+	if _, err := provider.CreatePaymentSession(context.Background(), 1850, "JPY", nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *params.LineItems[0].PriceData.Currency != "jpy" || *params.LineItems[0].PriceData.UnitAmount != 1850 {
+		t.Errorf("expected 1850 jpy, got %d %s", *params.LineItems[0].PriceData.UnitAmount, *params.LineItems[0].PriceData.Currency)
+	}
+
+	if _, err := provider.CreatePaymentSession(context.Background(), 1250, "KWD", nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := provider.CreatePaymentSession(context.Background(), 1255, "KWD", nil, ""); err == nil {
+		t.Error("expected error for a three-decimal amount not divisible by 10")
+	}
+}
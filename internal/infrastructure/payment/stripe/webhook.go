@@ -0,0 +1,137 @@
+package stripe
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+// Stripe webhook event types handled by the service
+const (
+	EventCheckoutSessionCompleted   = "checkout.session.completed"
+	EventCheckoutSessionExpired     = "checkout.session.expired"
+	EventCheckoutAsyncPaymentPaid   = "checkout.session.async_payment_succeeded"
+	EventCheckoutAsyncPaymentFailed = "checkout.session.async_payment_failed"
+	EventPaymentIntentCapturable    = "payment_intent.amount_capturable_updated"
+	EventPaymentIntentPaymentFailed = "payment_intent.payment_failed"
+	EventChargeRefunded             = "charge.refunded"
+)
+
+// metadataPaymentIDKey is the metadata key carrying our payment ID on Stripe objects
+const metadataPaymentIDKey = "payment_id"
+
+// WebhookParser verifies Stripe webhook signatures and translates events into application.ProviderEvent
+type WebhookParser struct {
+	secret string
+}
+
+// NewWebhookParser creates a new webhook parser using the endpoint's signing secret
+func NewWebhookParser(secret string) *WebhookParser {
+	return &WebhookParser{secret: secret}
+}
+
+// Parse verifies the Stripe-Signature header and converts the event payload
+func (p *WebhookParser) Parse(payload []byte, signature string) (*application.ProviderEvent, error) {
+	event, err := webhook.ConstructEventWithOptions(payload, signature, p.secret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid stripe webhook: %w", err)
+	}
+
+	return parseEvent(event)
+}
+
+func parseEvent(event stripe.Event) (*application.ProviderEvent, error) {
+	switch event.Type {
+	case EventCheckoutSessionCompleted, EventCheckoutSessionExpired, EventCheckoutAsyncPaymentPaid, EventCheckoutAsyncPaymentFailed:
+		var session stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal checkout session: %w", err)
+		}
+
+		result := &application.ProviderEvent{
+			Type:              application.ProviderEventSessionExpired,
+			PaymentID:         session.Metadata[metadataPaymentIDKey],
+			ProviderSessionID: session.ID,
+		}
+		if session.PaymentIntent != nil {
+			result.ProviderPaymentID = session.PaymentIntent.ID
+		}
+
+		switch event.Type {
+		case EventCheckoutSessionCompleted:
+			// Asynchronous payment methods complete the session before funds arrive
+			if session.PaymentStatus != stripe.CheckoutSessionPaymentStatusPaid {
+				return nil, application.ErrProviderEventIgnored
+			}
+			result.Type = application.ProviderEventPaymentSucceeded
+		case EventCheckoutAsyncPaymentPaid:
+			result.Type = application.ProviderEventPaymentSucceeded
+		case EventCheckoutAsyncPaymentFailed:
+			// The delayed payment of a completed session failed, so the rider cannot retry in it
+			result.Type = application.ProviderEventPaymentFailed
+			result.FailureReason = "asynchronous payment failed"
+		}
+		return result, nil
+
+	case EventPaymentIntentCapturable:
+		var intent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payment intent: %w", err)
+		}
+
+		// Fired when a manual-capture payment intent holds funds ready to capture
+		if intent.Status != stripe.PaymentIntentStatusRequiresCapture {
+			return nil, application.ErrProviderEventIgnored
+		}
+
+		return &application.ProviderEvent{
+			Type:              application.ProviderEventPaymentAuthorized,
+			PaymentID:         intent.Metadata[metadataPaymentIDKey],
+			ProviderPaymentID: intent.ID,
+		}, nil
+
+	case EventPaymentIntentPaymentFailed:
+		var intent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payment intent: %w", err)
+		}
+
+		reason := "payment failed"
+		if intent.LastPaymentError != nil && intent.LastPaymentError.Msg != "" {
+			reason = intent.LastPaymentError.Msg
+		}
+
+		// A declined attempt leaves the Checkout session open for another card; the payment only fails
+		// when the session expires or a delayed payment fails
+		return &application.ProviderEvent{
+			Type:              application.ProviderEventAttemptDeclined,
+			PaymentID:         intent.Metadata[metadataPaymentIDKey],
+			ProviderPaymentID: intent.ID,
+			FailureReason:     reason,
+		}, nil
+
+	case EventChargeRefunded:
+		var charge stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal charge: %w", err)
+		}
+
+		result := &application.ProviderEvent{
+			Type:           application.ProviderEventPaymentRefunded,
+			PaymentID:      charge.Metadata[metadataPaymentIDKey],
+			AmountRefunded: charge.AmountRefunded,
+		}
+		if charge.PaymentIntent != nil {
+			result.ProviderPaymentID = charge.PaymentIntent.ID
+		}
+		return result, nil
+
+	default:
+		return nil, application.ErrProviderEventIgnored
+	}
+}
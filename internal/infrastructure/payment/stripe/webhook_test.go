@@ -0,0 +1,210 @@
+package stripe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+const testWebhookSecret = "whsec_test_123"
+
+func signedPayload(t *testing.T, payload string) ([]byte, string) {
+	t.Helper()
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: []byte(payload),
+		Secret:  testWebhookSecret,
+	})
+	return signed.Payload, signed.Header
+}
+
+func TestWebhookParser_Parse_CheckoutSessionCompleted(t *testing.T) {
+	payload, signature := signedPayload(t, `{
+		"id": "evt_1",
+		"object": "event",
+		"type": "checkout.session.completed",
+		"data": {"object": {
+			"id": "cs_test_123",
+			"object": "checkout.session",
+			"payment_status": "paid",
+			"payment_intent": "pi_test_456",
+			"metadata": {"payment_id": "payment-1"}
+		}}
+	}`)
+
+	event, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if event.Type != application.ProviderEventPaymentSucceeded {
+		t.Errorf("expected type '%s', got '%s'", application.ProviderEventPaymentSucceeded, event.Type)
+	}
+
+	if event.PaymentID != "payment-1" {
+		t.Errorf("expected PaymentID 'payment-1', got '%s'", event.PaymentID)
+	}
+
+	if event.ProviderSessionID != "cs_test_123" {
+		t.Errorf("expected ProviderSessionID 'cs_test_123', got '%s'", event.ProviderSessionID)
+	}
+
+	if event.ProviderPaymentID != "pi_test_456" {
+		t.Errorf("expected ProviderPaymentID 'pi_test_456', got '%s'", event.ProviderPaymentID)
+	}
+}
+
+func TestWebhookParser_Parse_CheckoutSessionCompletedUnpaid(t *testing.T) {
+	payload, signature := signedPayload(t, `{
+		"id": "evt_1",
+		"object": "event",
+		"type": "checkout.session.completed",
+		"data": {"object": {"id": "cs_test_123", "object": "checkout.session", "payment_status": "unpaid"}}
+	}`)
+
+	_, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if !errors.Is(err, application.ErrProviderEventIgnored) {
+		t.Fatalf("expected ErrProviderEventIgnored, got %v", err)
+	}
+}
+
+func TestWebhookParser_Parse_PaymentFailed(t *testing.T) {
+	payload, signature := signedPayload(t, `{
+		"id": "evt_2",
+		"object": "event",
+		"type": "payment_intent.payment_failed",
+		"data": {"object": {
+			"id": "pi_test_456",
+			"object": "payment_intent",
+			"last_payment_error": {"message": "Your card was declined."},
+			"metadata": {"payment_id": "payment-1"}
+		}}
+	}`)
+
+	event, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if event.Type != application.ProviderEventAttemptDeclined {
+		t.Errorf("expected type '%s', got '%s'", application.ProviderEventAttemptDeclined, event.Type)
+	}
+
+	if event.FailureReason != "Your card was declined." {
+		t.Errorf("unexpected failure reason: %s", event.FailureReason)
+	}
+}
+
+func TestWebhookParser_Parse_AsyncPayment(t *testing.T) {
+	tests := []struct {
+		eventType string
+		want      application.ProviderEventType
+	}{
+		{EventCheckoutAsyncPaymentPaid, application.ProviderEventPaymentSucceeded},
+		{EventCheckoutAsyncPaymentFailed, application.ProviderEventPaymentFailed},
+	}
+	for _, tt := range tests {
+		payload, signature := signedPayload(t, `{
+			"id": "evt_3",
+			"object": "event",
+			"type": "`+tt.eventType+`",
+			"data": {"object": {
+				"id": "cs_test_123",
+				"object": "checkout.session",
+				"payment_status": "unpaid",
+				"payment_intent": "pi_test_456",
+				"metadata": {"payment_id": "payment-1"}
+			}}
+		}`)
+
+		event, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.eventType, err)
+		}
+		if event.Type != tt.want || event.PaymentID != "payment-1" || event.ProviderPaymentID != "pi_test_456" {
+			t.Errorf("unexpected event for %s: %+v", tt.eventType, event)
+		}
+	}
+}
+
+func TestWebhookParser_Parse_ChargeRefunded(t *testing.T) {
+	payload, signature := signedPayload(t, `{
+		"id": "evt_3",
+		"object": "event",
+		"type": "charge.refunded",
+		"data": {"object": {
+			"id": "ch_test_789",
+			"object": "charge",
+			"amount_refunded": 500,
+			"payment_intent": "pi_test_456"
+		}}
+	}`)
+
+	event, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if event.Type != application.ProviderEventPaymentRefunded {
+		t.Errorf("expected type '%s', got '%s'", application.ProviderEventPaymentRefunded, event.Type)
+	}
+
+	if event.AmountRefunded != 500 {
+		t.Errorf("expected AmountRefunded 500, got %d", event.AmountRefunded)
+	}
+
+	if event.ProviderPaymentID != "pi_test_456" {
+		t.Errorf("expected ProviderPaymentID 'pi_test_456', got '%s'", event.ProviderPaymentID)
+	}
+}
+
+func TestWebhookParser_Parse_InvalidSignature(t *testing.T) {
+	payload, _ := signedPayload(t, `{"id": "evt_4", "object": "event", "type": "charge.refunded"}`)
+
+	_, err := NewWebhookParser(testWebhookSecret).Parse(payload, "t=123,v1=invalid")
+	if err == nil {
+		t.Fatal("expected error for invalid signature")
+	}
+
+	if errors.Is(err, application.ErrProviderEventIgnored) {
+		t.Error("expected signature error, got ErrProviderEventIgnored")
+	}
+}
+
+func TestWebhookParser_Parse_UnhandledEvent(t *testing.T) {
+	payload, signature := signedPayload(t, `{"id": "evt_5", "object": "event", "type": "customer.created", "data": {"object": {}}}`)
+
+	_, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if !errors.Is(err, application.ErrProviderEventIgnored) {
+		t.Fatalf("expected ErrProviderEventIgnored, got %v", err)
+	}
+}
+
+func TestWebhookParser_Parse_PaymentIntentCapturable(t *testing.T) {
+	payload, signature := signedPayload(t, `{
+		"id": "evt_5",
+		"object": "event",
+		"type": "payment_intent.amount_capturable_updated",
+		"data": {"object": {
+			"id": "pi_test_789",
+			"object": "payment_intent",
+			"status": "requires_capture",
+			"amount_capturable": 2500,
+			"metadata": {"payment_id": "payment-1"}
+		}}
+	}`)
+
+	event, err := NewWebhookParser(testWebhookSecret).Parse(payload, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if event.Type != application.ProviderEventPaymentAuthorized {
+		t.Errorf("expected type '%s', got '%s'", application.ProviderEventPaymentAuthorized, event.Type)
+	}
+
+	if event.PaymentID != "payment-1" || event.ProviderPaymentID != "pi_test_789" {
+		t.Errorf("unexpected references: %+v", event)
+	}
+}
@@ -0,0 +1,149 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLookbackBlocks is how many recent blocks are searched for a settlement, about 5 hours on Base.
+// Settlements are looked up when a payment command is redelivered, minutes after the payload was signed.
+const DefaultLookbackBlocks = 10_000
+
+// authorizationUsedTopic identifies the ERC-3009 AuthorizationUsed(address indexed authorizer, bytes32 indexed nonce) event
+var authorizationUsedTopic = "0x" + hex.EncodeToString(keccak256([]byte("AuthorizationUsed(address,bytes32)")))
+
+// ChainReader finds the transactions that used ERC-3009 authorizations (allows mocking in tests)
+type ChainReader interface {
+	// FindAuthorization returns the hash of the transaction in which the token contract accepted the
+	// payer's authorization nonce, or an empty hash when the nonce was not used
+	FindAuthorization(ctx context.Context, network, asset, authorizer, nonce string) (string, error)
+}
+
+// RPCChainReader implements ChainReader with the logs of an Ethereum JSON-RPC endpoint per network
+type RPCChainReader struct {
+	endpoints  map[string]string
+	httpClient *http.Client
+	lookback   uint64
+}
+
+// NewRPCChainReader creates a chain reader for the networks in endpoints, which maps a network to its JSON-RPC URL
+func NewRPCChainReader(endpoints map[string]string) *RPCChainReader {
+	return &RPCChainReader{
+		endpoints:  endpoints,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		lookback:   DefaultLookbackBlocks,
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type logFilter struct {
+	Address   string   `json:"address"`
+	FromBlock string   `json:"fromBlock"`
+	ToBlock   string   `json:"toBlock"`
+	Topics    []string `json:"topics"`
+}
+
+// FindAuthorization searches the token contract's AuthorizationUsed events of the last lookback blocks
+func (c *RPCChainReader) FindAuthorization(ctx context.Context, network, asset, authorizer, nonce string) (string, error) {
+	url, ok := c.endpoints[network]
+	if !ok {
+		return "", fmt.Errorf("no JSON-RPC endpoint for network %s: %w", network, errors.ErrUnsupported)
+	}
+
+	var latestHex string
+	if err := c.call(ctx, url, "eth_blockNumber", nil, &latestHex); err != nil {
+		return "", err
+	}
+	latest, err := strconv.ParseUint(strings.TrimPrefix(latestHex, "0x"), 16, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid block number %q: %w", latestHex, err)
+	}
+	fromBlock := uint64(0)
+	if latest > c.lookback {
+		fromBlock = latest - c.lookback
+	}
+
+	filter := logFilter{
+		Address:   strings.ToLower(asset),
+		FromBlock: "0x" + strconv.FormatUint(fromBlock, 16),
+		ToBlock:   "latest",
+		Topics: []string{
+			authorizationUsedTopic,
+			"0x" + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(authorizer), "0x"),
+			strings.ToLower(nonce),
+		},
+	}
+	var logs []struct {
+		TransactionHash string `json:"transactionHash"`
+		Removed         bool   `json:"removed"`
+	}
+	if err := c.call(ctx, url, "eth_getLogs", []any{filter}, &logs); err != nil {
+		return "", err
+	}
+	for _, entry := range logs {
+		if !entry.Removed {
+			return entry.TransactionHash, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *RPCChainReader) call(ctx context.Context, url, method string, params []any, out any) error {
+	if params == nil {
+		params = []any{}
+	}
+	jsonBody, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed: JSON-RPC endpoint returned %d %s", method, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s failed: %d %s", method, rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if err := json.Unmarshal(rpcResp.Result, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
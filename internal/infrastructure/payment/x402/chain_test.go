@@ -0,0 +1,66 @@
+package x402_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+)
+
+func TestRPCChainReader_FindAuthorization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch req.Method {
+		case "eth_blockNumber":
+			json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": "0x4e20"})
+		case "eth_getLogs":
+			var filter struct {
+				Address   string   `json:"address"`
+				FromBlock string   `json:"fromBlock"`
+				Topics    []string `json:"topics"`
+			}
+			json.Unmarshal(req.Params[0], &filter)
+			if filter.Address != "0x036cbd53842c5426634e7929541ec2318f3dcf7e" || filter.FromBlock != "0x2710" {
+				t.Errorf("unexpected filter: %+v", filter)
+			}
+			// keccak256("AuthorizationUsed(address,bytes32)"), the authorizer padded to 32 bytes, the nonce
+			want := []string{
+				"0x98de503528ee59b575ef0c0a2576a82497bfc029a5685b209e9ec333479b10a5",
+				"0x000000000000000000000000ed437cc3e8ba88dbfe3f7a912f96fb51a3ca3752",
+				"0x9c6230254ac733f54ec47298f0a5ddaf93dc9efe6e05fb726dcb6faf10ddece2",
+			}
+			if len(filter.Topics) != 3 || filter.Topics[0] != want[0] || filter.Topics[1] != want[1] || filter.Topics[2] != want[2] {
+				t.Errorf("unexpected topics: %v", filter.Topics)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": []map[string]any{{"transactionHash": "0xtxhash"}}})
+		}
+	}))
+	defer server.Close()
+
+	reader := x402.NewRPCChainReader(map[string]string{"base-sepolia": server.URL})
+	transactionHash, err := reader.FindAuthorization(context.Background(), "base-sepolia", testAsset,
+		"0xED437cc3e8ba88dbfe3f7a912f96fb51a3ca3752", "0x9c6230254ac733f54ec47298f0a5ddaf93dc9efe6e05fb726dcb6faf10ddece2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transactionHash != "0xtxhash" {
+		t.Errorf("expected 0xtxhash, got %s", transactionHash)
+	}
+}
+
+func TestRPCChainReader_FindAuthorization_UnknownNetwork(t *testing.T) {
+	reader := x402.NewRPCChainReader(nil)
+
+	if _, err := reader.FindAuthorization(context.Background(), "base", testAsset, "0xrider", "0x01"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -2,14 +2,60 @@ package x402
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // DefaultFacilitatorURL is the default URL for the x402 facilitator service
 const DefaultFacilitatorURL = "https://x402.org/facilitator"
 
+// maxErrorBodyBytes caps how much of a failed response body is kept on a FacilitatorError
+const maxErrorBodyBytes = 4096
+
+// FacilitatorError is returned when the facilitator answers with a non-200 status
+type FacilitatorError struct {
+	Op         string // "verify" or "settle"
+	StatusCode int
+	Body       string // the facilitator's error body, truncated to maxErrorBodyBytes
+}
+
+func (e *FacilitatorError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("failed to %s payment: facilitator returned %d %s", e.Op, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("failed to %s payment: facilitator returned %d %s: %s", e.Op, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// IsRetryable reports whether a facilitator call that failed with err may succeed if repeated.
+// Settling moves funds on-chain and a gateway error does not tell whether it did, so a failed settle
+// is only retried when the connection was never established. Verifying moves nothing and is also
+// retried on throttling and gateway errors.
+func IsRetryable(err error) bool {
+	var facilitatorErr *FacilitatorError
+	if errors.As(err, &facilitatorErr) {
+		if facilitatorErr.Op != "verify" {
+			return false
+		}
+		switch facilitatorErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
 // FacilitatorClient represents a facilitator client for verifying and settling payments
 type FacilitatorClient struct {
 	URL               string
@@ -37,72 +83,61 @@ func NewFacilitatorClient(config *FacilitatorConfig) *FacilitatorClient {
 	}
 }
 
-// Verify sends a payment verification request to the facilitator
+// Verify sends a payment verification request to the facilitator.
+// Prefer VerifyContext, which can be cancelled and carries the caller's trace.
 func (c *FacilitatorClient) Verify(payload *PaymentPayload, requirements *PaymentRequirements) (*VerifyResponse, error) {
-	reqBody := map[string]any{
-		"x402Version":         1,
-		"paymentPayload":      payload,
-		"paymentRequirements": requirements,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
+	return c.VerifyContext(context.Background(), payload, requirements)
+}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/verify", c.URL), bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// VerifyContext sends a payment verification request to the facilitator, aborting when ctx is done
+func (c *FacilitatorClient) VerifyContext(ctx context.Context, payload *PaymentPayload, requirements *PaymentRequirements) (*VerifyResponse, error) {
+	var verifyResp VerifyResponse
+	if err := c.post(ctx, "verify", payload, requirements, &verifyResp); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return &verifyResp, nil
+}
 
-	// Add auth headers if available
-	if c.CreateAuthHeaders != nil {
-		headers, err := c.CreateAuthHeaders()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create auth headers: %w", err)
-		}
-		if verifyHeaders, ok := headers["verify"]; ok {
-			for key, value := range verifyHeaders {
-				req.Header.Set(key, value)
-			}
-		}
-	}
+// Settle sends a payment settlement request to the facilitator.
+// Prefer SettleContext, which can be cancelled and carries the caller's trace.
+func (c *FacilitatorClient) Settle(payload *PaymentPayload, requirements *PaymentRequirements) (*SettleResponse, error) {
+	return c.SettleContext(context.Background(), payload, requirements)
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send verify request: %w", err)
+// SettleContext sends a payment settlement request to the facilitator, aborting when ctx is done
+func (c *FacilitatorClient) SettleContext(ctx context.Context, payload *PaymentPayload, requirements *PaymentRequirements) (*SettleResponse, error) {
+	var settleResp SettleResponse
+	if err := c.post(ctx, "settle", payload, requirements, &settleResp); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return &settleResp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to verify payment: %s", resp.Status)
+// post sends the payment to the facilitator's op endpoint and decodes the response into out
+func (c *FacilitatorClient) post(ctx context.Context, op string, payload *PaymentPayload, requirements *PaymentRequirements, out any) error {
+	version := payload.X402Version
+	if version == 0 {
+		version = ProtocolV1
 	}
-
-	var verifyResp VerifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&verifyResp); err != nil {
-		return nil, fmt.Errorf("failed to decode verify response: %w", err)
+	// Requirements are sent in the shape of the payload's version
+	var wireRequirements any = requirements
+	if version == ProtocolV2 {
+		wireRequirements = requirements.V2()
 	}
-
-	return &verifyResp, nil
-}
-
-// Settle sends a payment settlement request to the facilitator
-func (c *FacilitatorClient) Settle(payload *PaymentPayload, requirements *PaymentRequirements) (*SettleResponse, error) {
 	reqBody := map[string]any{
-		"x402Version":         1,
+		"x402Version":         version,
 		"paymentPayload":      payload,
-		"paymentRequirements": requirements,
+		"paymentRequirements": wireRequirements,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/settle", c.URL), bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", c.URL, op), bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -110,29 +145,29 @@ func (c *FacilitatorClient) Settle(payload *PaymentPayload, requirements *Paymen
 	if c.CreateAuthHeaders != nil {
 		headers, err := c.CreateAuthHeaders()
 		if err != nil {
-			return nil, fmt.Errorf("failed to create auth headers: %w", err)
+			return fmt.Errorf("failed to create auth headers: %w", err)
 		}
-		if settleHeaders, ok := headers["settle"]; ok {
-			for key, value := range settleHeaders {
-				req.Header.Set(key, value)
-			}
+		for key, value := range headers[op] {
+			req.Header.Set(key, value)
 		}
 	}
 
+	// Continue the caller's trace on the facilitator side
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send settle request: %w", err)
+		return fmt.Errorf("failed to send %s request: %w", op, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to settle payment: %s", resp.Status)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &FacilitatorError{Op: op, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
-	var settleResp SettleResponse
-	if err := json.NewDecoder(resp.Body).Decode(&settleResp); err != nil {
-		return nil, fmt.Errorf("failed to decode settle response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", op, err)
 	}
-
-	return &settleResp, nil
+	return nil
 }
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestVerify(t *testing.T) {
@@ -318,3 +323,101 @@ func TestSettleWithAuthHeaders(t *testing.T) {
 		t.Errorf("Expected auth header '%s', got: '%s'", expectedAuthHeader, capturedAuthHeader)
 	}
 }
+
+func TestVerifyContext_Canceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := client.VerifyContext(ctx, &x402.PaymentPayload{}, &x402.PaymentRequirements{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error, got: %v", err)
+	}
+}
+
+func TestSettleContext_PropagatesTrace(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(x402.SettleResponse{Success: true})
+	}))
+	defer server.Close()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	client := x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL})
+	if _, err := client.SettleContext(ctx, &x402.PaymentPayload{}, &x402.PaymentRequirements{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "00-" + spanContext.TraceID().String() + "-" + spanContext.SpanID().String() + "-01"
+	if traceparent != expected {
+		t.Errorf("Expected traceparent '%s', got: '%s'", expected, traceparent)
+	}
+}
+
+func TestSettleContext_FacilitatorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"insufficient_funds"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL})
+
+	_, err := client.SettleContext(context.Background(), &x402.PaymentPayload{}, &x402.PaymentRequirements{})
+	var facilitatorErr *x402.FacilitatorError
+	if !errors.As(err, &facilitatorErr) {
+		t.Fatalf("Expected FacilitatorError, got: %v", err)
+	}
+	if facilitatorErr.Op != "settle" || facilitatorErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected error fields: %s %d", facilitatorErr.Op, facilitatorErr.StatusCode)
+	}
+	if facilitatorErr.Body != `{"error":"insufficient_funds"}` {
+		t.Errorf("Unexpected error body: %s", facilitatorErr.Body)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "verify throttled", err: &x402.FacilitatorError{Op: "verify", StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "verify unavailable", err: &x402.FacilitatorError{Op: "verify", StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "verify bad gateway", err: &x402.FacilitatorError{Op: "verify", StatusCode: http.StatusBadGateway}, want: true},
+		{name: "verify internal error", err: &x402.FacilitatorError{Op: "verify", StatusCode: http.StatusInternalServerError}, want: false},
+		{name: "settle throttled", err: &x402.FacilitatorError{Op: "settle", StatusCode: http.StatusTooManyRequests}, want: false},
+		{name: "settle bad gateway", err: &x402.FacilitatorError{Op: "settle", StatusCode: http.StatusBadGateway}, want: false},
+		{name: "settle gateway timeout", err: fmt.Errorf("wrapped: %w", &x402.FacilitatorError{Op: "settle", StatusCode: http.StatusGatewayTimeout}), want: false},
+		{name: "rejected", err: &x402.FacilitatorError{Op: "verify", StatusCode: http.StatusBadRequest}, want: false},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("refused")}, want: true},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x402.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
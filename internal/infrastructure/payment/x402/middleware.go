@@ -0,0 +1,218 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+)
+
+// Header names of the x402 HTTP transport
+const (
+	PaymentHeader         = "X-PAYMENT"
+	PaymentResponseHeader = "X-PAYMENT-RESPONSE"
+
+	// v2 transport: the payment requirements are also sent in a header
+	PaymentSignatureHeader  = "PAYMENT-SIGNATURE"
+	PaymentRequiredHeader   = "PAYMENT-REQUIRED"
+	PaymentResponseHeaderV2 = "PAYMENT-RESPONSE"
+)
+
+// PaymentRequiredResponse is the body of a 402 reply, listing the payments the resource accepts
+type PaymentRequiredResponse struct {
+	X402Version int                    `json:"x402Version"`
+	Error       string                 `json:"error"`
+	Accepts     []*PaymentRequirements `json:"accepts"`
+	Payer       string                 `json:"payer,omitempty"`
+}
+
+// Paywall returns middleware charging every request to the wrapped handler; the client pays
+// against any one of accepts, e.g. the same price on several networks. A request without a valid
+// payment is answered 402 with the accepted requirements, in the protocol version of the client's
+// payment or, when it sent none, as a v1 body and a v2 PAYMENT-REQUIRED header. v1 clients pay in
+// the X-PAYMENT header and v2 clients in PAYMENT-SIGNATURE. A verified payment is settled only after
+// the handler succeeds, and the settlement is returned in the response header of the payment's
+// version. An empty Resource is filled with the request URL. Authorization nonces are reserved in
+// nonces while a request is served, so a replayed payment is refused.
+func Paywall(accepts []PaymentRequirements, facilitator Facilitator, nonces application.NonceStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			offered := make([]*PaymentRequirements, len(accepts))
+			for i := range accepts {
+				requirements := accepts[i]
+				if requirements.Resource == "" {
+					requirements.Resource = requestURL(r)
+				}
+				offered[i] = &requirements
+			}
+
+			headerName, version := PaymentSignatureHeader, ProtocolV2
+			header := r.Header.Get(headerName)
+			if header == "" {
+				headerName, version = PaymentHeader, ProtocolV1
+				header = r.Header.Get(headerName)
+			}
+			if header == "" {
+				writePaymentRequired(w, 0, offered, "payment is required", nil)
+				return
+			}
+
+			payload, err := DecodePaymentPayloadFromBase64(header)
+			if err != nil || payload.Payload == nil {
+				writePaymentRequired(w, version, offered, "invalid "+headerName+" header", nil)
+				return
+			}
+			version = payload.X402Version
+			required := MatchRequirements(offered, payload)
+			if required == nil {
+				writePaymentRequired(w, version, offered, "payment requirements not accepted", nil)
+				return
+			}
+			if err := PreVerify(payload, required, time.Now()); err != nil {
+				writePaymentRequired(w, version, offered, err.Error(), nil)
+				return
+			}
+
+			release, err := reserveNonce(r.Context(), nonces, payload)
+			if err != nil {
+				var invalidErr *InvalidPaymentError
+				if errors.As(err, &invalidErr) {
+					writePaymentRequired(w, version, offered, err.Error(), nil)
+					return
+				}
+				log.Printf("x402 paywall: %v", err)
+				http.Error(w, "failed to verify payment", http.StatusInternalServerError)
+				return
+			}
+
+			verifyResp, err := facilitator.VerifyContext(r.Context(), payload, required)
+			if err != nil {
+				release()
+				log.Printf("x402 paywall: %v", err)
+				http.Error(w, "failed to verify payment", http.StatusBadGateway)
+				return
+			}
+			if !verifyResp.IsValid {
+				release()
+				writePaymentRequired(w, version, offered, reason(verifyResp.InvalidReason), verifyResp.Payer)
+				return
+			}
+
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.status >= http.StatusBadRequest {
+				// nothing was delivered, so the rider's authorization is left unspent
+				release()
+				rec.flush(w)
+				return
+			}
+
+			// The response is already produced: settle even if the client has gone away
+			settleResp, err := facilitator.SettleContext(context.WithoutCancel(r.Context()), payload, required)
+			if err != nil {
+				release()
+				log.Printf("x402 paywall: %v", err)
+				http.Error(w, "failed to settle payment", http.StatusBadGateway)
+				return
+			}
+			if !settleResp.Success {
+				release()
+				writePaymentRequired(w, version, offered, reason(settleResp.ErrorReason), settleResp.Payer)
+				return
+			}
+
+			encoded, err := settleResp.EncodeToBase64String()
+			if err != nil {
+				log.Printf("x402 paywall: %v", err)
+				http.Error(w, "failed to encode settlement", http.StatusInternalServerError)
+				return
+			}
+			responseHeader := PaymentResponseHeader
+			if version == ProtocolV2 {
+				responseHeader = PaymentResponseHeaderV2
+			}
+			rec.header.Set(responseHeader, encoded)
+			rec.flush(w)
+		})
+	}
+}
+
+// writePaymentRequired answers 402 with accepts in the given protocol version; version 0 is used
+// when the client's version is unknown and answers in both
+func writePaymentRequired(w http.ResponseWriter, version int, accepts []*PaymentRequirements, message string, payer *string) {
+	v1 := PaymentRequiredResponse{
+		X402Version: ProtocolV1,
+		Error:       message,
+		Accepts:     accepts,
+	}
+	if payer != nil {
+		v1.Payer = *payer
+	}
+	var response any = v1
+
+	if version != ProtocolV1 {
+		required := NewPaymentRequiredV2(accepts, message)
+		if jsonBytes, err := json.Marshal(required); err != nil {
+			log.Printf("x402 paywall: failed to encode payment requirements: %v", err)
+		} else {
+			w.Header().Set(PaymentRequiredHeader, base64.StdEncoding.EncodeToString(jsonBytes))
+		}
+		if version == ProtocolV2 {
+			response = required
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPaymentRequired)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("x402 paywall: failed to write response: %v", err)
+	}
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// bufferedResponse holds the wrapped handler's response until the payment is settled,
+// so the settlement header can still be added and nothing is sent for an unpaid request
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wrote {
+		b.status, b.wrote = status, true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	if _, err := w.Write(b.body.Bytes()); err != nil {
+		log.Printf("x402 paywall: failed to write response: %v", err)
+	}
+}
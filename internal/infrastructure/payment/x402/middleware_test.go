@@ -0,0 +1,290 @@
+package x402_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+)
+
+// mockFacilitator is a mock implementation of x402.Facilitator
+type mockFacilitator struct {
+	verify       *x402.VerifyResponse
+	settle       *x402.SettleResponse
+	err          error
+	verified     bool
+	settled      bool
+	requirements *x402.PaymentRequirements
+}
+
+func (m *mockFacilitator) VerifyContext(ctx context.Context, payload *x402.PaymentPayload, requirements *x402.PaymentRequirements) (*x402.VerifyResponse, error) {
+	m.verified, m.requirements = true, requirements
+	return m.verify, m.err
+}
+
+func (m *mockFacilitator) SettleContext(ctx context.Context, payload *x402.PaymentPayload, requirements *x402.PaymentRequirements) (*x402.SettleResponse, error) {
+	m.settled = true
+	return m.settle, m.err
+}
+
+func paywallRequirements() []x402.PaymentRequirements {
+	return []x402.PaymentRequirements{
+		{Scheme: "exact", Network: "base-sepolia", MaxAmountRequired: "10000", PayTo: testPayTo, Asset: testAsset, MaxTimeoutSeconds: 60},
+		{Scheme: "exact", Network: "solana-devnet", MaxAmountRequired: "10000", PayTo: "platformSolanaWallet", Asset: "usdcMint", MaxTimeoutSeconds: 60},
+	}
+}
+
+func servePaywall(nonces application.NonceStore, facilitator x402.Facilitator, handler http.HandlerFunc, payment string) *httptest.ResponseRecorder {
+	paywall := x402.Paywall(paywallRequirements(), facilitator, nonces)
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.ride4low.test/fare-estimates?from=a&to=b", nil)
+	if payment != "" {
+		req.Header.Set(x402.PaymentHeader, payment)
+	}
+	rec := httptest.NewRecorder()
+	paywall(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"estimate":1850}`))
+}
+
+func decodePaymentRequired(t *testing.T, rec *httptest.ResponseRecorder) x402.PaymentRequiredResponse {
+	t.Helper()
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected status 402, got %d", rec.Code)
+	}
+	var response x402.PaymentRequiredResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestPaywall_MissingPayment(t *testing.T) {
+	facilitator := &mockFacilitator{}
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, okHandler, "")
+
+	response := decodePaymentRequired(t, rec)
+	if response.X402Version != 1 || len(response.Accepts) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	if response.Accepts[0].Resource != "http://api.ride4low.test/fare-estimates?from=a&to=b" {
+		t.Errorf("expected request URL as resource, got %s", response.Accepts[0].Resource)
+	}
+	if response.Accepts[0].MaxAmountRequired != "10000" {
+		t.Errorf("unexpected amount %s", response.Accepts[0].MaxAmountRequired)
+	}
+	if facilitator.verified {
+		t.Error("expected facilitator not to be called")
+	}
+}
+
+func TestPaywall_MissingPaymentAdvertisesV2(t *testing.T) {
+	rec := servePaywall(x402.NewMemoryNonceStore(), &mockFacilitator{}, okHandler, "")
+
+	decoded, err := base64.StdEncoding.DecodeString(rec.Header().Get(x402.PaymentRequiredHeader))
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", x402.PaymentRequiredHeader, err)
+	}
+	var required x402.PaymentRequiredV2
+	if err := json.Unmarshal(decoded, &required); err != nil {
+		t.Fatalf("failed to decode requirements: %v", err)
+	}
+	if required.X402Version != 2 || required.Resource == nil || required.Resource.URL != "http://api.ride4low.test/fare-estimates?from=a&to=b" {
+		t.Fatalf("unexpected v2 requirements: %s", decoded)
+	}
+	if len(required.Accepts) != 2 || required.Accepts[0].Amount != "10000" {
+		t.Errorf("unexpected v2 accepts: %s", decoded)
+	}
+}
+
+func TestPaywall_RejectedPayment(t *testing.T) {
+	reason := "insufficient_funds"
+	tests := []struct {
+		name    string
+		payment string
+		verify  *x402.VerifyResponse
+		want    string
+	}{
+		{name: "not base64", payment: "%%%", want: "invalid X-PAYMENT header"},
+		{name: "other network", payment: base64.StdEncoding.EncodeToString([]byte(`{"scheme":"exact","network":"base","payload":{"signature":"0x","authorization":{}}}`)), want: "payment requirements not accepted"},
+		{name: "other amount accepted", payment: base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"solana-devnet","amount":"1","asset":"usdcMint","payTo":"platformSolanaWallet","maxTimeoutSeconds":60},"payload":{"transaction":"AQAB"}}`)), want: "payment requirements not accepted"},
+		{name: "unknown scheme", payment: base64.StdEncoding.EncodeToString([]byte(`{"scheme":"upto","network":"base-sepolia","payload":{}}`)), want: "invalid X-PAYMENT header"},
+		{name: "verification failed", payment: "", verify: &x402.VerifyResponse{IsValid: false, InvalidReason: &reason}, want: "insufficient_funds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := tt.payment
+			if payment == "" {
+				payment = encodedTestPayload(t)
+			}
+			facilitator := &mockFacilitator{verify: tt.verify}
+			var served bool
+
+			rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, func(w http.ResponseWriter, r *http.Request) { served = true }, payment)
+
+			if response := decodePaymentRequired(t, rec); response.Error != tt.want {
+				t.Errorf("expected error %q, got %q", tt.want, response.Error)
+			}
+			if served || facilitator.settled {
+				t.Error("expected unpaid request not to be served or settled")
+			}
+		})
+	}
+}
+
+func TestPaywall_Settles(t *testing.T) {
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"},
+	}
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, okHandler, encodedTestPayload(t))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"estimate":1850}` {
+		t.Fatalf("expected the handler's response, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected handler headers to be kept, got %v", rec.Header())
+	}
+	if !facilitator.settled {
+		t.Fatal("expected payment to be settled")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(rec.Header().Get(x402.PaymentResponseHeader))
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", x402.PaymentResponseHeader, err)
+	}
+	var settlement x402.SettleResponse
+	if err := json.Unmarshal(decoded, &settlement); err != nil || settlement.Transaction != "0xtxhash" {
+		t.Errorf("unexpected settlement %s: %v", decoded, err)
+	}
+}
+
+func TestPaywall_SettlesOnChosenNetwork(t *testing.T) {
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: true, Transaction: "5solanaSignature", Network: "solana-devnet"},
+	}
+	payment := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"solana-devnet","amount":"10000","asset":"usdcMint","payTo":"platformSolanaWallet","maxTimeoutSeconds":60},"payload":{"transaction":"AQAB"}}`))
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, okHandler, payment)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if facilitator.requirements == nil || facilitator.requirements.PayTo != "platformSolanaWallet" {
+		t.Errorf("expected the Solana requirements to be verified, got %+v", facilitator.requirements)
+	}
+}
+
+func TestPaywall_SettlesV2(t *testing.T) {
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: true, Transaction: "5solanaSignature", Network: "solana-devnet"},
+	}
+	payment := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"solana-devnet","amount":"10000","asset":"usdcMint","payTo":"platformSolanaWallet","maxTimeoutSeconds":60},"payload":{"transaction":"AQAB"}}`))
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.ride4low.test/fare-estimates?from=a&to=b", nil)
+	req.Header.Set(x402.PaymentSignatureHeader, payment)
+	rec := httptest.NewRecorder()
+	x402.Paywall(paywallRequirements(), facilitator, x402.NewMemoryNonceStore())(http.HandlerFunc(okHandler)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Header().Get(x402.PaymentResponseHeaderV2) == "" || rec.Header().Get(x402.PaymentResponseHeader) != "" {
+		t.Errorf("expected the settlement in %s, got %v", x402.PaymentResponseHeaderV2, rec.Header())
+	}
+}
+
+func TestPaywall_Replay(t *testing.T) {
+	nonces := x402.NewMemoryNonceStore()
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"},
+	}
+	if rec := servePaywall(nonces, facilitator, okHandler, encodedTestPayload(t)); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	replay := &mockFacilitator{verify: &x402.VerifyResponse{IsValid: true}}
+	rec := servePaywall(nonces, replay, okHandler, encodedTestPayload(t))
+
+	if response := decodePaymentRequired(t, rec); !strings.HasPrefix(response.Error, x402.ReasonNonceUsed) {
+		t.Errorf("expected %s, got %q", x402.ReasonNonceUsed, response.Error)
+	}
+	if replay.verified {
+		t.Error("expected the replay not to reach the facilitator")
+	}
+}
+
+func TestPaywall_HandlerFailureReleasesNonce(t *testing.T) {
+	nonces := x402.NewMemoryNonceStore()
+	failing := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "estimate unavailable", http.StatusServiceUnavailable)
+	}
+	servePaywall(nonces, &mockFacilitator{verify: &x402.VerifyResponse{IsValid: true}}, failing, encodedTestPayload(t))
+
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"},
+	}
+	if rec := servePaywall(nonces, facilitator, okHandler, encodedTestPayload(t)); rec.Code != http.StatusOK {
+		t.Fatalf("expected the unspent authorization to be usable again, got %d", rec.Code)
+	}
+}
+
+func TestPaywall_HandlerFailureNotSettled(t *testing.T) {
+	facilitator := &mockFacilitator{verify: &x402.VerifyResponse{IsValid: true}}
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "estimate unavailable", http.StatusServiceUnavailable)
+	}, encodedTestPayload(t))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the handler's status 503, got %d", rec.Code)
+	}
+	if facilitator.settled {
+		t.Error("expected failed request not to be settled")
+	}
+	if rec.Header().Get(x402.PaymentResponseHeader) != "" {
+		t.Error("expected no settlement header")
+	}
+}
+
+func TestPaywall_SettlementFailed(t *testing.T) {
+	reason := "authorization_expired"
+	facilitator := &mockFacilitator{
+		verify: &x402.VerifyResponse{IsValid: true},
+		settle: &x402.SettleResponse{Success: false, ErrorReason: &reason},
+	}
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, okHandler, encodedTestPayload(t))
+
+	if response := decodePaymentRequired(t, rec); response.Error != "authorization_expired" {
+		t.Errorf("unexpected error %q", response.Error)
+	}
+}
+
+func TestPaywall_FacilitatorUnavailable(t *testing.T) {
+	facilitator := &mockFacilitator{err: errors.New("connection refused")}
+
+	rec := servePaywall(x402.NewMemoryNonceStore(), facilitator, okHandler, encodedTestPayload(t))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected status 502, got %d", rec.Code)
+	}
+}
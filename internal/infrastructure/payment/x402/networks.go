@@ -0,0 +1,42 @@
+package x402
+
+// Family groups networks sharing an account model and signature format
+type Family string
+
+const (
+	FamilyEVM Family = "evm" // Ethereum-compatible chains, paid with EIP-3009 authorizations
+	FamilySVM Family = "svm" // Solana, paid with partially signed SPL token transfers
+)
+
+// networkInfo describes a network riders may pay on
+type networkInfo struct {
+	family   Family
+	chainID  int64  // EIP-155 chain ID, EVM only
+	usdcName string // EIP-712 domain name of the USDC contract, EVM only
+}
+
+// knownNetworks lists the supported networks under both their v1 names and their v2 CAIP-2 identifiers
+var knownNetworks = map[string]networkInfo{
+	"base":           {family: FamilyEVM, chainID: 8453, usdcName: "USD Coin"},
+	"base-sepolia":   {family: FamilyEVM, chainID: 84532, usdcName: "USDC"},
+	"avalanche":      {family: FamilyEVM, chainID: 43114, usdcName: "USD Coin"},
+	"avalanche-fuji": {family: FamilyEVM, chainID: 43113, usdcName: "USD Coin"},
+	"polygon":        {family: FamilyEVM, chainID: 137, usdcName: "USD Coin"},
+	"polygon-amoy":   {family: FamilyEVM, chainID: 80002, usdcName: "USDC"},
+	"eip155:8453":    {family: FamilyEVM, chainID: 8453, usdcName: "USD Coin"},
+	"eip155:84532":   {family: FamilyEVM, chainID: 84532, usdcName: "USDC"},
+	"eip155:43114":   {family: FamilyEVM, chainID: 43114, usdcName: "USD Coin"},
+	"eip155:43113":   {family: FamilyEVM, chainID: 43113, usdcName: "USD Coin"},
+	"eip155:137":     {family: FamilyEVM, chainID: 137, usdcName: "USD Coin"},
+	"eip155:80002":   {family: FamilyEVM, chainID: 80002, usdcName: "USDC"},
+	"solana":         {family: FamilySVM},
+	"solana-devnet":  {family: FamilySVM},
+	"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp": {family: FamilySVM},
+	"solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1": {family: FamilySVM},
+}
+
+// NetworkFamily returns the family of a supported network, or false for networks riders cannot pay on
+func NetworkFamily(network string) (Family, bool) {
+	info, ok := knownNetworks[network]
+	return info.family, ok
+}
@@ -0,0 +1,89 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// reserveNonce claims the nonce of an exact EVM payload and returns the function releasing it
+// again. Payloads without an ERC-3009 nonce, and a nil store, reserve nothing.
+func reserveNonce(ctx context.Context, store application.NonceStore, payload *PaymentPayload) (func(), error) {
+	exact, ok := payload.Payload.(*ExactEvmPayload)
+	if store == nil || !ok || exact.Authorization == nil {
+		return func() {}, nil
+	}
+
+	auth := exact.Authorization
+	from, nonce := strings.ToLower(auth.From), strings.ToLower(auth.Nonce)
+	// PreVerify has already checked validBefore; after it the token contract refuses the authorization anyway
+	validBefore, err := parseUint256(auth.ValidBefore)
+	if err != nil || !validBefore.IsInt64() {
+		return nil, invalid(ReasonInvalidPayload, "validBefore: %s", auth.ValidBefore)
+	}
+
+	if err := store.Reserve(ctx, from, nonce, time.Unix(validBefore.Int64(), 0)); err != nil {
+		if errors.Is(err, domain.ErrNonceUsed) {
+			return nil, &InvalidPaymentError{Reason: ReasonNonceUsed, Detail: err.Error()}
+		}
+		return nil, fmt.Errorf("failed to reserve authorization nonce: %w", err)
+	}
+
+	return func() {
+		// release even when the request was cancelled, or the payer could never retry
+		if err := store.Release(context.WithoutCancel(ctx), from, nonce); err != nil {
+			log.Printf("x402: failed to release nonce %s of %s: %v", nonce, from, err)
+		}
+	}, nil
+}
+
+// memorySweepInterval is how many reservations a MemoryNonceStore accepts between sweeps of expired nonces
+const memorySweepInterval = 256
+
+// MemoryNonceStore is an application.NonceStore for a single instance; reservations are lost on restart
+type MemoryNonceStore struct {
+	mu       sync.Mutex
+	reserved map[string]time.Time // from:nonce -> expiry
+	reserves int
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{reserved: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) Reserve(ctx context.Context, from, nonce string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := from + ":" + nonce
+	if expiry, ok := s.reserved[key]; ok && now.Before(expiry) {
+		return fmt.Errorf("%w: %s", domain.ErrNonceUsed, nonce)
+	}
+	s.reserved[key] = expiresAt
+
+	if s.reserves++; s.reserves%memorySweepInterval == 0 {
+		for k, expiry := range s.reserved {
+			if !now.Before(expiry) {
+				delete(s.reserved, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MemoryNonceStore) Release(ctx context.Context, from, nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reserved, from+":"+nonce)
+	return nil
+}
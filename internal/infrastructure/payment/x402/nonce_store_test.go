@@ -0,0 +1,46 @@
+package x402_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	store := x402.NewMemoryNonceStore()
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := store.Reserve(ctx, "0xrider", "0x01", expiresAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Reserve(ctx, "0xrider", "0x01", expiresAt); !errors.Is(err, domain.ErrNonceUsed) {
+		t.Fatalf("expected ErrNonceUsed, got %v", err)
+	}
+	if err := store.Reserve(ctx, "0xother", "0x01", expiresAt); err != nil {
+		t.Errorf("expected the same nonce of another payer to be free, got %v", err)
+	}
+
+	if err := store.Release(ctx, "0xrider", "0x01"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Reserve(ctx, "0xrider", "0x01", expiresAt); err != nil {
+		t.Errorf("expected a released nonce to be reservable, got %v", err)
+	}
+}
+
+func TestMemoryNonceStore_Expired(t *testing.T) {
+	ctx := context.Background()
+	store := x402.NewMemoryNonceStore()
+
+	if err := store.Reserve(ctx, "0xrider", "0x01", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Reserve(ctx, "0xrider", "0x01", time.Now().Add(time.Hour)); err != nil {
+		t.Errorf("expected an expired reservation to be replaced, got %v", err)
+	}
+}
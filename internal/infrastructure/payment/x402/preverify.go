@@ -0,0 +1,211 @@
+package x402
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Reasons a payment fails local verification, matching the facilitator's invalidReason values
+const (
+	ReasonUnsupportedScheme = "unsupported_scheme"
+	ReasonInvalidNetwork    = "invalid_network"
+	ReasonInvalidPayload    = "invalid_payload"
+	ReasonRecipientMismatch = "invalid_exact_evm_payload_recipient_mismatch"
+	ReasonValueTooLow       = "invalid_exact_evm_payload_authorization_value"
+	ReasonNotYetValid       = "invalid_exact_evm_payload_authorization_valid_after"
+	ReasonExpired           = "invalid_exact_evm_payload_authorization_valid_before"
+	ReasonInvalidSignature  = "invalid_exact_evm_payload_signature"
+	ReasonNonceUsed         = "invalid_exact_evm_payload_authorization_nonce_used"
+)
+
+// validBeforeMargin leaves time to settle an authorization on-chain before it expires
+const validBeforeMargin = 6 * time.Second
+
+// EIP-712 type hashes of the token domain and of the ERC-3009 authorization signed by the payer
+var (
+	eip712DomainTypeHash              = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	transferWithAuthorizationTypeHash = keccak256([]byte("TransferWithAuthorization(address from,address to,uint256 value,uint256 validAfter,uint256 validBefore,bytes32 nonce)"))
+)
+
+// InvalidPaymentError is returned when a payment payload fails local verification
+type InvalidPaymentError struct {
+	Reason string // one of the Reason constants
+	Detail string
+}
+
+func (e *InvalidPaymentError) Error() string {
+	return e.Reason + ": " + e.Detail
+}
+
+func invalid(reason, format string, args ...any) error {
+	return &InvalidPaymentError{Reason: reason, Detail: fmt.Sprintf(format, args...)}
+}
+
+// PreVerify checks payload against requirements without a network call, so payments that can
+// never settle are rejected before reaching the facilitator. Exact EVM payments are checked for
+// recipient, value, validity window and, for 65 byte EOA signatures, the signer recovered from the
+// EIP-712 digest. Other payloads are only matched on scheme and network; the facilitator remains
+// the authority on balances, nonces and smart wallet signatures.
+func PreVerify(payload *PaymentPayload, requirements *PaymentRequirements, now time.Time) error {
+	if payload.Scheme != requirements.Scheme {
+		return invalid(ReasonUnsupportedScheme, "payment scheme %q, required %q", payload.Scheme, requirements.Scheme)
+	}
+	if payload.Network != requirements.Network {
+		return invalid(ReasonInvalidNetwork, "payment network %q, required %q", payload.Network, requirements.Network)
+	}
+
+	switch p := payload.Payload.(type) {
+	case nil:
+		return invalid(ReasonInvalidPayload, "missing payload")
+	case *ExactEvmPayload:
+		return preVerifyExactEvm(p, requirements, now)
+	default:
+		return nil
+	}
+}
+
+func preVerifyExactEvm(payload *ExactEvmPayload, requirements *PaymentRequirements, now time.Time) error {
+	auth := payload.Authorization
+	if auth == nil {
+		return invalid(ReasonInvalidPayload, "missing authorization")
+	}
+
+	from, err := decodeHex(auth.From, 20)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "from: %v", err)
+	}
+	to, err := decodeHex(auth.To, 20)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "to: %v", err)
+	}
+	payTo, err := decodeHex(requirements.PayTo, 20)
+	if err != nil || !strings.EqualFold(hex.EncodeToString(to), hex.EncodeToString(payTo)) {
+		return invalid(ReasonRecipientMismatch, "authorization pays %s, required %s", auth.To, requirements.PayTo)
+	}
+
+	value, err := parseUint256(auth.Value)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "value: %v", err)
+	}
+	required, err := parseUint256(requirements.MaxAmountRequired)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "required amount: %v", err)
+	}
+	if value.Cmp(required) < 0 {
+		return invalid(ReasonValueTooLow, "authorization value %s below required %s", value, required)
+	}
+
+	validAfter, err := parseUint256(auth.ValidAfter)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "validAfter: %v", err)
+	}
+	validBefore, err := parseUint256(auth.ValidBefore)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "validBefore: %v", err)
+	}
+	if validAfter.Cmp(big.NewInt(now.Unix())) > 0 {
+		return invalid(ReasonNotYetValid, "authorization valid after %s", validAfter)
+	}
+	if validBefore.Cmp(big.NewInt(now.Add(validBeforeMargin).Unix())) < 0 {
+		return invalid(ReasonExpired, "authorization expired at %s", validBefore)
+	}
+
+	nonce, err := decodeHex(auth.Nonce, 32)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "nonce: %v", err)
+	}
+	signature, err := decodeHex(payload.Signature, -1)
+	if err != nil {
+		return invalid(ReasonInvalidSignature, "signature: %v", err)
+	}
+	if len(signature) != 65 {
+		// ERC-1271 and ERC-6492 smart wallet signatures are checked on-chain by the facilitator
+		return nil
+	}
+
+	digest, err := authorizationDigest(requirements, from, to, value, validAfter, validBefore, nonce)
+	if err != nil {
+		return invalid(ReasonInvalidPayload, "%v", err)
+	}
+	signer, err := recoverAddress(digest, signature)
+	if err != nil {
+		return invalid(ReasonInvalidSignature, "%v", err)
+	}
+	if hex.EncodeToString(signer) != hex.EncodeToString(from) {
+		return invalid(ReasonInvalidSignature, "signed by 0x%x, not %s", signer, auth.From)
+	}
+	return nil
+}
+
+// authorizationDigest returns the EIP-712 digest of a TransferWithAuthorization on the requirements' asset
+func authorizationDigest(requirements *PaymentRequirements, from, to []byte, value, validAfter, validBefore *big.Int, nonce []byte) ([]byte, error) {
+	info := knownNetworks[requirements.Network]
+	if info.family != FamilyEVM || info.chainID == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedNetwork, requirements.Network)
+	}
+	asset, err := decodeHex(requirements.Asset, 20)
+	if err != nil {
+		return nil, fmt.Errorf("asset: %w", err)
+	}
+
+	// the token's EIP-712 domain is announced in Extra; USDC deployments default to version 2
+	domain := struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}{Name: info.usdcName, Version: "2"}
+	if requirements.Extra != nil {
+		if err := json.Unmarshal(*requirements.Extra, &domain); err != nil {
+			return nil, fmt.Errorf("extra: %w", err)
+		}
+	}
+
+	domainSeparator := keccak256(
+		eip712DomainTypeHash,
+		keccak256([]byte(domain.Name)),
+		keccak256([]byte(domain.Version)),
+		word(big.NewInt(info.chainID).Bytes()),
+		word(asset),
+	)
+	structHash := keccak256(
+		transferWithAuthorizationTypeHash,
+		word(from),
+		word(to),
+		word(value.Bytes()),
+		word(validAfter.Bytes()),
+		word(validBefore.Bytes()),
+		nonce,
+	)
+	return keccak256([]byte{0x19, 0x01}, domainSeparator, structHash), nil
+}
+
+// word left-pads b to a 32 byte ABI word
+func word(b []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
+
+// decodeHex decodes a 0x-prefixed hex string of size bytes, or of any length when size is negative
+func decodeHex(s string, size int) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex %q", s)
+	}
+	if size >= 0 && len(b) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+	}
+	return b, nil
+}
+
+func parseUint256(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, errors.New("not a uint256: " + s)
+	}
+	return n, nil
+}
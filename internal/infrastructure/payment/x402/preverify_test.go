@@ -0,0 +1,209 @@
+package x402
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	riderKey := new(big.Int).SetBytes(keccak256([]byte("rider wallet")))
+	otherKey := new(big.Int).SetBytes(keccak256([]byte("someone else")))
+
+	newRequirements := func() *PaymentRequirements {
+		return &PaymentRequirements{
+			Scheme:            SchemeExact,
+			Network:           "base-sepolia",
+			MaxAmountRequired: "18500000",
+			PayTo:             "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+			Asset:             "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		}
+	}
+	// signed builds a payload for requirements with the authorization adjusted by edit before signing with key
+	signed := func(t *testing.T, requirements *PaymentRequirements, key *big.Int, edit func(*ExactEvmPayloadAuthorization)) *PaymentPayload {
+		auth := &ExactEvmPayloadAuthorization{
+			From:        "0x" + hex.EncodeToString(addressOf(riderKey)),
+			To:          requirements.PayTo,
+			Value:       requirements.MaxAmountRequired,
+			ValidAfter:  "0",
+			ValidBefore: "1772370000", // 2026-03-01 13:00 UTC
+			Nonce:       "0x" + hex.EncodeToString(keccak256([]byte("nonce-1"))),
+		}
+		if edit != nil {
+			edit(auth)
+		}
+		from, _ := decodeHex(auth.From, 20)
+		to, _ := decodeHex(auth.To, 20)
+		value, _ := parseUint256(auth.Value)
+		validAfter, _ := parseUint256(auth.ValidAfter)
+		validBefore, _ := parseUint256(auth.ValidBefore)
+		nonce, _ := decodeHex(auth.Nonce, 32)
+		digest, err := authorizationDigest(requirements, from, to, value, validAfter, validBefore, nonce)
+		if err != nil {
+			t.Fatalf("failed to build digest: %v", err)
+		}
+		return &PaymentPayload{
+			X402Version: ProtocolV1,
+			Scheme:      requirements.Scheme,
+			Network:     requirements.Network,
+			Payload:     &ExactEvmPayload{Signature: "0x" + hex.EncodeToString(signDigest(t, key, digest)), Authorization: auth},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		payload    func(t *testing.T, requirements *PaymentRequirements) *PaymentPayload
+		wantReason string
+	}{
+		{
+			name:    "valid",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload { return signed(t, r, riderKey, nil) },
+		},
+		{
+			name: "overpayment",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.Value = "20000000" })
+			},
+		},
+		{
+			name: "recipient in other case",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.To = strings.ToLower(a.To) })
+			},
+		},
+		{
+			name: "smart wallet signature left to facilitator",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				payload := signed(t, r, riderKey, nil)
+				payload.Payload.(*ExactEvmPayload).Signature = "0x" + strings.Repeat("ab", 200)
+				return payload
+			},
+		},
+		{
+			name: "other scheme",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				payload := signed(t, r, riderKey, nil)
+				payload.Scheme = "upto"
+				return payload
+			},
+			wantReason: ReasonUnsupportedScheme,
+		},
+		{
+			name: "other network",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				payload := signed(t, r, riderKey, nil)
+				payload.Network = "base"
+				return payload
+			},
+			wantReason: ReasonInvalidNetwork,
+		},
+		{
+			name: "missing authorization",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return &PaymentPayload{Scheme: r.Scheme, Network: r.Network, Payload: &ExactEvmPayload{Signature: "0x00"}}
+			},
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name: "malformed sender",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				payload := signed(t, r, riderKey, nil)
+				payload.Payload.(*ExactEvmPayload).Authorization.From = "0xrider"
+				return payload
+			},
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name: "recipient mismatch",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.To = "0x" + hex.EncodeToString(addressOf(otherKey)) })
+			},
+			wantReason: ReasonRecipientMismatch,
+		},
+		{
+			name: "value below required",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.Value = "18499999" })
+			},
+			wantReason: ReasonValueTooLow,
+		},
+		{
+			name: "not yet valid",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.ValidAfter = "1772366460" })
+			},
+			wantReason: ReasonNotYetValid,
+		},
+		{
+			name: "expired",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.ValidBefore = "1772366399" })
+			},
+			wantReason: ReasonExpired,
+		},
+		{
+			name: "expires before it can settle",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				return signed(t, r, riderKey, func(a *ExactEvmPayloadAuthorization) { a.ValidBefore = "1772366403" })
+			},
+			wantReason: ReasonExpired,
+		},
+		{
+			name:       "signed by someone else",
+			payload:    func(t *testing.T, r *PaymentRequirements) *PaymentPayload { return signed(t, r, otherKey, nil) },
+			wantReason: ReasonInvalidSignature,
+		},
+		{
+			name: "value changed after signing",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				payload := signed(t, r, riderKey, nil)
+				payload.Payload.(*ExactEvmPayload).Authorization.Value = "99000000"
+				return payload
+			},
+			wantReason: ReasonInvalidSignature,
+		},
+		{
+			name: "signed for another token",
+			payload: func(t *testing.T, r *PaymentRequirements) *PaymentPayload {
+				other := *r
+				extra := json.RawMessage(`{"name":"USD Coin","version":"2"}`)
+				other.Extra = &extra
+				return signed(t, &other, riderKey, nil)
+			},
+			wantReason: ReasonInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirements := newRequirements()
+
+			err := PreVerify(tt.payload(t, requirements), requirements, now)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var invalidErr *InvalidPaymentError
+			if !errors.As(err, &invalidErr) || invalidErr.Reason != tt.wantReason {
+				t.Fatalf("expected reason %s, got %v", tt.wantReason, err)
+			}
+		})
+	}
+}
+
+func TestPreVerify_SolanaLeftToFacilitator(t *testing.T) {
+	requirements := &PaymentRequirements{Scheme: SchemeExact, Network: "solana-devnet", MaxAmountRequired: "10000", PayTo: "platformSolanaWallet"}
+	payload := &PaymentPayload{Scheme: SchemeExact, Network: "solana-devnet", Payload: &ExactSvmPayload{Transaction: "AQAB"}}
+
+	if err := PreVerify(payload, requirements, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
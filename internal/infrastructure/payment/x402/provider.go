@@ -0,0 +1,408 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// ProviderName identifies x402 as the provider of a payment
+const ProviderName = "x402"
+
+// usdcUnitsPerCent converts US cents to USDC atomic units (USDC has 6 decimals)
+const usdcUnitsPerCent = 10_000
+
+// ProviderConfig configures the x402 payment provider
+type ProviderConfig struct {
+	Networks          []NetworkConfig `json:"networks"`          // chains riders may pay on, in order of preference
+	ResourceURL       string          `json:"resourceURL"`       // base URL identifying what is paid for
+	MaxTimeoutSeconds int             `json:"maxTimeoutSeconds"` // how long the signed authorization stays usable
+	ProtocolVersion   int             `json:"protocolVersion"`   // x402 version sessions are encoded in, 1 when zero
+}
+
+// NetworkConfig configures payments on one network
+type NetworkConfig struct {
+	Network  string `json:"network"`            // e.g. "base", "base-sepolia" or "solana"
+	PayTo    string `json:"payTo"`              // platform wallet receiving the fares on Network
+	Asset    string `json:"asset"`              // USDC token contract or mint address on Network
+	FeePayer string `json:"feePayer,omitempty"` // Solana only, required: facilitator account paying the transaction fee
+}
+
+// Facilitator verifies and settles x402 payments (allows mocking in tests)
+type Facilitator interface {
+	VerifyContext(ctx context.Context, payload *PaymentPayload, requirements *PaymentRequirements) (*VerifyResponse, error)
+	SettleContext(ctx context.Context, payload *PaymentPayload, requirements *PaymentRequirements) (*SettleResponse, error)
+}
+
+// Provider implements application.CryptoPaymentProvider for USDC payments over x402
+type Provider struct {
+	config      ProviderConfig
+	facilitator Facilitator
+	nonces      application.NonceStore
+	chain       ChainReader
+	now         func() time.Time
+}
+
+// NewProvider creates a new x402 payment provider settling through the given facilitator.
+// Authorization nonces are reserved in nonces for the time a payment is being settled, and settlements
+// whose outcome was lost are looked up on chain; without a chain reader they cannot be looked up.
+func NewProvider(config ProviderConfig, facilitator Facilitator, nonces application.NonceStore, chain ChainReader) *Provider {
+	return &Provider{config: config, facilitator: facilitator, nonces: nonces, chain: chain, now: time.Now}
+}
+
+// Name returns the provider name stored on payments
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// CreatePaymentSession builds the payment requirements for the fare on every configured network
+// and returns them base64 encoded in the configured protocol version. Amount is in US cents; the
+// rider pays the same amount in USDC.
+func (p *Provider) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	if !strings.EqualFold(currency, "usd") {
+		return "", fmt.Errorf("x402 payments are settled in USDC, unsupported fare currency %s", currency)
+	}
+	if amount <= 0 {
+		return "", fmt.Errorf("invalid fare amount %d", amount)
+	}
+	if len(p.config.Networks) == 0 {
+		return "", errors.New("no x402 networks configured")
+	}
+
+	accepts := make([]*PaymentRequirements, 0, len(p.config.Networks))
+	for _, network := range p.config.Networks {
+		requirements := &PaymentRequirements{
+			Scheme:            SchemeExact,
+			Network:           network.Network,
+			MaxAmountRequired: strconv.FormatInt(amount*usdcUnitsPerCent, 10),
+			Resource:          strings.TrimRight(p.config.ResourceURL, "/") + "/payments/" + metadata["payment_id"],
+			Description:       "Ride fare for trip " + metadata["trip_id"],
+			MimeType:          "application/json",
+			PayTo:             network.PayTo,
+			MaxTimeoutSeconds: p.config.MaxTimeoutSeconds,
+			Asset:             network.Asset,
+		}
+		if err := setAssetInfo(requirements, network); err != nil {
+			return "", err
+		}
+		accepts = append(accepts, requirements)
+	}
+
+	if p.config.ProtocolVersion == ProtocolV2 {
+		return EncodePaymentRequiredV2(accepts)
+	}
+	return EncodeAccepts(accepts)
+}
+
+// SettlePayment verifies the rider's payment payload against the session's requirements for the
+// network the rider paid on and settles it on-chain
+func (p *Provider) SettlePayment(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	accepts, err := DecodeAccepts(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := DecodePaymentPayloadFromBase64(paymentPayload)
+	if err != nil {
+		return nil, rejected("decode payment", err)
+	}
+	requirements := MatchRequirements(accepts, payload)
+	if requirements == nil {
+		return nil, rejected("match payment", fmt.Errorf("%w: %s %s", ErrUnsupportedNetwork, payload.Scheme, payload.Network))
+	}
+	if err := PreVerify(payload, requirements, p.now()); err != nil {
+		return nil, rejected("verify payment", err)
+	}
+
+	release, err := reserveNonce(ctx, p.nonces, payload)
+	if err != nil {
+		var invalidErr *InvalidPaymentError
+		if errors.As(err, &invalidErr) {
+			return nil, rejected("verify payment", err)
+		}
+		return nil, err
+	}
+	settleResp, err := p.verifyAndSettle(ctx, payload, requirements)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	settlement := &application.CryptoSettlement{
+		TransactionHash: settleResp.Transaction,
+		Network:         settleResp.Network,
+	}
+	if settlement.Network == "" {
+		settlement.Network = requirements.Network
+	}
+	if settleResp.Payer != nil {
+		settlement.Payer = *settleResp.Payer
+	}
+	return settlement, nil
+}
+
+// FindSettlement looks up the transaction in which an exact EVM payload was settled. It returns nil
+// while the authorization is unused and still valid, and ErrPaymentDeclined once it expired unused.
+// Other payloads cannot be looked up and return errors.ErrUnsupported.
+func (p *Provider) FindSettlement(ctx context.Context, sessionID string, paymentPayload string) (*application.CryptoSettlement, error) {
+	accepts, err := DecodeAccepts(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := DecodePaymentPayloadFromBase64(paymentPayload)
+	if err != nil {
+		return nil, rejected("decode payment", err)
+	}
+	requirements := MatchRequirements(accepts, payload)
+	if requirements == nil {
+		return nil, rejected("match payment", fmt.Errorf("%w: %s %s", ErrUnsupportedNetwork, payload.Scheme, payload.Network))
+	}
+	exact, ok := payload.Payload.(*ExactEvmPayload)
+	if !ok || exact.Authorization == nil || p.chain == nil {
+		return nil, fmt.Errorf("x402 settlement lookup on %s: %w", requirements.Network, errors.ErrUnsupported)
+	}
+
+	auth := exact.Authorization
+	transactionHash, err := p.chain.FindAuthorization(ctx, requirements.Network, requirements.Asset, auth.From, auth.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up settlement: %w", err)
+	}
+	if transactionHash != "" {
+		return &application.CryptoSettlement{TransactionHash: transactionHash, Network: requirements.Network, Payer: auth.From}, nil
+	}
+
+	validBefore, err := parseUint256(auth.ValidBefore)
+	if err != nil || !validBefore.IsInt64() {
+		return nil, rejected("find settlement", invalid(ReasonInvalidPayload, "validBefore: %s", auth.ValidBefore))
+	}
+	if !p.now().Before(time.Unix(validBefore.Int64(), 0)) {
+		return nil, rejected("find settlement", errors.New("authorization expired without being settled"))
+	}
+	return nil, nil
+}
+
+func (p *Provider) verifyAndSettle(ctx context.Context, payload *PaymentPayload, requirements *PaymentRequirements) (*SettleResponse, error) {
+	verifyResp, err := p.facilitator.VerifyContext(ctx, payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyResp.IsValid {
+		return nil, declined("verify payment", verifyResp.InvalidReason)
+	}
+
+	settleResp, err := p.facilitator.SettleContext(ctx, payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+	if !settleResp.Success {
+		return nil, declined("settle payment", settleResp.ErrorReason)
+	}
+	return settleResp, nil
+}
+
+// CreateAuthorizationSession is not supported: x402 transfers settle immediately
+func (p *Provider) CreateAuthorizationSession(ctx context.Context, amount int64, currency string, metadata map[string]string, idempotencyKey string) (string, error) {
+	return "", fmt.Errorf("x402 authorization holds: %w", errors.ErrUnsupported)
+}
+
+// CapturePayment is not supported: x402 transfers settle immediately
+func (p *Provider) CapturePayment(ctx context.Context, providerPaymentID string, amount int64, idempotencyKey string) error {
+	return fmt.Errorf("x402 captures: %w", errors.ErrUnsupported)
+}
+
+// CancelPayment is not supported: x402 transfers settle immediately
+func (p *Provider) CancelPayment(ctx context.Context, providerPaymentID string, idempotencyKey string) error {
+	return fmt.Errorf("x402 cancellations: %w", errors.ErrUnsupported)
+}
+
+// ExpirePaymentSession has nothing to expire: the session only carries the requirements, and the
+// payment service refuses payloads for payments that are no longer open
+func (p *Provider) ExpirePaymentSession(ctx context.Context, sessionID string, idempotencyKey string) error {
+	return nil
+}
+
+// RefundPayment is not supported: on-chain refunds are sent from the platform wallet outside the protocol
+func (p *Provider) RefundPayment(ctx context.Context, providerPaymentID string, amount int64, reason string, idempotencyKey string) (string, error) {
+	return "", fmt.Errorf("x402 refunds: %w", errors.ErrUnsupported)
+}
+
+// EncodePaymentRequirements encodes requirements as base64 JSON, the form handed to the rider's wallet
+func EncodePaymentRequirements(requirements *PaymentRequirements) (string, error) {
+	jsonBytes, err := json.Marshal(requirements)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payment requirements: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+}
+
+// DecodePaymentRequirements decodes requirements produced by EncodePaymentRequirements
+func DecodePaymentRequirements(encoded string) (*PaymentRequirements, error) {
+	decodedBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 string: %w", err)
+	}
+
+	var requirements PaymentRequirements
+	if err := json.Unmarshal(decodedBytes, &requirements); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payment requirements: %w", err)
+	}
+	return &requirements, nil
+}
+
+// EncodeAccepts encodes the requirements a payment may be made against as base64 JSON
+func EncodeAccepts(accepts []*PaymentRequirements) (string, error) {
+	jsonBytes, err := json.Marshal(accepts)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payment requirements: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+}
+
+// EncodePaymentRequiredV2 encodes the requirements a payment may be made against as a base64 JSON
+// v2 PaymentRequired
+func EncodePaymentRequiredV2(accepts []*PaymentRequirements) (string, error) {
+	jsonBytes, err := json.Marshal(NewPaymentRequiredV2(accepts, ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payment requirements: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+}
+
+// DecodeAccepts decodes requirements produced by EncodeAccepts, by EncodePaymentRequiredV2 or, for
+// sessions created before riders could choose a network, by EncodePaymentRequirements
+func DecodeAccepts(encoded string) ([]*PaymentRequirements, error) {
+	decodedBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 string: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(decodedBytes); len(trimmed) > 0 && trimmed[0] == '{' {
+		var versioned struct {
+			X402Version int `json:"x402Version"`
+		}
+		if err := json.Unmarshal(decodedBytes, &versioned); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payment requirements: %w", err)
+		}
+		if versioned.X402Version == ProtocolV2 {
+			var required PaymentRequiredV2
+			if err := json.Unmarshal(decodedBytes, &required); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal payment requirements: %w", err)
+			}
+			accepts := make([]*PaymentRequirements, 0, len(required.Accepts))
+			for _, requirements := range required.Accepts {
+				accepts = append(accepts, requirements.requirements(required.Resource))
+			}
+			return accepts, nil
+		}
+
+		var requirements PaymentRequirements
+		if err := json.Unmarshal(decodedBytes, &requirements); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payment requirements: %w", err)
+		}
+		return []*PaymentRequirements{&requirements}, nil
+	}
+
+	var accepts []*PaymentRequirements
+	if err := json.Unmarshal(decodedBytes, &accepts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payment requirements: %w", err)
+	}
+	return accepts, nil
+}
+
+// MatchRequirements returns the requirements paid for by payload, or nil when its scheme and network
+// are not accepted. A v2 payload names the requirements it accepted, which must be the ones offered.
+func MatchRequirements(accepts []*PaymentRequirements, payload *PaymentPayload) *PaymentRequirements {
+	for _, requirements := range accepts {
+		if requirements.Scheme != payload.Scheme || requirements.Network != payload.Network {
+			continue
+		}
+		if payload.Accepted != nil && !sameRequirements(requirements.V2(), payload.Accepted) {
+			continue
+		}
+		return requirements
+	}
+	return nil
+}
+
+// sameRequirements reports whether the client accepted exactly the offered requirements.
+// EVM addresses are compared case-insensitively, as their checksum casing is optional.
+func sameRequirements(offered, accepted *PaymentRequirementsV2) bool {
+	sameAddress := func(a, b string) bool { return a == b }
+	if family, _ := NetworkFamily(offered.Network); family == FamilyEVM {
+		sameAddress = strings.EqualFold
+	}
+	return offered.Scheme == accepted.Scheme &&
+		offered.Network == accepted.Network &&
+		offered.Amount == accepted.Amount &&
+		offered.MaxTimeoutSeconds == accepted.MaxTimeoutSeconds &&
+		sameAddress(offered.Asset, accepted.Asset) &&
+		sameAddress(offered.PayTo, accepted.PayTo) &&
+		sameJSON(offered.Extra, accepted.Extra)
+}
+
+// sameJSON reports whether a and b encode the same value, ignoring formatting and key order
+func sameJSON(a, b *json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	var va, vb any
+	if json.Unmarshal(*a, &va) != nil || json.Unmarshal(*b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// setAssetInfo sets the network-specific Extra the rider's wallet needs to sign the payment
+func setAssetInfo(requirements *PaymentRequirements, network NetworkConfig) error {
+	family, ok := NetworkFamily(network.Network)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedNetwork, network.Network)
+	}
+
+	var extra map[string]any
+	switch family {
+	case FamilyEVM:
+		extra = map[string]any{"name": knownNetworks[network.Network].usdcName, "version": "2"}
+	case FamilySVM:
+		if network.FeePayer == "" {
+			return fmt.Errorf("x402 network %s requires the facilitator's fee payer", network.Network)
+		}
+		extra = map[string]any{"feePayer": network.FeePayer}
+	}
+
+	jsonBytes, err := json.Marshal(extra)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset info: %w", err)
+	}
+	rawMessage := json.RawMessage(jsonBytes)
+	requirements.Extra = &rawMessage
+	return nil
+}
+
+// declined reports the facilitator's refusal of the rider's payment as domain.ErrPaymentDeclined
+func declined(op string, r *string) error {
+	return &domain.ProviderError{Provider: ProviderName, Op: op, Err: domain.ErrPaymentDeclined, Cause: errors.New(reason(r))}
+}
+
+// rejected reports a payment payload that can never be settled as domain.ErrPaymentDeclined
+func rejected(op string, err error) error {
+	return &domain.ProviderError{Provider: ProviderName, Op: op, Err: domain.ErrPaymentDeclined, Cause: err}
+}
+
+func reason(r *string) string {
+	if r == nil || *r == "" {
+		return "unknown reason"
+	}
+	return *r
+}
@@ -0,0 +1,468 @@
+package x402_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+)
+
+// Platform wallet and USDC contract on Base Sepolia that encodedTestPayload is signed for
+const (
+	testPayTo = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+	testAsset = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+)
+
+func testProviderConfig() x402.ProviderConfig {
+	return x402.ProviderConfig{
+		Networks: []x402.NetworkConfig{
+			{Network: "base-sepolia", PayTo: testPayTo, Asset: testAsset},
+			{Network: "solana-devnet", PayTo: "platformSolanaWallet", Asset: "usdcMint", FeePayer: "facilitatorFeePayer"},
+		},
+		ResourceURL:       "https://api.ride4low.test/",
+		MaxTimeoutSeconds: 300,
+	}
+}
+
+func encodedTestPayload(t *testing.T) string {
+	t.Helper()
+	payload, err := json.Marshal(&x402.PaymentPayload{
+		X402Version: 1,
+		Scheme:      "exact",
+		Network:     "base-sepolia",
+		Payload: &x402.ExactEvmPayload{
+			// EIP-712 signature over the authorization by the rider's key, keccak256("rider wallet")
+			Signature: "0x9c4317018830726ef11bbfc7057ea117cea6329c6f2ee1b641357a64eeecde3f1992c00690cb323bfa4d46890b97b9101a7a8b6ec1421a3fa3984481f28700661c",
+			Authorization: &x402.ExactEvmPayloadAuthorization{
+				From:        "0xed437cc3e8ba88dbfe3f7a912f96fb51a3ca3752",
+				To:          testPayTo,
+				Value:       "18500000",
+				ValidAfter:  "0",
+				ValidBefore: "4102444800",
+				Nonce:       "0x9c6230254ac733f54ec47298f0a5ddaf93dc9efe6e05fb726dcb6faf10ddece2",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(payload)
+}
+
+func TestProvider_CreatePaymentSession(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, nil)
+
+	sessionID, err := provider.CreatePaymentSession(context.Background(), 1850, "USD", map[string]string{"payment_id": "payment-1", "trip_id": "trip-1"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	accepts, err := x402.DecodeAccepts(sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(accepts) != 2 {
+		t.Fatalf("expected requirements for both networks, got %d", len(accepts))
+	}
+	requirements := accepts[0]
+
+	// 18.50 USD in USDC atomic units (6 decimals)
+	if requirements.MaxAmountRequired != "18500000" {
+		t.Errorf("expected 18500000, got %s", requirements.MaxAmountRequired)
+	}
+	if requirements.Resource != "https://api.ride4low.test/payments/payment-1" {
+		t.Errorf("unexpected resource %s", requirements.Resource)
+	}
+	if requirements.PayTo != testPayTo || requirements.Asset != testAsset || requirements.Scheme != "exact" {
+		t.Errorf("unexpected requirements: %+v", requirements)
+	}
+	if requirements.Extra == nil || string(*requirements.Extra) != `{"name":"USDC","version":"2"}` {
+		t.Errorf("expected USDC EIP-712 domain in extra, got %v", requirements.Extra)
+	}
+
+	solana := accepts[1]
+	if solana.Network != "solana-devnet" || solana.PayTo != "platformSolanaWallet" || solana.Asset != "usdcMint" || solana.MaxAmountRequired != "18500000" {
+		t.Errorf("unexpected Solana requirements: %+v", solana)
+	}
+	if solana.Extra == nil || string(*solana.Extra) != `{"feePayer":"facilitatorFeePayer"}` {
+		t.Errorf("expected fee payer in extra, got %v", solana.Extra)
+	}
+}
+
+func TestProvider_CreatePaymentSession_UnsupportedNetwork(t *testing.T) {
+	config := testProviderConfig()
+	config.Networks = []x402.NetworkConfig{{Network: "dogechain", PayTo: "0xplatformWallet", Asset: "0xusdcAddress"}}
+	provider := x402.NewProvider(config, nil, nil, nil)
+
+	if _, err := provider.CreatePaymentSession(context.Background(), 1850, "USD", nil, ""); !errors.Is(err, x402.ErrUnsupportedNetwork) {
+		t.Fatalf("expected ErrUnsupportedNetwork, got %v", err)
+	}
+}
+
+func TestProvider_CreatePaymentSession_SolanaRequiresFeePayer(t *testing.T) {
+	config := testProviderConfig()
+	config.Networks[1].FeePayer = ""
+	provider := x402.NewProvider(config, nil, nil, nil)
+
+	if _, err := provider.CreatePaymentSession(context.Background(), 1850, "USD", nil, ""); err == nil {
+		t.Fatal("expected error for a Solana network without a fee payer")
+	}
+}
+
+func TestProvider_CreatePaymentSession_UnsupportedCurrency(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, nil)
+
+	if _, err := provider.CreatePaymentSession(context.Background(), 1850, "EUR", nil, ""); err == nil {
+		t.Fatal("expected error for a non-USD fare")
+	}
+}
+
+func TestProvider_SettlePayment(t *testing.T) {
+	var settled bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			PaymentRequirements x402.PaymentRequirements `json:"paymentRequirements"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.PaymentRequirements.MaxAmountRequired != "18500000" {
+			t.Errorf("expected session requirements to be sent, got %+v", body.PaymentRequirements)
+		}
+
+		switch r.URL.Path {
+		case "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			settled = true
+			payer := "0xrider"
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia", Payer: &payer})
+		}
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", map[string]string{"payment_id": "payment-1"}, "")
+
+	settlement, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !settled {
+		t.Error("expected payment to be settled")
+	}
+	if settlement.TransactionHash != "0xtxhash" || settlement.Payer != "0xrider" {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+}
+
+func TestProvider_SettlePayment_Solana(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			X402Version         int                      `json:"x402Version"`
+			PaymentRequirements x402.PaymentRequirements `json:"paymentRequirements"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.X402Version != 2 {
+			t.Errorf("expected the payload's version 2, got %d", body.X402Version)
+		}
+		if body.PaymentRequirements.Network != "solana-devnet" || body.PaymentRequirements.PayTo != "platformSolanaWallet" {
+			t.Errorf("expected the Solana requirements, got %+v", body.PaymentRequirements)
+		}
+
+		switch r.URL.Path {
+		case "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "5solanaSignature"})
+		}
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+	payment := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"solana-devnet","amount":"18500000","asset":"usdcMint","payTo":"platformSolanaWallet","maxTimeoutSeconds":300,"extra":{"feePayer":"facilitatorFeePayer"}},"payload":{"transaction":"AQAB"}}`))
+
+	settlement, err := provider.SettlePayment(context.Background(), sessionID, payment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settlement.TransactionHash != "5solanaSignature" || settlement.Network != "solana-devnet" {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+}
+
+func TestProvider_ProtocolV2RoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			X402Version         int            `json:"x402Version"`
+			PaymentPayload      map[string]any `json:"paymentPayload"`
+			PaymentRequirements map[string]any `json:"paymentRequirements"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.X402Version != 2 || body.PaymentRequirements["amount"] != "18500000" || body.PaymentRequirements["maxAmountRequired"] != nil {
+			t.Errorf("expected v2 requirements, got version %d %v", body.X402Version, body.PaymentRequirements)
+		}
+		if body.PaymentPayload["accepted"] == nil || body.PaymentPayload["scheme"] != nil {
+			t.Errorf("expected a v2 payload, got %v", body.PaymentPayload)
+		}
+
+		switch r.URL.Path {
+		case "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"})
+		}
+	}))
+	defer server.Close()
+
+	config := testProviderConfig()
+	config.ProtocolVersion = x402.ProtocolV2
+	provider := x402.NewProvider(config, x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, err := provider.CreatePaymentSession(context.Background(), 1850, "usd", map[string]string{"payment_id": "payment-1"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The wallet receives a v2 PaymentRequired and pays against the requirements it accepts
+	decoded, _ := base64.StdEncoding.DecodeString(sessionID)
+	var required x402.PaymentRequiredV2
+	if err := json.Unmarshal(decoded, &required); err != nil {
+		t.Fatalf("failed to decode session: %v", err)
+	}
+	if required.X402Version != 2 || required.Resource == nil || required.Resource.URL != "https://api.ride4low.test/payments/payment-1" {
+		t.Fatalf("unexpected PaymentRequired: %s", decoded)
+	}
+	if len(required.Accepts) != 2 || required.Accepts[0].Amount != "18500000" {
+		t.Fatalf("expected v2 requirements for both networks, got %s", decoded)
+	}
+
+	payload, err := x402.DecodePaymentPayloadFromBase64(encodedTestPayload(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload.X402Version, payload.Resource, payload.Accepted = x402.ProtocolV2, required.Resource, required.Accepts[0]
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+
+	settlement, err := provider.SettlePayment(context.Background(), sessionID, base64.StdEncoding.EncodeToString(encoded))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settlement.TransactionHash != "0xtxhash" {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+}
+
+func TestProvider_SettlePayment_AcceptedOtherRequirements(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+
+	// the wallet claims to have accepted a tenth of the fare, paid to another wallet
+	payment := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":2,"accepted":{"scheme":"exact","network":"solana-devnet","amount":"1850000","asset":"usdcMint","payTo":"otherWallet","maxTimeoutSeconds":300,"extra":{"feePayer":"facilitatorFeePayer"}},"payload":{"transaction":"AQAB"}}`))
+
+	if _, err := provider.SettlePayment(context.Background(), sessionID, payment); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+}
+
+func TestProvider_SettlePayment_NetworkNotAccepted(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+	payment := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":1,"scheme":"exact","network":"base","payload":{"signature":"0xsig","authorization":{"from":"0xrider"}}}`))
+
+	_, err := provider.SettlePayment(context.Background(), sessionID, payment)
+	if !errors.Is(err, domain.ErrPaymentDeclined) || !errors.Is(err, x402.ErrUnsupportedNetwork) {
+		t.Fatalf("expected a declined payment on an unaccepted network, got %v", err)
+	}
+}
+
+func TestProvider_SettlePayment_LegacySession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"})
+		}
+	}))
+	defer server.Close()
+
+	// sessions created before multi-network support hold a single requirements object
+	sessionID, _ := x402.EncodePaymentRequirements(&x402.PaymentRequirements{Scheme: "exact", Network: "base-sepolia", MaxAmountRequired: "18500000", PayTo: testPayTo, Asset: testAsset})
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+
+	if _, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProvider_SettlePayment_RejectedLocally(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no facilitator call, got %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	// a 20.00 USD fare is not covered by the 18.50 USD authorization
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 2000, "usd", nil, "")
+
+	_, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t))
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	var invalidErr *x402.InvalidPaymentError
+	if !errors.As(err, &invalidErr) || invalidErr.Reason != x402.ReasonValueTooLow {
+		t.Errorf("expected %s, got %v", x402.ReasonValueTooLow, err)
+	}
+}
+
+func TestProvider_SettlePayment_Replay(t *testing.T) {
+	var settles int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		case "/settle":
+			settles++
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"})
+		}
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+	if _, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t))
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected the replay to be declined, got %v", err)
+	}
+	var invalidErr *x402.InvalidPaymentError
+	if !errors.As(err, &invalidErr) || invalidErr.Reason != x402.ReasonNonceUsed {
+		t.Errorf("expected reason %s, got %v", x402.ReasonNonceUsed, err)
+	}
+	if settles != 1 {
+		t.Errorf("expected a single settlement, got %d", settles)
+	}
+}
+
+func TestProvider_SettlePayment_ReleasesNonceOnFailure(t *testing.T) {
+	unavailable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case unavailable:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/verify":
+			json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true})
+		default:
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtxhash", Network: "base-sepolia"})
+		}
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+	if _, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t)); !x402.IsRetryable(err) {
+		t.Fatalf("expected a retryable facilitator error, got %v", err)
+	}
+
+	unavailable = false
+	if _, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t)); err != nil {
+		t.Fatalf("expected the retry to settle, got %v", err)
+	}
+}
+
+func TestProvider_SettlePayment_Invalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/settle" {
+			t.Error("expected invalid payment not to be settled")
+		}
+		reason := "insufficient_funds"
+		json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: false, InvalidReason: &reason})
+	}))
+	defer server.Close()
+
+	provider := x402.NewProvider(testProviderConfig(), x402.NewFacilitatorClient(&x402.FacilitatorConfig{URL: server.URL}), x402.NewMemoryNonceStore(), nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+
+	_, err := provider.SettlePayment(context.Background(), sessionID, encodedTestPayload(t))
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	var providerErr *domain.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Cause.Error() != "insufficient_funds" {
+		t.Errorf("expected the facilitator's reason as cause, got %v", err)
+	}
+}
+
+func TestProvider_RefundPayment_Unsupported(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, nil)
+
+	if _, err := provider.RefundPayment(context.Background(), "0xtxhash", 100, "", ""); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected errors.ErrUnsupported, got %v", err)
+	}
+}
+
+// mockChainReader answers settlement lookups with a fixed transaction
+type mockChainReader struct {
+	transactionHash string
+	network         string
+	authorizer      string
+}
+
+func (m *mockChainReader) FindAuthorization(ctx context.Context, network, asset, authorizer, nonce string) (string, error) {
+	m.network, m.authorizer = network, authorizer
+	return m.transactionHash, nil
+}
+
+func TestProvider_FindSettlement(t *testing.T) {
+	chain := &mockChainReader{transactionHash: "0xtxhash"}
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, chain)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+
+	settlement, err := provider.FindSettlement(context.Background(), sessionID, encodedTestPayload(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settlement == nil || settlement.TransactionHash != "0xtxhash" || settlement.Network != "base-sepolia" {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+	if chain.network != "base-sepolia" || chain.authorizer != "0xed437cc3e8ba88dbfe3f7a912f96fb51a3ca3752" {
+		t.Errorf("expected the rider's authorization to be looked up on base-sepolia, got %s %s", chain.network, chain.authorizer)
+	}
+}
+
+func TestProvider_FindSettlement_NotSettled(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, &mockChainReader{})
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+
+	// Still valid, so the settlement may yet be mined
+	settlement, err := provider.FindSettlement(context.Background(), sessionID, encodedTestPayload(t))
+	if err != nil || settlement != nil {
+		t.Fatalf("expected no settlement yet, got %+v %v", settlement, err)
+	}
+
+	expired := base64.StdEncoding.EncodeToString([]byte(`{"x402Version":1,"scheme":"exact","network":"base-sepolia","payload":{"signature":"0xsig","authorization":{"from":"0xrider","to":"` + testPayTo + `","value":"18500000","validAfter":"0","validBefore":"1","nonce":"0x01"}}}`))
+	if _, err := provider.FindSettlement(context.Background(), sessionID, expired); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("expected an expired authorization to be declined, got %v", err)
+	}
+}
+
+func TestProvider_FindSettlement_Unsupported(t *testing.T) {
+	provider := x402.NewProvider(testProviderConfig(), nil, nil, nil)
+	sessionID, _ := provider.CreatePaymentSession(context.Background(), 1850, "usd", nil, "")
+
+	if _, err := provider.FindSettlement(context.Background(), sessionID, encodedTestPayload(t)); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported without a chain reader, got %v", err)
+	}
+}
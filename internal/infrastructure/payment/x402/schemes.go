@@ -0,0 +1,80 @@
+package x402
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SchemeExact transfers exactly the required amount
+const SchemeExact = "exact"
+
+var (
+	// ErrUnsupportedVersion is returned for payloads of an x402 protocol version this package does not speak
+	ErrUnsupportedVersion = errors.New("unsupported x402 version")
+	// ErrUnsupportedScheme is returned for payloads of a scheme without a registered decoder
+	ErrUnsupportedScheme = errors.New("unsupported payment scheme")
+	// ErrUnsupportedNetwork is returned for payloads on a network riders cannot pay on
+	ErrUnsupportedNetwork = errors.New("unsupported payment network")
+)
+
+// SchemePayload is the scheme-specific part of a payment payload, such as *ExactEvmPayload
+type SchemePayload interface {
+	Scheme() string
+}
+
+// PayloadDecoder decodes the scheme-specific payload of a payment made on a network of the given family
+type PayloadDecoder func(family Family, raw json.RawMessage) (SchemePayload, error)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]PayloadDecoder{SchemeExact: decodeExactPayload}
+)
+
+// RegisterScheme makes payloads of scheme decodable, replacing any decoder registered before
+func RegisterScheme(scheme string, decoder PayloadDecoder) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	schemes[scheme] = decoder
+}
+
+func decodeSchemePayload(scheme, network string, raw json.RawMessage) (SchemePayload, error) {
+	family, ok := NetworkFamily(network)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedNetwork, network)
+	}
+
+	schemesMu.RLock()
+	decoder, ok := schemes[scheme]
+	schemesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, scheme)
+	}
+	return decoder(family, raw)
+}
+
+func decodeExactPayload(family Family, raw json.RawMessage) (SchemePayload, error) {
+	switch family {
+	case FamilyEVM:
+		var payload ExactEvmPayload
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Signature == "" || payload.Authorization == nil {
+			return nil, errors.New("exact EVM payload requires a signature and an authorization")
+		}
+		return &payload, nil
+	case FamilySVM:
+		var payload ExactSvmPayload
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Transaction == "" {
+			return nil, errors.New("exact Solana payload requires a transaction")
+		}
+		return &payload, nil
+	default:
+		return nil, fmt.Errorf("%w: exact payments on %s networks", ErrUnsupportedScheme, family)
+	}
+}
@@ -0,0 +1,47 @@
+package x402
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// recoverAddress returns the Ethereum address whose key produced the 65 byte r || s || v signature
+// over hash. Like USDC's ECRecover it rejects malleable signatures with s in the upper half of the order.
+func recoverAddress(hash []byte, signature []byte) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("signature must be 65 bytes")
+	}
+	v := signature[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, errors.New("invalid signature recovery id")
+	}
+	var s secp256k1.ModNScalar
+	if overflow := s.SetByteSlice(signature[32:64]); !overflow && s.IsOverHalfOrder() {
+		return nil, errors.New("signature s value is malleable")
+	}
+
+	// RecoverCompact takes the recovery code first: 27 + v for an uncompressed key
+	compact := make([]byte, 0, 65)
+	compact = append(compact, 27+v)
+	compact = append(compact, signature[:64]...)
+	publicKey, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return keccak256(publicKey.SerializeUncompressed()[1:])[12:], nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hash.Write(d)
+	}
+	return hash.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package x402
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// signDigest signs digest with the private key as an Ethereum r || s || v signature (test helper)
+func signDigest(t *testing.T, privateKey *big.Int, digest []byte) []byte {
+	t.Helper()
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(privateKey.Bytes()), digest, false)
+	return append(compact[1:], compact[0])
+}
+
+// addressOf returns the Ethereum address of the private key (test helper)
+func addressOf(privateKey *big.Int) []byte {
+	publicKey := secp256k1.PrivKeyFromBytes(privateKey.Bytes()).PubKey()
+	return keccak256(publicKey.SerializeUncompressed()[1:])[12:]
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeHex(s, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTypeHashes(t *testing.T) {
+	if got := hex.EncodeToString(eip712DomainTypeHash); got != "8b73c3c69bb8fe3d512ecc4cf759cc79239f7b179b0ffacaa9a75d522b39400f" {
+		t.Errorf("unexpected EIP712Domain type hash %s", got)
+	}
+	if got := hex.EncodeToString(transferWithAuthorizationTypeHash); got != "7c7c6cdb67a18743f49ec6fa9b35f50d52ed05cbed4cc592e13b44501c1a2267" {
+		t.Errorf("unexpected TransferWithAuthorization type hash %s", got)
+	}
+}
+
+func TestRecoverAddress_KnownVector(t *testing.T) {
+	// web3.js accounts.sign("Some data") with the documented example key
+	privateKey, _ := new(big.Int).SetString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318", 16)
+	want := "2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	if got := hex.EncodeToString(addressOf(privateKey)); got != want {
+		t.Fatalf("expected address %s, got %s", want, got)
+	}
+
+	digest := mustHex(t, "0x1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655")
+	signature := mustHex(t, "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c")
+
+	signer, err := recoverAddress(digest, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hex.EncodeToString(signer) != want {
+		t.Errorf("expected signer %s, got %x", want, signer)
+	}
+}
+
+func TestRecoverAddress_RoundTrip(t *testing.T) {
+	privateKey := new(big.Int).SetBytes(keccak256([]byte("rider wallet")))
+	digest := keccak256([]byte("payment"))
+
+	signature := signDigest(t, privateKey, digest)
+	signer, err := recoverAddress(digest, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hex.EncodeToString(signer) != hex.EncodeToString(addressOf(privateKey)) {
+		t.Errorf("expected signer %x, got %x", addressOf(privateKey), signer)
+	}
+
+	// the malleable twin (n - s, flipped v) recovers the same key but USDC refuses it
+	malleable := append([]byte(nil), signature...)
+	var s secp256k1.ModNScalar
+	s.SetByteSlice(signature[32:64])
+	sBytes := s.Negate().Bytes()
+	copy(malleable[32:64], sBytes[:])
+	malleable[64] ^= 1
+	if _, err := recoverAddress(digest, malleable); err == nil {
+		t.Error("expected high s signature to be rejected")
+	}
+}
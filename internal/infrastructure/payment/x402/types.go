@@ -4,7 +4,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"time"
 )
 
@@ -23,20 +22,157 @@ type PaymentRequirements struct {
 	Extra             *json.RawMessage `json:"extra,omitempty"`
 }
 
+// Protocol versions understood by this package
+const (
+	ProtocolV1 = 1
+	ProtocolV2 = 2
+)
+
+// PaymentRequirementsV2 is the v2 wire form of PaymentRequirements: the price is named amount and
+// the resource paid for is described once, next to the accepted requirements
+type PaymentRequirementsV2 struct {
+	Scheme            string           `json:"scheme"`
+	Network           string           `json:"network"`
+	Amount            string           `json:"amount"`
+	Asset             string           `json:"asset"`
+	PayTo             string           `json:"payTo"`
+	MaxTimeoutSeconds int              `json:"maxTimeoutSeconds"`
+	Extra             *json.RawMessage `json:"extra,omitempty"`
+}
+
+// ResourceInfo describes the resource paid for in v2 messages
+type ResourceInfo struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// V2 returns the requirements in their v2 wire form; the resource is described by ResourceInfo
+func (r *PaymentRequirements) V2() *PaymentRequirementsV2 {
+	return &PaymentRequirementsV2{
+		Scheme:            r.Scheme,
+		Network:           r.Network,
+		Amount:            r.MaxAmountRequired,
+		Asset:             r.Asset,
+		PayTo:             r.PayTo,
+		MaxTimeoutSeconds: r.MaxTimeoutSeconds,
+		Extra:             r.Extra,
+	}
+}
+
+// ResourceInfo returns the v2 description of the resource the requirements pay for
+func (r *PaymentRequirements) ResourceInfo() *ResourceInfo {
+	return &ResourceInfo{URL: r.Resource, Description: r.Description, MimeType: r.MimeType}
+}
+
+// requirements converts v2 requirements for resource back to PaymentRequirements
+func (r *PaymentRequirementsV2) requirements(resource *ResourceInfo) *PaymentRequirements {
+	requirements := &PaymentRequirements{
+		Scheme:            r.Scheme,
+		Network:           r.Network,
+		MaxAmountRequired: r.Amount,
+		PayTo:             r.PayTo,
+		MaxTimeoutSeconds: r.MaxTimeoutSeconds,
+		Asset:             r.Asset,
+		Extra:             r.Extra,
+	}
+	if resource != nil {
+		requirements.Resource, requirements.Description, requirements.MimeType = resource.URL, resource.Description, resource.MimeType
+	}
+	return requirements
+}
+
+// PaymentRequiredV2 is the v2 list of the payments a resource accepts
+type PaymentRequiredV2 struct {
+	X402Version int                      `json:"x402Version"`
+	Error       string                   `json:"error,omitempty"`
+	Resource    *ResourceInfo            `json:"resource"`
+	Accepts     []*PaymentRequirementsV2 `json:"accepts"`
+}
+
+// NewPaymentRequiredV2 describes accepts in the v2 shape. They must pay for the same resource, which
+// is taken from the first.
+func NewPaymentRequiredV2(accepts []*PaymentRequirements, message string) *PaymentRequiredV2 {
+	required := &PaymentRequiredV2{X402Version: ProtocolV2, Error: message, Accepts: make([]*PaymentRequirementsV2, 0, len(accepts))}
+	for _, requirements := range accepts {
+		if required.Resource == nil {
+			required.Resource = requirements.ResourceInfo()
+		}
+		required.Accepts = append(required.Accepts, requirements.V2())
+	}
+	return required
+}
+
 // PaymentPayload represents the decoded payment payload for a client's payment
 type PaymentPayload struct {
-	X402Version int              `json:"x402Version"`
-	Scheme      string           `json:"scheme"`
-	Network     string           `json:"network"`
-	Payload     *ExactEvmPayload `json:"payload"`
+	X402Version int                    `json:"x402Version"`
+	Scheme      string                 `json:"scheme"`
+	Network     string                 `json:"network"`
+	Resource    *ResourceInfo          `json:"resource,omitempty"` // v2: the resource the client paid for
+	Accepted    *PaymentRequirementsV2 `json:"accepted,omitempty"` // v2: the requirements the client chose to pay
+	Payload     SchemePayload          `json:"payload"`
+}
+
+// MarshalJSON encodes the payload in the shape of its version: a v2 payload names its scheme and
+// network only in the accepted requirements
+func (p PaymentPayload) MarshalJSON() ([]byte, error) {
+	if p.X402Version == ProtocolV2 && p.Accepted != nil {
+		return json.Marshal(struct {
+			X402Version int                    `json:"x402Version"`
+			Resource    *ResourceInfo          `json:"resource,omitempty"`
+			Accepted    *PaymentRequirementsV2 `json:"accepted"`
+			Payload     SchemePayload          `json:"payload"`
+		}{p.X402Version, p.Resource, p.Accepted, p.Payload})
+	}
+	return json.Marshal(struct {
+		X402Version int           `json:"x402Version"`
+		Scheme      string        `json:"scheme"`
+		Network     string        `json:"network"`
+		Payload     SchemePayload `json:"payload"`
+	}{p.X402Version, p.Scheme, p.Network, p.Payload})
+}
+
+// UnmarshalJSON decodes the scheme-specific payload with the decoder registered for the payment's scheme.
+// A v2 payload names its scheme and network in the accepted requirements.
+func (p *PaymentPayload) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		X402Version int                    `json:"x402Version"`
+		Scheme      string                 `json:"scheme"`
+		Network     string                 `json:"network"`
+		Resource    *ResourceInfo          `json:"resource"`
+		Accepted    *PaymentRequirementsV2 `json:"accepted"`
+		Payload     json.RawMessage        `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	if wire.Scheme == "" && wire.Accepted != nil {
+		wire.Scheme, wire.Network = wire.Accepted.Scheme, wire.Accepted.Network
+	}
+
+	*p = PaymentPayload{X402Version: wire.X402Version, Scheme: wire.Scheme, Network: wire.Network, Resource: wire.Resource, Accepted: wire.Accepted}
+	if len(wire.Payload) == 0 || string(wire.Payload) == "null" {
+		return nil
+	}
+	payload, err := decodeSchemePayload(wire.Scheme, wire.Network, wire.Payload)
+	if err != nil {
+		return err
+	}
+	p.Payload = payload
+	return nil
 }
 
-// ExactEvmPayloadAuthorization represents the payload for an exact EVM payment
+// ExactEvmPayload represents the payload for an exact EVM payment
 type ExactEvmPayload struct {
 	Signature     string                        `json:"signature"`
 	Authorization *ExactEvmPayloadAuthorization `json:"authorization"`
 }
 
+// Scheme implements SchemePayload
+func (p *ExactEvmPayload) Scheme() string {
+	return SchemeExact
+}
+
 // ExactEvmPayloadAuthorization represents the payload for an exact EVM payment ERC-3009
 // authorization EIP-712 typed data message
 type ExactEvmPayloadAuthorization struct {
@@ -48,6 +184,17 @@ type ExactEvmPayloadAuthorization struct {
 	Nonce       string `json:"nonce"`
 }
 
+// ExactSvmPayload represents the payload for an exact Solana payment: a token transfer
+// signed by the payer and completed by the facilitator as fee payer
+type ExactSvmPayload struct {
+	Transaction string `json:"transaction"` // base64 encoded, partially signed transaction
+}
+
+// Scheme implements SchemePayload
+func (p *ExactSvmPayload) Scheme() string {
+	return SchemeExact
+}
+
 // VerifyResponse represents the response from the verify endpoint
 type VerifyResponse struct {
 	IsValid       bool    `json:"isValid"`
@@ -73,24 +220,26 @@ func (s *SettleResponse) EncodeToBase64String() (string, error) {
 	return base64.StdEncoding.EncodeToString(jsonBytes), nil
 }
 
-// DecodePaymentPayloadFromBase64 decodes a base64 encoded string into a PaymentPayload
+// DecodePaymentPayloadFromBase64 decodes a base64 encoded string into a PaymentPayload.
+// The declared protocol version is kept; payloads without one are treated as v1.
 func DecodePaymentPayloadFromBase64(encoded string) (*PaymentPayload, error) {
 	decodedBytes, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode base64 string: %w", err)
 	}
 
-	log.Println(string(decodedBytes))
 	var payload PaymentPayload
 	if err := json.Unmarshal(decodedBytes, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payment payload: %w", err)
 	}
 
-	// Set the x402Version after decoding, matching the TypeScript behavior
-	payload.X402Version = 1
-
-	log.Printf("%#v\n", payload)
-
+	switch payload.X402Version {
+	case 0:
+		payload.X402Version = ProtocolV1
+	case ProtocolV1, ProtocolV2:
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, payload.X402Version)
+	}
 	return &payload, nil
 }
 
@@ -0,0 +1,87 @@
+package x402_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/infrastructure/payment/x402"
+)
+
+func TestDecodePaymentPayloadFromBase64(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		wantVersion int
+		wantNetwork string
+		wantErr     error
+	}{
+		{
+			name:        "v1 EVM",
+			payload:     `{"x402Version":1,"scheme":"exact","network":"base","payload":{"signature":"0xsig","authorization":{"from":"0xrider"}}}`,
+			wantVersion: 1,
+			wantNetwork: "base",
+		},
+		{
+			name:        "missing version defaults to v1",
+			payload:     `{"scheme":"exact","network":"base-sepolia","payload":{"signature":"0xsig","authorization":{"from":"0xrider"}}}`,
+			wantVersion: 1,
+			wantNetwork: "base-sepolia",
+		},
+		{
+			name:        "v2 with accepted requirements",
+			payload:     `{"x402Version":2,"accepted":{"scheme":"exact","network":"eip155:8453"},"payload":{"signature":"0xsig","authorization":{"from":"0xrider"}}}`,
+			wantVersion: 2,
+			wantNetwork: "eip155:8453",
+		},
+		{
+			name:        "v2 Solana",
+			payload:     `{"x402Version":2,"scheme":"exact","network":"solana","payload":{"transaction":"AQAB"}}`,
+			wantVersion: 2,
+			wantNetwork: "solana",
+		},
+		{name: "future version", payload: `{"x402Version":3,"scheme":"exact","network":"base","payload":{"signature":"0xsig","authorization":{}}}`, wantErr: x402.ErrUnsupportedVersion},
+		{name: "unknown scheme", payload: `{"x402Version":1,"scheme":"upto","network":"base","payload":{}}`, wantErr: x402.ErrUnsupportedScheme},
+		{name: "unknown network", payload: `{"x402Version":1,"scheme":"exact","network":"dogechain","payload":{}}`, wantErr: x402.ErrUnsupportedNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := x402.DecodePaymentPayloadFromBase64(base64.StdEncoding.EncodeToString([]byte(tt.payload)))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if payload.X402Version != tt.wantVersion || payload.Scheme != "exact" || payload.Network != tt.wantNetwork {
+				t.Errorf("unexpected payload: %+v", payload)
+			}
+		})
+	}
+}
+
+func TestDecodePaymentPayloadFromBase64_SchemePayloads(t *testing.T) {
+	evm, err := x402.DecodePaymentPayloadFromBase64(encodedTestPayload(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exact, ok := evm.Payload.(*x402.ExactEvmPayload); !ok || exact.Authorization.From != "0xed437cc3e8ba88dbfe3f7a912f96fb51a3ca3752" {
+		t.Errorf("expected an exact EVM payload, got %#v", evm.Payload)
+	}
+
+	svm, err := x402.DecodePaymentPayloadFromBase64(base64.StdEncoding.EncodeToString([]byte(`{"x402Version":1,"scheme":"exact","network":"solana-devnet","payload":{"transaction":"AQAB"}}`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exact, ok := svm.Payload.(*x402.ExactSvmPayload); !ok || exact.Transaction != "AQAB" {
+		t.Errorf("expected an exact Solana payload, got %#v", svm.Payload)
+	}
+
+	if _, err := x402.DecodePaymentPayloadFromBase64(base64.StdEncoding.EncodeToString([]byte(`{"scheme":"exact","network":"base","payload":{"signature":"0xsig"}}`))); err == nil {
+		t.Error("expected an EVM payload without authorization to be rejected")
+	}
+}
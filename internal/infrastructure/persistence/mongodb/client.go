@@ -12,7 +12,12 @@ import (
 )
 
 const (
-	TripsCollection = "trips"
+	TripsCollection          = "trips"
+	PaymentsCollection       = "payments"
+	OutboxCollection         = "payment_outbox"
+	DriverAccountsCollection = "driver_accounts"
+	PayoutsCollection        = "payouts"
+	X402NoncesCollection     = "x402_nonces"
 )
 
 // MongoConfig holds MongoDB connection configuration
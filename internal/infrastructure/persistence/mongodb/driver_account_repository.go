@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// driverAccountDocument is the MongoDB representation of domain.DriverAccount, keyed by driver ID
+type driverAccountDocument struct {
+	DriverID          string    `bson:"_id"`
+	ProviderAccountID string    `bson:"provider_account_id"`
+	PayoutsEnabled    bool      `bson:"payouts_enabled"`
+	CreatedAt         time.Time `bson:"created_at"`
+	UpdatedAt         time.Time `bson:"updated_at"`
+}
+
+// DriverAccountRepository is the MongoDB implementation of domain.DriverAccountRepository
+type DriverAccountRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDriverAccountRepository creates a new MongoDB driver account repository
+func NewDriverAccountRepository(db *mongo.Database) *DriverAccountRepository {
+	return &DriverAccountRepository{
+		collection: db.Collection(DriverAccountsCollection),
+	}
+}
+
+func (r *DriverAccountRepository) CreateDriverAccount(ctx context.Context, account *domain.DriverAccount) error {
+	if _, err := r.collection.InsertOne(ctx, driverAccountDocument(*account)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: %s", domain.ErrDriverAccountAlreadyExists, account.DriverID)
+		}
+		return fmt.Errorf("failed to create driver account: %w", err)
+	}
+	return nil
+}
+
+func (r *DriverAccountRepository) UpdateDriverAccount(ctx context.Context, account *domain.DriverAccount) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": account.DriverID}, driverAccountDocument(*account))
+	if err != nil {
+		return fmt.Errorf("failed to update driver account: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrDriverAccountNotFound, account.DriverID)
+	}
+	return nil
+}
+
+func (r *DriverAccountRepository) GetDriverAccount(ctx context.Context, driverID string) (*domain.DriverAccount, error) {
+	var doc driverAccountDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": driverID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: %s", domain.ErrDriverAccountNotFound, driverID)
+		}
+		return nil, fmt.Errorf("failed to get driver account: %w", err)
+	}
+
+	account := domain.DriverAccount(doc)
+	return &account, nil
+}
@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NonceRepository is the MongoDB implementation of application.NonceStore, shared by all instances
+type NonceRepository struct {
+	collection *mongo.Collection
+}
+
+// NewNonceRepository creates a new MongoDB nonce repository
+func NewNonceRepository(db *mongo.Database) *NonceRepository {
+	return &NonceRepository{
+		collection: db.Collection(X402NoncesCollection),
+	}
+}
+
+// EnsureIndexes creates the TTL index removing nonces once their authorization has expired
+func (r *NonceRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create nonce indexes: %w", err)
+	}
+	return nil
+}
+
+// Reserve claims the nonce with a single upsert keyed by payer and nonce. It matches only an expired
+// reservation the TTL monitor has not removed yet, so a live reservation fails the insert on its _id.
+func (r *NonceRepository) Reserve(ctx context.Context, from, nonce string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	key := from + ":" + nonce
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": key, "expires_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"from": from, "nonce": nonce, "expires_at": expiresAt.UTC(), "reserved_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: %s", domain.ErrNonceUsed, nonce)
+		}
+		return fmt.Errorf("failed to reserve nonce: %w", err)
+	}
+	return nil
+}
+
+func (r *NonceRepository) Release(ctx context.Context, from, nonce string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": from + ":" + nonce}); err != nil {
+		return fmt.Errorf("failed to release nonce: %w", err)
+	}
+	return nil
+}
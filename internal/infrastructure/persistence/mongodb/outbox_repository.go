@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/infrastructure/messaging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dispatchedOutboxRetention is how long dispatched messages are kept for troubleshooting before MongoDB deletes them
+const dispatchedOutboxRetention = 7 * 24 * time.Hour
+
+// outboxDocument is the MongoDB representation of messaging.OutboxMessage
+type outboxDocument struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	RoutingKey    string             `bson:"routing_key"`
+	OwnerID       string             `bson:"owner_id"`
+	Data          []byte             `bson:"data"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	DispatchedAt  *time.Time         `bson:"dispatched_at"`
+}
+
+func (d *outboxDocument) toMessage() *messaging.OutboxMessage {
+	return &messaging.OutboxMessage{
+		ID:         d.ID.Hex(),
+		RoutingKey: d.RoutingKey,
+		Message: events.AmqpMessage{
+			OwnerID: d.OwnerID,
+			Data:    d.Data,
+		},
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		DispatchedAt:  d.DispatchedAt,
+	}
+}
+
+// OutboxRepository is the MongoDB implementation of messaging.OutboxStore
+type OutboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository creates a new MongoDB outbox repository
+func NewOutboxRepository(db *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{
+		collection: db.Collection(OutboxCollection),
+	}
+}
+
+// EnsureIndexes creates the index used by the relay to find pending messages and a TTL index that
+// removes dispatched messages. Pending messages have no dispatched_at time, so the TTL never removes them.
+func (r *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "dispatched_at", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "dispatched_at", Value: 1}},
+			Options: options.Index().
+				SetName("dispatched_at_ttl").
+				SetExpireAfterSeconds(int32(dispatchedOutboxRetention / time.Second)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) AddOutboxMessage(ctx context.Context, message *messaging.OutboxMessage) error {
+	doc := &outboxDocument{
+		ID:            primitive.NewObjectID(),
+		RoutingKey:    message.RoutingKey,
+		OwnerID:       message.Message.OwnerID,
+		Data:          message.Message.Data,
+		NextAttemptAt: message.NextAttemptAt,
+		CreatedAt:     message.CreatedAt,
+	}
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to add outbox message: %w", err)
+	}
+
+	message.ID = doc.ID.Hex()
+	return nil
+}
+
+// FetchPendingOutboxMessages returns undispatched messages that are due, oldest first
+func (r *OutboxRepository) FetchPendingOutboxMessages(ctx context.Context, limit int) ([]*messaging.OutboxMessage, error) {
+	filter := bson.M{
+		"dispatched_at":   nil,
+		"next_attempt_at": bson.M{"$lte": time.Now().UTC()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox messages: %w", err)
+	}
+
+	var docs []outboxDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox messages: %w", err)
+	}
+
+	messages := make([]*messaging.OutboxMessage, 0, len(docs))
+	for i := range docs {
+		messages = append(messages, docs[i].toMessage())
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkOutboxMessageDispatched(ctx context.Context, id string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"dispatched_at": time.Now().UTC()}}
+	if _, err := r.collection.UpdateByID(ctx, _id, update); err != nil {
+		return fmt.Errorf("failed to mark outbox message dispatched: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkOutboxMessageFailed(ctx context.Context, id string, lastError string, nextAttemptAt time.Time) error {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{"last_error": lastError, "next_attempt_at": nextAttemptAt},
+		"$inc": bson.M{"attempts": 1},
+	}
+	if _, err := r.collection.UpdateByID(ctx, _id, update); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// refundDocument is the MongoDB representation of domain.Refund
+type refundDocument struct {
+	RequestID        string    `bson:"request_id,omitempty"`
+	ProviderRefundID string    `bson:"provider_refund_id"`
+	Amount           int64     `bson:"amount"`
+	Reason           string    `bson:"reason,omitempty"`
+	CreatedAt        time.Time `bson:"created_at"`
+}
+
+// paymentDocument is the MongoDB representation of domain.Payment
+type paymentDocument struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty"`
+	TripID            string             `bson:"trip_id"`
+	UserID            string             `bson:"user_id"`
+	DriverID          string             `bson:"driver_id"`
+	Amount            int64              `bson:"amount"`
+	AuthorizedAmount  int64              `bson:"authorized_amount,omitempty"`
+	Currency          string             `bson:"currency"`
+	CaptureMethod     string             `bson:"capture_method"`
+	Provider          string             `bson:"provider"`
+	ProviderSessionID string             `bson:"provider_session_id,omitempty"`
+	ProviderPaymentID string             `bson:"provider_payment_id,omitempty"`
+	TransactionHash   string             `bson:"transaction_hash,omitempty"`
+	SettlingPayload   string             `bson:"settling_payload,omitempty"`
+	Status            string             `bson:"status"`
+	FailureReason     string             `bson:"failure_reason,omitempty"`
+	RefundedAmount    int64              `bson:"refunded_amount"`
+	Refunds           []refundDocument   `bson:"refunds,omitempty"`
+	PayoutID          string             `bson:"payout_id,omitempty"`
+	CollectedByDriver bool               `bson:"collected_by_driver,omitempty"`
+	ReleaseRequested  bool               `bson:"release_requested,omitempty"`
+	Version           int64              `bson:"version"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	UpdatedAt         time.Time          `bson:"updated_at"`
+}
+
+func toPaymentDocument(p *domain.Payment) (*paymentDocument, error) {
+	doc := &paymentDocument{
+		TripID:            p.TripID,
+		UserID:            p.UserID,
+		DriverID:          p.DriverID,
+		Amount:            p.Amount,
+		AuthorizedAmount:  p.AuthorizedAmount,
+		Currency:          p.Currency,
+		CaptureMethod:     string(p.CaptureMethod),
+		Provider:          p.Provider,
+		ProviderSessionID: p.ProviderSessionID,
+		ProviderPaymentID: p.ProviderPaymentID,
+		TransactionHash:   p.TransactionHash,
+		SettlingPayload:   p.SettlingPayload,
+		Status:            string(p.Status),
+		FailureReason:     p.FailureReason,
+		RefundedAmount:    p.RefundedAmount,
+		PayoutID:          p.PayoutID,
+		CollectedByDriver: p.CollectedByDriver,
+		ReleaseRequested:  p.ReleaseRequested,
+		Version:           p.Version,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+	for _, refund := range p.Refunds {
+		doc.Refunds = append(doc.Refunds, refundDocument(refund))
+	}
+	if p.ID != "" {
+		id, err := primitive.ObjectIDFromHex(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		doc.ID = id
+	}
+	return doc, nil
+}
+
+func (d *paymentDocument) toDomain() *domain.Payment {
+	payment := &domain.Payment{
+		ID:                d.ID.Hex(),
+		TripID:            d.TripID,
+		UserID:            d.UserID,
+		DriverID:          d.DriverID,
+		Amount:            d.Amount,
+		AuthorizedAmount:  d.AuthorizedAmount,
+		Currency:          d.Currency,
+		CaptureMethod:     domain.CaptureMethod(d.CaptureMethod),
+		Provider:          d.Provider,
+		ProviderSessionID: d.ProviderSessionID,
+		ProviderPaymentID: d.ProviderPaymentID,
+		TransactionHash:   d.TransactionHash,
+		SettlingPayload:   d.SettlingPayload,
+		Status:            domain.PaymentStatus(d.Status),
+		FailureReason:     d.FailureReason,
+		RefundedAmount:    d.RefundedAmount,
+		PayoutID:          d.PayoutID,
+		CollectedByDriver: d.CollectedByDriver,
+		ReleaseRequested:  d.ReleaseRequested,
+		Version:           d.Version,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+	for _, refund := range d.Refunds {
+		payment.Refunds = append(payment.Refunds, domain.Refund(refund))
+	}
+	return payment
+}
+
+// PaymentRepository is the MongoDB implementation of domain.PaymentRepository
+type PaymentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPaymentRepository creates a new MongoDB payment repository
+func NewPaymentRepository(db *mongo.Database) *PaymentRepository {
+	return &PaymentRepository{
+		collection: db.Collection(PaymentsCollection),
+	}
+}
+
+// EnsureIndexes creates the indexes used by the payment queries.
+// The partial unique index allows a single open payment per trip (requires MongoDB 6.0+ for $in).
+// A partial filter cannot be changed in place, so the index is renamed whenever the open statuses change.
+func (r *PaymentRepository) EnsureIndexes(ctx context.Context) error {
+	openStatuses := bson.A{}
+	for _, status := range domain.OpenPaymentStatuses() {
+		openStatuses = append(openStatuses, string(status))
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}},
+			Options: options.Index().
+				SetName("trip_id_open_unique_v2").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": openStatuses}}),
+		},
+		{Keys: bson.D{{Key: "provider_session_id", Value: 1}}},
+		{Keys: bson.D{{Key: "provider_payment_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "payout_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "driver_id", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payment indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	doc, err := toPaymentDocument(payment)
+	if err != nil {
+		return err
+	}
+	if doc.ID.IsZero() {
+		doc.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: %s", domain.ErrPaymentAlreadyExists, payment.TripID)
+		}
+		return fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	payment.ID = doc.ID.Hex()
+	return nil
+}
+
+// UpdatePayment replaces the payment only if its stored version is still the one it was loaded with.
+// Payments stored before versioning have no version field and match version 0.
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, payment *domain.Payment) error {
+	doc, err := toPaymentDocument(payment)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": doc.ID, "version": payment.Version}
+	if payment.Version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	doc.Version = payment.Version + 1
+
+	result, err := r.collection.ReplaceOne(ctx, filter, doc)
+	if err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": doc.ID})
+		if err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s", domain.ErrPaymentConflict, payment.ID)
+		}
+		return fmt.Errorf("%w: %s", domain.ErrPaymentNotFound, payment.ID)
+	}
+
+	payment.Version = doc.Version
+	return nil
+}
+
+func (r *PaymentRepository) GetPaymentByID(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	_id, err := primitive.ObjectIDFromHex(paymentID)
+	if err != nil {
+		// No stored payment can have an ID that is not an ObjectID
+		return nil, fmt.Errorf("%w: %s", domain.ErrPaymentNotFound, paymentID)
+	}
+	return r.findOne(ctx, bson.M{"_id": _id}, paymentID)
+}
+
+// GetPaymentByTripID returns the most recent payment for the given trip
+func (r *PaymentRepository) GetPaymentByTripID(ctx context.Context, tripID string) (*domain.Payment, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return r.findOne(ctx, bson.M{"trip_id": tripID}, tripID, opts)
+}
+
+func (r *PaymentRepository) GetPaymentByProviderPaymentID(ctx context.Context, providerPaymentID string) (*domain.Payment, error) {
+	return r.findOne(ctx, bson.M{"provider_payment_id": providerPaymentID}, providerPaymentID)
+}
+
+// ListPayablePayments returns captured payments created before the cutoff that were not paid out yet, oldest first.
+// Fares the driver collected, e.g. in cash, are left out: the platform holds none of that money.
+func (r *PaymentRepository) ListPayablePayments(ctx context.Context, createdBefore time.Time) ([]*domain.Payment, error) {
+	filter := unsettledFilter()
+	filter["collected_by_driver"] = bson.M{"$ne": true}
+	filter["created_at"] = bson.M{"$lt": createdBefore}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	return r.find(ctx, filter, opts)
+}
+
+// ListCommissionDuePayments returns fares the driver collected before the cutoff whose commission was not debited yet, oldest first
+func (r *PaymentRepository) ListCommissionDuePayments(ctx context.Context, createdBefore time.Time) ([]*domain.Payment, error) {
+	filter := unsettledFilter()
+	filter["collected_by_driver"] = true
+	filter["created_at"] = bson.M{"$lt": createdBefore}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	return r.find(ctx, filter, opts)
+}
+
+// ClaimPayment sets the payment's payout in a single conditional update, so a payment refunded
+// or claimed by another run since it was listed is left alone
+func (r *PaymentRepository) ClaimPayment(ctx context.Context, paymentID, payoutID string) (bool, error) {
+	_id, err := primitive.ObjectIDFromHex(paymentID)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", domain.ErrPaymentNotFound, paymentID)
+	}
+
+	filter := unsettledFilter()
+	filter["_id"] = _id
+	update := bson.M{
+		"$set": bson.M{"payout_id": payoutID, "updated_at": time.Now().UTC()},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim payment: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// unsettledFilter matches captured payments not included in a payout yet, whether as earnings or as a commission debit
+func unsettledFilter() bson.M {
+	payableStatuses := bson.A{}
+	for _, status := range domain.PayablePaymentStatuses() {
+		payableStatuses = append(payableStatuses, string(status))
+	}
+	return bson.M{
+		"status":    bson.M{"$in": payableStatuses},
+		"payout_id": bson.M{"$exists": false},
+	}
+}
+
+// ListPaymentsByUser returns a page of the rider's payments, newest first
+func (r *PaymentRepository) ListPaymentsByUser(ctx context.Context, userID string, page domain.PaymentPage) ([]*domain.Payment, error) {
+	return r.findPage(ctx, bson.M{"user_id": userID}, page)
+}
+
+// ListPaymentsByDriver returns a page of the payments for the driver's trips, newest first
+func (r *PaymentRepository) ListPaymentsByDriver(ctx context.Context, driverID string, page domain.PaymentPage) ([]*domain.Payment, error) {
+	return r.findPage(ctx, bson.M{"driver_id": driverID}, page)
+}
+
+// findPage pages through the matching payments by descending ObjectID, which follows creation order
+func (r *PaymentRepository) findPage(ctx context.Context, filter bson.M, page domain.PaymentPage) ([]*domain.Payment, error) {
+	if page.AfterID != "" {
+		afterID, err := primitive.ObjectIDFromHex(page.AfterID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidPageToken, page.AfterID)
+		}
+		filter["_id"] = bson.M{"$lt": afterID}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(page.Limit))
+	return r.find(ctx, filter, opts)
+}
+
+func (r *PaymentRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.Payment, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	var docs []paymentDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode payments: %w", err)
+	}
+
+	payments := make([]*domain.Payment, 0, len(docs))
+	for i := range docs {
+		payments = append(payments, docs[i].toDomain())
+	}
+	return payments, nil
+}
+
+func (r *PaymentRepository) findOne(ctx context.Context, filter bson.M, key string, opts ...*options.FindOneOptions) (*domain.Payment, error) {
+	var doc paymentDocument
+	err := r.collection.FindOne(ctx, filter, opts...).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPaymentNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return doc.toDomain(), nil
+}
@@ -0,0 +1,182 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// payoutItemDocument is the MongoDB representation of domain.PayoutItem
+type payoutItemDocument struct {
+	PaymentID       string `bson:"payment_id"`
+	TripID          string `bson:"trip_id"`
+	GrossAmount     int64  `bson:"gross_amount"`
+	Commission      int64  `bson:"commission"`
+	NetAmount       int64  `bson:"net_amount"`
+	CommissionDebit bool   `bson:"commission_debit,omitempty"`
+}
+
+// payoutDocument is the MongoDB representation of domain.Payout
+type payoutDocument struct {
+	ID                 primitive.ObjectID   `bson:"_id,omitempty"`
+	DriverID           string               `bson:"driver_id"`
+	ProviderAccountID  string               `bson:"provider_account_id"`
+	Currency           string               `bson:"currency"`
+	GrossAmount        int64                `bson:"gross_amount"`
+	Commission         int64                `bson:"commission"`
+	NetAmount          int64                `bson:"net_amount"`
+	CommissionBps      int64                `bson:"commission_bps"`
+	Items              []payoutItemDocument `bson:"items"`
+	Status             string               `bson:"status"`
+	ProviderTransferID string               `bson:"provider_transfer_id,omitempty"`
+	FailureReason      string               `bson:"failure_reason,omitempty"`
+	Attempts           int                  `bson:"attempts"`
+	CreatedAt          time.Time            `bson:"created_at"`
+	UpdatedAt          time.Time            `bson:"updated_at"`
+}
+
+func toPayoutDocument(p *domain.Payout) (*payoutDocument, error) {
+	doc := &payoutDocument{
+		DriverID:           p.DriverID,
+		ProviderAccountID:  p.ProviderAccountID,
+		Currency:           p.Currency,
+		GrossAmount:        p.GrossAmount,
+		Commission:         p.Commission,
+		NetAmount:          p.NetAmount,
+		CommissionBps:      p.CommissionBps,
+		Status:             string(p.Status),
+		ProviderTransferID: p.ProviderTransferID,
+		FailureReason:      p.FailureReason,
+		Attempts:           p.Attempts,
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
+	}
+	for _, item := range p.Items {
+		doc.Items = append(doc.Items, payoutItemDocument(item))
+	}
+	if p.ID != "" {
+		id, err := primitive.ObjectIDFromHex(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		doc.ID = id
+	}
+	return doc, nil
+}
+
+func (d *payoutDocument) toDomain() *domain.Payout {
+	payout := &domain.Payout{
+		ID:                 d.ID.Hex(),
+		DriverID:           d.DriverID,
+		ProviderAccountID:  d.ProviderAccountID,
+		Currency:           d.Currency,
+		GrossAmount:        d.GrossAmount,
+		Commission:         d.Commission,
+		NetAmount:          d.NetAmount,
+		CommissionBps:      d.CommissionBps,
+		Status:             domain.PayoutStatus(d.Status),
+		ProviderTransferID: d.ProviderTransferID,
+		FailureReason:      d.FailureReason,
+		Attempts:           d.Attempts,
+		CreatedAt:          d.CreatedAt,
+		UpdatedAt:          d.UpdatedAt,
+	}
+	for _, item := range d.Items {
+		payout.Items = append(payout.Items, domain.PayoutItem(item))
+	}
+	return payout
+}
+
+// PayoutRepository is the MongoDB implementation of domain.PayoutRepository
+type PayoutRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPayoutRepository creates a new MongoDB payout repository
+func NewPayoutRepository(db *mongo.Database) *PayoutRepository {
+	return &PayoutRepository{
+		collection: db.Collection(PayoutsCollection),
+	}
+}
+
+// EnsureIndexes creates the indexes used by the ledger and payout run queries
+func (r *PayoutRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "driver_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payout indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *PayoutRepository) CreatePayout(ctx context.Context, payout *domain.Payout) error {
+	doc, err := toPayoutDocument(payout)
+	if err != nil {
+		return err
+	}
+	if doc.ID.IsZero() {
+		doc.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to create payout: %w", err)
+	}
+
+	payout.ID = doc.ID.Hex()
+	return nil
+}
+
+func (r *PayoutRepository) UpdatePayout(ctx context.Context, payout *domain.Payout) error {
+	doc, err := toPayoutDocument(payout)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc)
+	if err != nil {
+		return fmt.Errorf("failed to update payout: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrPayoutNotFound, payout.ID)
+	}
+	return nil
+}
+
+// ListPayoutsByDriver returns the driver's payouts, newest first
+func (r *PayoutRepository) ListPayoutsByDriver(ctx context.Context, driverID string, limit int) ([]*domain.Payout, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	return r.find(ctx, bson.M{"driver_id": driverID}, opts)
+}
+
+// ListUnsettledPayouts returns pending and failed payouts, oldest first
+func (r *PayoutRepository) ListUnsettledPayouts(ctx context.Context) ([]*domain.Payout, error) {
+	filter := bson.M{"status": bson.M{"$in": bson.A{string(domain.PayoutStatusPending), string(domain.PayoutStatusFailed)}}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return r.find(ctx, filter, opts)
+}
+
+func (r *PayoutRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.Payout, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payouts: %w", err)
+	}
+
+	var docs []payoutDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode payouts: %w", err)
+	}
+
+	payouts := make([]*domain.Payout, 0, len(docs))
+	for i := range docs {
+		payouts = append(payouts, docs[i].toDomain())
+	}
+	return payouts, nil
+}
@@ -0,0 +1,33 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor is the MongoDB implementation of application.Transactor.
+// Multi-document transactions require MongoDB to run as a replica set.
+type Transactor struct {
+	client *mongo.Client
+}
+
+// NewTransactor creates a new MongoDB transactor
+func NewTransactor(client *mongo.Client) *Transactor {
+	return &Transactor{client: client}
+}
+
+// WithinTransaction runs fn in a transaction; repositories called with the
+// context passed to fn take part in the same transaction
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
@@ -2,15 +2,20 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ride4Low/contracts/types"
+	"github.com/ride4Low/payment-service/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// TripRepository is the MongoDB implementation of types.TripRepository
+// fareCurrencyPath is where the trip service records the currency a trip's fare was priced in
+var fareCurrencyPath = []string{"rideFare", "currency"}
+
+// TripRepository is the MongoDB implementation of domain.TripRepository
 type TripRepository struct {
 	collection *mongo.Collection
 }
@@ -22,19 +27,24 @@ func NewTripRepository(db *mongo.Database) *TripRepository {
 	}
 }
 
-func (r *TripRepository) GetTripByID(ctx context.Context, tripID string) (*types.Trip, error) {
+// GetTripByID returns the trip with the given hex ObjectID and the currency its fare was priced in
+func (r *TripRepository) GetTripByID(ctx context.Context, tripID string) (*domain.Trip, error) {
 	_id, err := primitive.ObjectIDFromHex(tripID)
 	if err != nil {
-		return nil, err
+		return nil, &domain.TripError{TripID: tripID, Err: domain.ErrInvalidTripID, Cause: err}
 	}
-	var trip types.Trip
-	err = r.collection.FindOne(ctx, bson.M{"_id": _id}).Decode(&trip)
+	raw, err := r.collection.FindOne(ctx, bson.M{"_id": _id}).Raw()
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("trip not found: %s", tripID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, &domain.TripError{TripID: tripID, Err: domain.ErrTripNotFound}
 		}
 		return nil, fmt.Errorf("failed to get trip: %w", err)
 	}
 
-	return &trip, nil
+	var trip types.Trip
+	if err := bson.Unmarshal(raw, &trip); err != nil {
+		return nil, fmt.Errorf("failed to decode trip: %w", err)
+	}
+	currency, _ := raw.Lookup(fareCurrencyPath...).StringValueOK()
+	return &domain.Trip{Trip: &trip, FareCurrency: currency}, nil
 }
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// Headers the API gateway forwards the end user it verified in. They are only trusted from
+// callers holding a gateway key.
+const (
+	PrincipalRoleHeader = "X-Principal-Role"
+	PrincipalIDHeader   = "X-Principal-Id"
+)
+
+// Role is the kind of principal a request is made for
+type Role string
+
+const (
+	RoleRider  Role = "rider"
+	RoleDriver Role = "driver"
+	RoleAdmin  Role = "admin"
+)
+
+// ErrNoPrincipal is returned when a gateway does not forward a valid principal
+var ErrNoPrincipal = errors.New("missing or invalid principal")
+
+// Client is a caller authenticated with an API key
+type Client struct {
+	Name  string
+	Admin bool // may run operator tasks such as payout runs
+}
+
+// KeyStore authenticates callers by API key. Only SHA-256 hashes of the keys are kept.
+type KeyStore struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	hash   [sha256.Size]byte
+	client Client
+}
+
+// NewKeyStore creates an empty key store, which authenticates no one
+func NewKeyStore() *KeyStore {
+	return &KeyStore{}
+}
+
+// Add registers key as the credential of client
+func (s *KeyStore) Add(key string, client Client) {
+	s.keys = append(s.keys, apiKey{hash: sha256.Sum256([]byte(key)), client: client})
+}
+
+// Authenticate returns the client holding key. Every key is compared in constant time,
+// so the response time does not reveal how much of a key was guessed.
+func (s *KeyStore) Authenticate(key string) (Client, bool) {
+	if key == "" {
+		return Client{}, false
+	}
+	hash := sha256.Sum256([]byte(key))
+
+	var found Client
+	ok := false
+	for _, candidate := range s.keys {
+		if subtle.ConstantTimeCompare(hash[:], candidate.hash[:]) == 1 {
+			found, ok = candidate.client, true
+		}
+	}
+	return found, ok
+}
+
+// Principal is the end user a request is made for
+type Principal struct {
+	Role Role
+	ID   string
+}
+
+// ResolvePrincipal returns the principal client acts for. Admin clients act as themselves; any other
+// client is a gateway that must forward the rider or driver it verified.
+func ResolvePrincipal(client Client, role, id string) (Principal, error) {
+	if client.Admin {
+		return Principal{Role: RoleAdmin, ID: client.Name}, nil
+	}
+	principal := Principal{Role: Role(strings.ToLower(strings.TrimSpace(role))), ID: strings.TrimSpace(id)}
+	if (principal.Role != RoleRider && principal.Role != RoleDriver) || principal.ID == "" {
+		return Principal{}, ErrNoPrincipal
+	}
+	return principal, nil
+}
+
+// IsAdmin reports whether the principal may act on any rider's or driver's payments
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// Owns reports whether the payment belongs to the principal, as its rider or as its driver.
+// Admins own every payment.
+func (p Principal) Owns(payment *domain.Payment) bool {
+	switch p.Role {
+	case RoleAdmin:
+		return true
+	case RoleRider:
+		return payment.UserID == p.ID
+	case RoleDriver:
+		return payment.DriverID == p.ID
+	}
+	return false
+}
+
+type clientKey struct{}
+
+type principalKey struct{}
+
+// WithClient returns a copy of ctx carrying the authenticated client
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client authenticated for the request, if any
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
+
+// WithPrincipal returns a copy of ctx carrying the principal of the request
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal the request is made for, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// RequireAdmin only passes requests from admin clients on to next
+func RequireAdmin(keys *KeyStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, ok := keys.Authenticate(BearerKey(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if !client.Admin {
+			http.Error(w, "admin API key required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithClient(r.Context(), client)))
+	})
+}
+
+// RequirePrincipal only passes authenticated requests on to next, with the client and the principal
+// it acts for in the request context. Gateway keys must forward the verified rider or driver in the
+// X-Principal-Role and X-Principal-Id headers.
+func RequirePrincipal(keys *KeyStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, ok := keys.Authenticate(BearerKey(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
+		principal, err := ResolvePrincipal(client, r.Header.Get(PrincipalRoleHeader), r.Header.Get(PrincipalIDHeader))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ctx := WithPrincipal(WithClient(r.Context(), client), principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// BearerKey returns the API key sent as "Authorization: Bearer <key>", or an empty string
+func BearerKey(r *http.Request) string {
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+func TestKeyStore_Authenticate(t *testing.T) {
+	keys := NewKeyStore()
+	keys.Add("admin-key", Client{Name: "ops", Admin: true})
+	keys.Add("partner-key", Client{Name: "partner"})
+
+	client, ok := keys.Authenticate("admin-key")
+	if !ok || client.Name != "ops" || !client.Admin {
+		t.Errorf("expected the ops admin, got %+v %v", client, ok)
+	}
+	if client, ok := keys.Authenticate("partner-key"); !ok || client.Admin {
+		t.Errorf("expected the partner, got %+v %v", client, ok)
+	}
+	for _, key := range []string{"", "admin", "unknown-key"} {
+		if _, ok := keys.Authenticate(key); ok {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	keys := NewKeyStore()
+	keys.Add("admin-key", Client{Name: "ops", Admin: true})
+	keys.Add("partner-key", Client{Name: "partner"})
+
+	var seen Client
+	handler := RequireAdmin(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ClientFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"admin", "Bearer admin-key", http.StatusOK},
+		{"lowercase scheme", "bearer admin-key", http.StatusOK},
+		{"partner", "Bearer partner-key", http.StatusForbidden},
+		{"unknown key", "Bearer other-key", http.StatusUnauthorized},
+		{"basic auth", "Basic YWRtaW4ta2V5", http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/payouts/runs", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+	if seen.Name != "ops" {
+		t.Errorf("expected the admin client in the request context, got %+v", seen)
+	}
+}
+
+func TestRequirePrincipal(t *testing.T) {
+	keys := NewKeyStore()
+	keys.Add("admin-key", Client{Name: "ops", Admin: true})
+	keys.Add("gateway-key", Client{Name: "gateway"})
+
+	var seen Principal
+	handler := RequirePrincipal(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = PrincipalFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		role          string
+		id            string
+		want          int
+		principal     Principal
+	}{
+		{"admin", "Bearer admin-key", "", "", http.StatusOK, Principal{Role: RoleAdmin, ID: "ops"}},
+		{"gateway rider", "Bearer gateway-key", "rider", "user-1", http.StatusOK, Principal{Role: RoleRider, ID: "user-1"}},
+		{"gateway without principal", "Bearer gateway-key", "", "", http.StatusUnauthorized, Principal{}},
+		{"gateway claims admin", "Bearer gateway-key", "admin", "ops", http.StatusUnauthorized, Principal{}},
+		{"principal without key", "", "rider", "user-1", http.StatusUnauthorized, Principal{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = Principal{}
+			req := httptest.NewRequest(http.MethodGet, "/payments", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.role != "" {
+				req.Header.Set(PrincipalRoleHeader, tt.role)
+				req.Header.Set(PrincipalIDHeader, tt.id)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if seen != tt.principal {
+				t.Errorf("expected principal %+v, got %+v", tt.principal, seen)
+			}
+		})
+	}
+}
+
+func TestResolvePrincipal(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  Client
+		role    string
+		id      string
+		want    Principal
+		wantErr bool
+	}{
+		{"admin acts as itself", Client{Name: "ops", Admin: true}, "rider", "user-1", Principal{Role: RoleAdmin, ID: "ops"}, false},
+		{"gateway rider", Client{Name: "gateway"}, "rider", "user-1", Principal{Role: RoleRider, ID: "user-1"}, false},
+		{"gateway driver", Client{Name: "gateway"}, " Driver ", "driver-1", Principal{Role: RoleDriver, ID: "driver-1"}, false},
+		{"gateway cannot grant admin", Client{Name: "gateway"}, "admin", "ops", Principal{}, true},
+		{"missing ID", Client{Name: "gateway"}, "rider", "", Principal{}, true},
+		{"missing role", Client{Name: "gateway"}, "", "user-1", Principal{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := ResolvePrincipal(tt.client, tt.role, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if principal != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, principal)
+			}
+		})
+	}
+}
+
+func TestPrincipal_Owns(t *testing.T) {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 1050, "USD", "stripe")
+
+	tests := []struct {
+		principal Principal
+		want      bool
+	}{
+		{Principal{Role: RoleAdmin, ID: "ops"}, true},
+		{Principal{Role: RoleRider, ID: "user-1"}, true},
+		{Principal{Role: RoleDriver, ID: "driver-1"}, true},
+		{Principal{Role: RoleRider, ID: "driver-1"}, false},
+		{Principal{Role: RoleDriver, ID: "user-1"}, false},
+		{Principal{Role: RoleRider, ID: "user-2"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.principal.Owns(payment); got != tt.want {
+			t.Errorf("%+v: expected %v, got %v", tt.principal, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// Headers the DeadLetterHandler sets on retried and dead-lettered messages
+const (
+	HeaderRetryCount         = "x-retry-count"
+	HeaderOriginalRoutingKey = "x-original-routing-key"
+	HeaderOriginalExchange   = "x-original-exchange"
+	HeaderFailureReason      = "x-failure-reason"
+	HeaderFailureClass       = "x-failure-class"
+	HeaderFailedAt           = "x-failed-at"
+)
+
+// Values of HeaderFailureClass
+const (
+	FailureClassPermanent        = "permanent"
+	FailureClassRetriesExhausted = "retries_exhausted"
+)
+
+// MessageHandler processes a single delivery
+type MessageHandler interface {
+	Handle(ctx context.Context, msg amqp091.Delivery) error
+}
+
+// DeliveryPublisher publishes raw AMQP messages (allows mocking in tests)
+type DeliveryPublisher interface {
+	Publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error
+}
+
+// DeadLetterConfig describes where failed messages go
+type DeadLetterConfig struct {
+	Queue              string          // queue the handler consumes; retried messages are routed back to it
+	DeadLetterExchange string          // exchange receiving messages that will not be retried
+	RetryDelays        []time.Duration // delay before each retry; its length bounds the number of retries
+}
+
+// RetryQueue returns the name of the queue holding messages until their attempt-th retry
+func (c DeadLetterConfig) RetryQueue(attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", c.Queue, attempt)
+}
+
+// DeadLetterHandler decorates a MessageHandler so that no message is lost or redelivered forever.
+// Permanent failures are sent to the dead-letter exchange with the failure reason in headers.
+// Transient failures are parked in a delay queue and retried, counting attempts in HeaderRetryCount,
+// and dead-lettered once the retries run out.
+type DeadLetterHandler struct {
+	next      MessageHandler
+	publisher DeliveryPublisher
+	config    DeadLetterConfig
+	now       func() time.Time
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler around next
+func NewDeadLetterHandler(next MessageHandler, publisher DeliveryPublisher, config DeadLetterConfig) *DeadLetterHandler {
+	return &DeadLetterHandler{next: next, publisher: publisher, config: config, now: time.Now}
+}
+
+// Handle processes the message and reroutes it on failure. An error is returned only when the
+// message could not be rerouted, leaving it to the broker's redelivery.
+func (h *DeadLetterHandler) Handle(ctx context.Context, msg amqp091.Delivery) error {
+	// Retried messages come back through the default exchange, so restore the command's routing key
+	if routingKey, ok := msg.Headers[HeaderOriginalRoutingKey].(string); ok {
+		msg.RoutingKey = routingKey
+	}
+
+	err := h.next.Handle(ctx, msg)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the failure says nothing about the message
+		return err
+	}
+
+	retries := retryCount(msg.Headers)
+	switch {
+	case IsPermanent(err):
+		return h.deadLetter(ctx, msg, err, FailureClassPermanent)
+	case retries >= len(h.config.RetryDelays):
+		return h.deadLetter(ctx, msg, err, FailureClassRetriesExhausted)
+	default:
+		return h.retry(ctx, msg, err, retries+1)
+	}
+}
+
+func (h *DeadLetterHandler) retry(ctx context.Context, msg amqp091.Delivery, cause error, attempt int) error {
+	log.Printf("message %s failed, retry %d/%d in %s: %v", msg.RoutingKey, attempt, len(h.config.RetryDelays), h.config.RetryDelays[attempt-1], cause)
+
+	publishing := h.republish(msg, cause)
+	publishing.Headers[HeaderRetryCount] = int32(attempt)
+	// The retry queue dead-letters expired messages back to the consumed queue
+	if err := h.publisher.Publish(ctx, "", h.config.RetryQueue(attempt), publishing); err != nil {
+		return fmt.Errorf("failed to schedule retry of message %s: %w", msg.RoutingKey, err)
+	}
+	return nil
+}
+
+func (h *DeadLetterHandler) deadLetter(ctx context.Context, msg amqp091.Delivery, cause error, class string) error {
+	log.Printf("dead-lettering message %s (%s): %v", msg.RoutingKey, class, cause)
+
+	publishing := h.republish(msg, cause)
+	publishing.Headers[HeaderFailureClass] = class
+	if err := h.publisher.Publish(ctx, h.config.DeadLetterExchange, msg.RoutingKey, publishing); err != nil {
+		return fmt.Errorf("failed to dead-letter message %s: %w", msg.RoutingKey, err)
+	}
+	return nil
+}
+
+// republish copies the delivery into a new message carrying the failure details
+func (h *DeadLetterHandler) republish(msg amqp091.Delivery, cause error) amqp091.Publishing {
+	headers := make(amqp091.Table, len(msg.Headers)+4)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderOriginalRoutingKey] = msg.RoutingKey
+	if _, ok := headers[HeaderOriginalExchange]; !ok {
+		headers[HeaderOriginalExchange] = msg.Exchange
+	}
+	headers[HeaderFailureReason] = cause.Error()
+	headers[HeaderFailedAt] = h.now().UTC().Format(time.RFC3339)
+
+	return amqp091.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		DeliveryMode:  amqp091.Persistent,
+		CorrelationId: msg.CorrelationId,
+		MessageId:     msg.MessageId,
+		Timestamp:     msg.Timestamp,
+		Type:          msg.Type,
+		AppId:         msg.AppId,
+		Body:          msg.Body,
+	}
+}
+
+// retryCount reads HeaderRetryCount, whose integer type depends on who encoded the table
+func retryCount(headers amqp091.Table) int {
+	switch count := headers[HeaderRetryCount].(type) {
+	case int:
+		return count
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	}
+	return 0
+}
@@ -0,0 +1,174 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// mockMessageHandler returns err and records the deliveries it was given
+type mockMessageHandler struct {
+	err        error
+	deliveries []amqp091.Delivery
+}
+
+func (m *mockMessageHandler) Handle(ctx context.Context, msg amqp091.Delivery) error {
+	m.deliveries = append(m.deliveries, msg)
+	return m.err
+}
+
+type publishedMessage struct {
+	exchange   string
+	routingKey string
+	msg        amqp091.Publishing
+}
+
+// mockDeliveryPublisher is a mock implementation of DeliveryPublisher for testing
+type mockDeliveryPublisher struct {
+	err       error
+	published []publishedMessage
+}
+
+func (m *mockDeliveryPublisher) Publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
+	m.published = append(m.published, publishedMessage{exchange: exchange, routingKey: routingKey, msg: msg})
+	return m.err
+}
+
+func newTestDeadLetterHandler(next MessageHandler, publisher DeliveryPublisher) *DeadLetterHandler {
+	return NewDeadLetterHandler(next, publisher, DeadLetterConfig{
+		Queue:              "payment_trip_response",
+		DeadLetterExchange: "payment.dlx",
+		RetryDelays:        []time.Duration{time.Second, time.Minute},
+	})
+}
+
+func TestDeadLetterHandler_Success(t *testing.T) {
+	publisher := &mockDeliveryPublisher{}
+	handler := newTestDeadLetterHandler(&mockMessageHandler{}, publisher)
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{RoutingKey: PaymentCmdCapture}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("expected nothing published, got %d", len(publisher.published))
+	}
+}
+
+func TestDeadLetterHandler_PermanentFailure(t *testing.T) {
+	publisher := &mockDeliveryPublisher{}
+	handler := newTestDeadLetterHandler(&mockMessageHandler{err: Permanent(errors.New("unknown routing key: foo"))}, publisher)
+
+	msg := amqp091.Delivery{RoutingKey: "foo", Exchange: "trip", Body: []byte("{}"), MessageId: "msg-1"}
+	if err := handler.Handle(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected 1 message published, got %d", len(publisher.published))
+	}
+	published := publisher.published[0]
+	if published.exchange != "payment.dlx" || published.routingKey != "foo" {
+		t.Errorf("expected dead-letter exchange, got %s %s", published.exchange, published.routingKey)
+	}
+	headers := published.msg.Headers
+	if headers[HeaderFailureClass] != FailureClassPermanent || headers[HeaderFailureReason] != "unknown routing key: foo" {
+		t.Errorf("unexpected failure headers: %v", headers)
+	}
+	if headers[HeaderOriginalExchange] != "trip" || string(published.msg.Body) != "{}" || published.msg.MessageId != "msg-1" {
+		t.Errorf("expected the original message to be preserved, got %+v", published.msg)
+	}
+}
+
+func TestDeadLetterHandler_TransientFailureRetried(t *testing.T) {
+	publisher := &mockDeliveryPublisher{}
+	next := &mockMessageHandler{err: errors.New("mongo unavailable")}
+	handler := newTestDeadLetterHandler(next, publisher)
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{RoutingKey: PaymentCmdCapture}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	published := publisher.published[0]
+	if published.exchange != "" || published.routingKey != "payment_trip_response.retry.1" {
+		t.Errorf("expected first retry queue, got %q %q", published.exchange, published.routingKey)
+	}
+	if published.msg.Headers[HeaderRetryCount] != int32(1) || published.msg.Headers[HeaderOriginalRoutingKey] != PaymentCmdCapture {
+		t.Errorf("unexpected retry headers: %v", published.msg.Headers)
+	}
+
+	// The retried message returns through the default exchange with the queue name as routing key
+	retried := amqp091.Delivery{RoutingKey: "payment_trip_response", Headers: published.msg.Headers}
+	if err := handler.Handle(context.Background(), retried); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.deliveries[1].RoutingKey != PaymentCmdCapture {
+		t.Errorf("expected the original routing key to be restored, got %s", next.deliveries[1].RoutingKey)
+	}
+	if publisher.published[1].routingKey != "payment_trip_response.retry.2" || publisher.published[1].msg.Headers[HeaderRetryCount] != int32(2) {
+		t.Errorf("expected second retry, got %s %v", publisher.published[1].routingKey, publisher.published[1].msg.Headers[HeaderRetryCount])
+	}
+}
+
+func TestDeadLetterHandler_RetriesExhausted(t *testing.T) {
+	publisher := &mockDeliveryPublisher{}
+	handler := newTestDeadLetterHandler(&mockMessageHandler{err: errors.New("mongo unavailable")}, publisher)
+
+	msg := amqp091.Delivery{RoutingKey: "payment_trip_response", Headers: amqp091.Table{
+		HeaderRetryCount:         int64(2),
+		HeaderOriginalRoutingKey: PaymentCmdCapture,
+	}}
+	if err := handler.Handle(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	published := publisher.published[0]
+	if published.exchange != "payment.dlx" || published.routingKey != PaymentCmdCapture {
+		t.Errorf("expected dead-letter exchange, got %s %s", published.exchange, published.routingKey)
+	}
+	if published.msg.Headers[HeaderFailureClass] != FailureClassRetriesExhausted {
+		t.Errorf("expected retries exhausted, got %v", published.msg.Headers[HeaderFailureClass])
+	}
+}
+
+func TestDeadLetterHandler_PublishFailure(t *testing.T) {
+	publisher := &mockDeliveryPublisher{err: errors.New("channel closed")}
+	handler := newTestDeadLetterHandler(&mockMessageHandler{err: errors.New("mongo unavailable")}, publisher)
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{RoutingKey: PaymentCmdCapture}); err == nil {
+		t.Error("expected error when the message cannot be rerouted")
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "marked permanent", err: fmt.Errorf("handle: %w", Permanent(errors.New("bad json"))), want: true},
+		{name: "invalid transition", err: fmt.Errorf("failed to capture payment: %w", &domain.InvalidTransitionError{}), want: true},
+		{name: "refused fare", err: &application.FareValidationError{TripID: "trip-1", Err: application.ErrTripAlreadyPaid}, want: true},
+		{name: "unsupported operation", err: fmt.Errorf("x402 refunds: %w", errors.ErrUnsupported), want: true},
+		{name: "not trip owner", err: &domain.TripError{TripID: "trip-1", Err: domain.ErrNotTripOwner}, want: true},
+		{name: "invalid trip ID", err: &domain.TripError{TripID: "x", Err: domain.ErrInvalidTripID, Cause: errors.New("bad hex")}, want: true},
+		{name: "payment declined", err: &domain.ProviderError{Provider: "x402", Op: "verify payment", Err: domain.ErrPaymentDeclined, Cause: errors.New("insufficient_funds")}, want: true},
+		{name: "missing refund request ID", err: fmt.Errorf("failed to refund payment: %w", domain.ErrRefundRequestIDRequired), want: true},
+		{name: "trip not found", err: &domain.TripError{TripID: "trip-1", Err: domain.ErrTripNotFound}, want: false},
+		{name: "provider unavailable", err: &domain.ProviderError{Provider: "stripe", Op: "refund payment", Err: domain.ErrProviderUnavailable, Cause: errors.New("503")}, want: false},
+		{name: "payment not found", err: domain.ErrPaymentNotFound, want: false},
+		{name: "infrastructure error", err: errors.New("connection reset"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanent(tt.err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
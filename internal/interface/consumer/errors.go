@@ -0,0 +1,53 @@
+package consumer
+
+import (
+	"errors"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// PermanentError marks a message that will fail the same way however often it is redelivered
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err as a PermanentError
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether a message that failed with err must be dead-lettered instead of retried.
+// Malformed messages, refused fares, requests the payment rules reject, commands from the wrong
+// user or driver and declined payments are permanent. Everything else, such as a trip not stored yet
+// (domain.ErrTripNotFound) or an unreachable database or provider (domain.ErrProviderUnavailable),
+// is assumed to be transient.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	var fare *application.FareValidationError
+	switch {
+	case errors.As(err, &permanent),
+		errors.As(err, &fare),
+		errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrInvalidCaptureAmount),
+		errors.Is(err, domain.ErrInvalidRefundAmount),
+		errors.Is(err, domain.ErrRefundRequestIDRequired),
+		errors.Is(err, domain.ErrInvalidTripID),
+		errors.Is(err, domain.ErrNotTripOwner),
+		errors.Is(err, domain.ErrNotTripDriver),
+		errors.Is(err, domain.ErrPaymentMethodMismatch),
+		errors.Is(err, domain.ErrPaymentDeclined),
+		errors.Is(err, application.ErrProviderNotFound),
+		errors.Is(err, errors.ErrUnsupported):
+		return true
+	}
+	return false
+}
@@ -10,6 +10,59 @@ import (
 	"github.com/ride4Low/payment-service/internal/application"
 )
 
+// Routing keys for payment commands that are not part of the shared contracts
+const (
+	PaymentCmdRefund    = "payment.cmd.refund"
+	PaymentCmdAuthorize = "payment.cmd.authorize"
+	PaymentCmdCapture   = "payment.cmd.capture"
+	PaymentCmdRelease   = "payment.cmd.release"
+
+	PaymentCmdCreateCryptoSession = "payment.cmd.create_crypto_session"
+	PaymentCmdPayWithCrypto       = "payment.cmd.pay_crypto"
+	PaymentCmdConfirmCash         = "payment.cmd.confirm_cash"
+)
+
+// CreateSessionData is the payload of an events.PaymentCmdCreateSession command. The optional
+// payment method and region select the provider; without them the configured defaults apply.
+type CreateSessionData struct {
+	events.PaymentSelectCardData
+	PaymentMethod string `json:"paymentMethod,omitempty"`
+	Region        string `json:"region,omitempty"`
+}
+
+// RefundPaymentData is the payload of a PaymentCmdRefund command. RequestID is chosen by the
+// sender once per refund and kept across retries, so a redelivered command never refunds twice.
+type RefundPaymentData struct {
+	PaymentID string `json:"paymentID"`
+	RequestID string `json:"requestID"`
+	Amount    int64  `json:"amount"` // amount to refund in the currency's minor units
+	Reason    string `json:"reason"`
+}
+
+// CapturePaymentData is the payload of a PaymentCmdCapture command
+type CapturePaymentData struct {
+	TripID string `json:"tripID"`
+	Amount int64  `json:"amount"` // final fare in the currency's minor units
+}
+
+// ReleasePaymentData is the payload of a PaymentCmdRelease command
+type ReleasePaymentData struct {
+	TripID string `json:"tripID"`
+}
+
+// PayWithCryptoData is the payload of a PaymentCmdPayWithCrypto command
+type PayWithCryptoData struct {
+	TripID         string `json:"tripID"`
+	UserID         string `json:"userID"`
+	PaymentPayload string `json:"paymentPayload"` // base64 encoded x402 payment payload signed by the rider's wallet
+}
+
+// ConfirmCashPaymentData is the payload of a PaymentCmdConfirmCash command sent by the driver app
+type ConfirmCashPaymentData struct {
+	TripID   string `json:"tripID"`
+	DriverID string `json:"driverID"`
+}
+
 // EventHandler handles incoming RabbitMQ messages for payment events
 type EventHandler struct {
 	paymentSvc application.PaymentService
@@ -25,35 +78,134 @@ func (h *EventHandler) Handle(ctx context.Context, msg amqp091.Delivery) error {
 	var message events.AmqpMessage
 
 	if msg.Body == nil {
-		return fmt.Errorf("message body is nil")
+		return Permanent(fmt.Errorf("message body is nil"))
 	}
 
 	if err := sonic.Unmarshal(msg.Body, &message); err != nil {
-		return fmt.Errorf("failed to unmarshal message: %v", err)
+		return Permanent(fmt.Errorf("failed to unmarshal message: %v", err))
 	}
 
 	switch msg.RoutingKey {
 	case events.PaymentCmdCreateSession:
 		return h.handleCreateSession(ctx, message)
+	case PaymentCmdRefund:
+		return h.handleRefund(ctx, message)
+	case PaymentCmdAuthorize:
+		return h.handleAuthorize(ctx, message)
+	case PaymentCmdCapture:
+		return h.handleCapture(ctx, message)
+	case PaymentCmdRelease:
+		return h.handleRelease(ctx, message)
+	case PaymentCmdCreateCryptoSession:
+		return h.handleCreateCryptoSession(ctx, message)
+	case PaymentCmdPayWithCrypto:
+		return h.handlePayWithCrypto(ctx, message)
+	case PaymentCmdConfirmCash:
+		return h.handleConfirmCash(ctx, message)
 	default:
-		return fmt.Errorf("unknown routing key: %s", msg.RoutingKey)
+		return Permanent(fmt.Errorf("unknown routing key: %s", msg.RoutingKey))
 	}
 }
 
 func (h *EventHandler) handleCreateSession(ctx context.Context, message events.AmqpMessage) error {
-	var payload events.PaymentSelectCardData
+	var payload CreateSessionData
 	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload: %v", err)
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
 	}
 
 	// Call application layer - publishing is handled there
-	err := h.paymentSvc.CreatePaymentSessionWithCard(
+	err := h.paymentSvc.CreatePaymentSessionForTrip(
 		ctx,
 		payload.TripID,
 		payload.UserID,
+		application.PaymentSelection{Method: payload.PaymentMethod, Region: payload.Region},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create payment session: %w", err)
 	}
 	return nil
 }
+
+func (h *EventHandler) handleRefund(ctx context.Context, message events.AmqpMessage) error {
+	var payload RefundPaymentData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.RefundPayment(ctx, payload.PaymentID, payload.RequestID, payload.Amount, payload.Reason); err != nil {
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handleAuthorize(ctx context.Context, message events.AmqpMessage) error {
+	var payload events.PaymentSelectCardData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.AuthorizePaymentWithCard(ctx, payload.TripID, payload.UserID); err != nil {
+		return fmt.Errorf("failed to authorize payment: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handleCapture(ctx context.Context, message events.AmqpMessage) error {
+	var payload CapturePaymentData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.CapturePayment(ctx, payload.TripID, payload.Amount); err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handleRelease(ctx context.Context, message events.AmqpMessage) error {
+	var payload ReleasePaymentData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.ReleasePayment(ctx, payload.TripID); err != nil {
+		return fmt.Errorf("failed to release payment: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handleCreateCryptoSession(ctx context.Context, message events.AmqpMessage) error {
+	var payload events.PaymentSelectCardData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.CreatePaymentSessionWithCrypto(ctx, payload.TripID, payload.UserID); err != nil {
+		return fmt.Errorf("failed to create crypto payment session: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handlePayWithCrypto(ctx context.Context, message events.AmqpMessage) error {
+	var payload PayWithCryptoData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.PayWithCrypto(ctx, payload.TripID, payload.UserID, payload.PaymentPayload); err != nil {
+		return fmt.Errorf("failed to pay with crypto: %w", err)
+	}
+	return nil
+}
+
+func (h *EventHandler) handleConfirmCash(ctx context.Context, message events.AmqpMessage) error {
+	var payload ConfirmCashPaymentData
+	if err := sonic.Unmarshal(message.Data, &payload); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal payload: %v", err))
+	}
+
+	if err := h.paymentSvc.ConfirmCashPayment(ctx, payload.TripID, payload.DriverID); err != nil {
+		return fmt.Errorf("failed to confirm cash payment: %w", err)
+	}
+	return nil
+}
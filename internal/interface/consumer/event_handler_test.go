@@ -7,12 +7,18 @@ import (
 	"github.com/bytedance/sonic"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/application"
 )
 
 // mockPaymentService is a mock implementation of application.PaymentService
 type mockPaymentService struct {
-	err    error
-	called bool
+	err       error
+	called    bool
+	refund    RefundPaymentData
+	capture   CapturePaymentData
+	release   ReleasePaymentData
+	crypto    PayWithCryptoData
+	selection application.PaymentSelection
 }
 
 func (m *mockPaymentService) CreatePaymentSession(ctx context.Context, tripID, userID, driverID string, amount int64, currency string) error {
@@ -31,6 +37,56 @@ func (m *mockPaymentService) CreatePaymentSessionWithCard(ctx context.Context, t
 	return nil
 }
 
+func (m *mockPaymentService) CreatePaymentSessionForTrip(ctx context.Context, tripID, userID string, selection application.PaymentSelection) error {
+	m.called = true
+	m.selection = selection
+	return m.err
+}
+
+func (m *mockPaymentService) ConfirmCashPayment(ctx context.Context, tripID, driverID string) error {
+	m.called = true
+	return m.err
+}
+
+func (m *mockPaymentService) HandleProviderEvent(ctx context.Context, event application.ProviderEvent) error {
+	m.called = true
+	return m.err
+}
+
+func (m *mockPaymentService) RefundPayment(ctx context.Context, paymentID, requestID string, amount int64, reason string) error {
+	m.called = true
+	m.refund = RefundPaymentData{PaymentID: paymentID, RequestID: requestID, Amount: amount, Reason: reason}
+	return m.err
+}
+
+func (m *mockPaymentService) AuthorizePaymentWithCard(ctx context.Context, tripID, userID string) error {
+	m.called = true
+	return m.err
+}
+
+func (m *mockPaymentService) CapturePayment(ctx context.Context, tripID string, amount int64) error {
+	m.called = true
+	m.capture = CapturePaymentData{TripID: tripID, Amount: amount}
+	return m.err
+}
+
+func (m *mockPaymentService) ReleasePayment(ctx context.Context, tripID string) error {
+	m.called = true
+	m.release = ReleasePaymentData{TripID: tripID}
+	return m.err
+}
+
+func (m *mockPaymentService) CreatePaymentSessionWithCrypto(ctx context.Context, tripID, userID string) error {
+	m.called = true
+	return m.err
+}
+
+func (m *mockPaymentService) PayWithCrypto(ctx context.Context, tripID, userID, paymentPayload string) error {
+	m.called = true
+	m.crypto = PayWithCryptoData{TripID: tripID, UserID: userID, PaymentPayload: paymentPayload}
+	return m.err
+}
+
 func TestNewEventHandler(t *testing.T) {
 	mockSvc := &mockPaymentService{}
 	handler := NewEventHandler(mockSvc)
@@ -76,6 +132,9 @@ func TestEventHandler_Handle_InvalidJSON(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for invalid JSON")
 	}
+	if !IsPermanent(err) {
+		t.Errorf("expected invalid JSON to be a permanent failure, got %v", err)
+	}
 }
 
 func TestEventHandler_Handle_UnknownRoutingKey(t *testing.T) {
@@ -97,6 +156,9 @@ func TestEventHandler_Handle_UnknownRoutingKey(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for unknown routing key")
 	}
+	if !IsPermanent(err) {
+		t.Errorf("expected unknown routing key to be a permanent failure, got %v", err)
+	}
 }
 
 func TestEventHandler_Handle_CreateSessionRoutingKey(t *testing.T) {
@@ -119,3 +181,88 @@ func TestEventHandler_Handle_CreateSessionRoutingKey(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestEventHandler_Handle_RefundRoutingKey(t *testing.T) {
+	mockSvc := &mockPaymentService{}
+	handler := NewEventHandler(mockSvc)
+
+	data, _ := sonic.Marshal(RefundPaymentData{PaymentID: "payment-1", RequestID: "refund-request-1", Amount: 500, Reason: "requested_by_customer"})
+	body, _ := sonic.Marshal(events.AmqpMessage{OwnerID: "user-123", Data: data})
+
+	msg := amqp091.Delivery{
+		Body:       body,
+		RoutingKey: PaymentCmdRefund,
+	}
+
+	if err := handler.Handle(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mockSvc.refund.PaymentID != "payment-1" || mockSvc.refund.RequestID != "refund-request-1" || mockSvc.refund.Amount != 500 || mockSvc.refund.Reason != "requested_by_customer" {
+		t.Errorf("unexpected refund call: %+v", mockSvc.refund)
+	}
+}
+
+func TestEventHandler_Handle_CaptureRoutingKey(t *testing.T) {
+	mockSvc := &mockPaymentService{}
+	handler := NewEventHandler(mockSvc)
+
+	data, _ := sonic.Marshal(CapturePaymentData{TripID: "trip-1", Amount: 1850})
+	body, _ := sonic.Marshal(events.AmqpMessage{OwnerID: "user-123", Data: data})
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{Body: body, RoutingKey: PaymentCmdCapture}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mockSvc.capture.TripID != "trip-1" || mockSvc.capture.Amount != 1850 {
+		t.Errorf("unexpected capture call: %+v", mockSvc.capture)
+	}
+}
+
+func TestEventHandler_Handle_ReleaseRoutingKey(t *testing.T) {
+	mockSvc := &mockPaymentService{}
+	handler := NewEventHandler(mockSvc)
+
+	data, _ := sonic.Marshal(ReleasePaymentData{TripID: "trip-1"})
+	body, _ := sonic.Marshal(events.AmqpMessage{OwnerID: "user-123", Data: data})
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{Body: body, RoutingKey: PaymentCmdRelease}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mockSvc.release.TripID != "trip-1" {
+		t.Errorf("unexpected release call: %+v", mockSvc.release)
+	}
+}
+
+func TestEventHandler_Handle_PayWithCryptoRoutingKey(t *testing.T) {
+	mockSvc := &mockPaymentService{}
+	handler := NewEventHandler(mockSvc)
+
+	data, _ := sonic.Marshal(PayWithCryptoData{TripID: "trip-1", UserID: "user-123", PaymentPayload: "eyJ4NDAyVmVyc2lvbiI6MX0="})
+	body, _ := sonic.Marshal(events.AmqpMessage{OwnerID: "user-123", Data: data})
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{Body: body, RoutingKey: PaymentCmdPayWithCrypto}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mockSvc.crypto.TripID != "trip-1" || mockSvc.crypto.PaymentPayload != "eyJ4NDAyVmVyc2lvbiI6MX0=" {
+		t.Errorf("unexpected crypto payment call: %+v", mockSvc.crypto)
+	}
+}
+
+func TestEventHandler_Handle_CreateSessionPaymentMethod(t *testing.T) {
+	mockSvc := &mockPaymentService{}
+	handler := NewEventHandler(mockSvc)
+
+	data := []byte(`{"tripID":"trip-1","userID":"user-123","paymentMethod":"cash","region":"eu"}`)
+	body, _ := sonic.Marshal(events.AmqpMessage{OwnerID: "user-123", Data: data})
+
+	if err := handler.Handle(context.Background(), amqp091.Delivery{Body: body, RoutingKey: events.PaymentCmdCreateSession}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mockSvc.selection.Method != "cash" || mockSvc.selection.Region != "eu" {
+		t.Errorf("unexpected payment selection: %+v", mockSvc.selection)
+	}
+}
@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthInterceptor authenticates every call with the API key sent as "authorization: Bearer <key>"
+// metadata and puts the principal it acts for in the context. Gateway keys must forward the verified
+// rider or driver in the x-principal-role and x-principal-id metadata.
+func UnaryAuthInterceptor(keys *auth.KeyStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		client, ok := keys.Authenticate(bearerKey(md))
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid API key")
+		}
+		principal, err := auth.ResolvePrincipal(client, firstValue(md, auth.PrincipalRoleHeader), firstValue(md, auth.PrincipalIDHeader))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = auth.WithPrincipal(auth.WithClient(ctx, client), principal)
+		return handler(ctx, req)
+	}
+}
+
+func bearerKey(md metadata.MD) string {
+	scheme, key, ok := strings.Cut(firstValue(md, "authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+// firstValue returns the first value of a metadata key; metadata keys are case-insensitive
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	keys := auth.NewKeyStore()
+	keys.Add("admin-key", auth.Client{Name: "ops", Admin: true})
+	keys.Add("gateway-key", auth.Client{Name: "gateway"})
+	interceptor := UnaryAuthInterceptor(keys)
+
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		want      codes.Code
+		principal auth.Principal
+	}{
+		{
+			name:      "admin",
+			md:        metadata.Pairs("authorization", "Bearer admin-key", "x-principal-role", "rider", "x-principal-id", "user-1"),
+			want:      codes.OK,
+			principal: auth.Principal{Role: auth.RoleAdmin, ID: "ops"},
+		},
+		{
+			name:      "gateway forwards rider",
+			md:        metadata.Pairs("authorization", "Bearer gateway-key", "x-principal-role", "rider", "x-principal-id", "user-1"),
+			want:      codes.OK,
+			principal: auth.Principal{Role: auth.RoleRider, ID: "user-1"},
+		},
+		{
+			name: "gateway without principal",
+			md:   metadata.Pairs("authorization", "Bearer gateway-key"),
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "gateway claims admin",
+			md:   metadata.Pairs("authorization", "Bearer gateway-key", "x-principal-role", "admin", "x-principal-id", "ops"),
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "principal without key",
+			md:   metadata.Pairs("x-principal-role", "rider", "x-principal-id", "user-1"),
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "unknown key",
+			md:   metadata.Pairs("authorization", "Bearer other-key", "x-principal-role", "rider", "x-principal-id", "user-1"),
+			want: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen auth.Principal
+			handler := func(ctx context.Context, req any) (any, error) {
+				seen, _ = auth.PrincipalFromContext(ctx)
+				return nil, nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentQueryService/GetPayment"}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("expected code %s, got %s (%v)", tt.want, got, err)
+			}
+			if seen != tt.principal {
+				t.Errorf("expected principal %+v, got %+v", tt.principal, seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	paymentv1 "github.com/ride4Low/payment-service/api/payment/v1"
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server serves the payment query use cases over gRPC. Callers are authenticated by
+// UnaryAuthInterceptor and only see the payments of the principal they act for.
+type Server struct {
+	paymentv1.UnimplementedPaymentQueryServiceServer
+	queries application.PaymentQueryService
+}
+
+// NewServer creates a new gRPC payment query server
+func NewServer(queries application.PaymentQueryService) *Server {
+	return &Server{queries: queries}
+}
+
+// Register adds the payment query service to the gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	paymentv1.RegisterPaymentQueryServiceServer(registrar, s)
+}
+
+// GetPayment returns a payment by its ID
+func (s *Server) GetPayment(ctx context.Context, req *paymentv1.GetPaymentRequest) (*paymentv1.Payment, error) {
+	if req.GetPaymentId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_id is required")
+	}
+	payment, err := s.queries.GetPayment(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := authorizePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+	return toPaymentProto(payment), nil
+}
+
+// GetPaymentByTrip returns the latest payment for a trip
+func (s *Server) GetPaymentByTrip(ctx context.Context, req *paymentv1.GetPaymentByTripRequest) (*paymentv1.Payment, error) {
+	if req.GetTripId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trip_id is required")
+	}
+	payment, err := s.queries.GetPaymentByTrip(ctx, req.GetTripId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := authorizePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+	return toPaymentProto(payment), nil
+}
+
+// ListPaymentsByUser returns a page of a rider's payments, newest first
+func (s *Server) ListPaymentsByUser(ctx context.Context, req *paymentv1.ListPaymentsByUserRequest) (*paymentv1.ListPaymentsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if err := authorizeOwner(ctx, auth.RoleRider, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	list, err := s.queries.ListPaymentsByUser(ctx, req.GetUserId(), int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toListResponse(list), nil
+}
+
+// ListPaymentsByDriver returns a page of the payments for a driver's trips, newest first
+func (s *Server) ListPaymentsByDriver(ctx context.Context, req *paymentv1.ListPaymentsByDriverRequest) (*paymentv1.ListPaymentsResponse, error) {
+	if req.GetDriverId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "driver_id is required")
+	}
+	if err := authorizeOwner(ctx, auth.RoleDriver, req.GetDriverId()); err != nil {
+		return nil, err
+	}
+	list, err := s.queries.ListPaymentsByDriver(ctx, req.GetDriverId(), int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toListResponse(list), nil
+}
+
+// authorizePayment hides payments the principal does not own as not found, so callers cannot
+// probe for payment or trip IDs
+func authorizePayment(ctx context.Context, payment *domain.Payment) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	if !principal.Owns(payment) {
+		return status.Error(codes.NotFound, domain.ErrPaymentNotFound.Error())
+	}
+	return nil
+}
+
+// authorizeOwner only lets admins and the rider or driver themselves list their payments
+func authorizeOwner(ctx context.Context, role auth.Role, id string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	if !principal.IsAdmin() && (principal.Role != role || principal.ID != id) {
+		return status.Error(codes.PermissionDenied, "payments of another "+string(role)+" requested")
+	}
+	return nil
+}
+
+// toStatus maps domain errors to gRPC status codes; unexpected errors are logged and not exposed
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	log.Printf("payment query failed: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func toListResponse(list *application.PaymentList) *paymentv1.ListPaymentsResponse {
+	response := &paymentv1.ListPaymentsResponse{
+		Payments:      make([]*paymentv1.Payment, 0, len(list.Payments)),
+		NextPageToken: list.NextPageToken,
+	}
+	for _, payment := range list.Payments {
+		response.Payments = append(response.Payments, toPaymentProto(payment))
+	}
+	return response
+}
+
+func toPaymentProto(payment *domain.Payment) *paymentv1.Payment {
+	return &paymentv1.Payment{
+		Id:                  payment.ID,
+		TripId:              payment.TripID,
+		UserId:              payment.UserID,
+		DriverId:            payment.DriverID,
+		AmountMinor:         payment.Amount,
+		AmountDecimal:       payment.Money().Decimal(),
+		Currency:            payment.Currency,
+		Status:              string(payment.Status),
+		CaptureMethod:       string(payment.CaptureMethod),
+		Provider:            payment.Provider,
+		RefundedAmountMinor: payment.RefundedAmount,
+		FailureReason:       payment.FailureReason,
+		TransactionHash:     payment.TransactionHash,
+		CreatedAt:           timestamppb.New(payment.CreatedAt),
+		UpdatedAt:           timestamppb.New(payment.UpdatedAt),
+	}
+}
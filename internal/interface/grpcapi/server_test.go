@@ -0,0 +1,267 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	paymentv1 "github.com/ride4Low/payment-service/api/payment/v1"
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockPaymentQueryService is a mock implementation of application.PaymentQueryService for testing
+type mockPaymentQueryService struct {
+	payment   *domain.Payment
+	list      *application.PaymentList
+	err       error
+	ownerID   string
+	pageSize  int
+	pageToken string
+}
+
+func (m *mockPaymentQueryService) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	return m.payment, m.err
+}
+
+func (m *mockPaymentQueryService) GetPaymentByTrip(ctx context.Context, tripID string) (*domain.Payment, error) {
+	return m.payment, m.err
+}
+
+func (m *mockPaymentQueryService) ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*application.PaymentList, error) {
+	m.ownerID, m.pageSize, m.pageToken = userID, pageSize, pageToken
+	return m.list, m.err
+}
+
+func (m *mockPaymentQueryService) ListPaymentsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*application.PaymentList, error) {
+	m.ownerID, m.pageSize, m.pageToken = driverID, pageSize, pageToken
+	return m.list, m.err
+}
+
+func newTestPayment() *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 1050, "USD", "stripe")
+	payment.ID = "payment-1"
+	payment.Status = domain.PaymentStatusCaptured
+	payment.RefundedAmount = 250
+	payment.CreatedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return payment
+}
+
+func adminContext() context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Role: auth.RoleAdmin, ID: "ops"})
+}
+
+func principalContext(role auth.Role, id string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Role: role, ID: id})
+}
+
+func TestServer_GetPayment(t *testing.T) {
+	server := NewServer(&mockPaymentQueryService{payment: newTestPayment()})
+
+	payment, err := server.GetPayment(adminContext(), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payment.GetId() != "payment-1" || payment.GetTripId() != "trip-1" || payment.GetStatus() != "captured" {
+		t.Errorf("unexpected payment: %v", payment)
+	}
+	if payment.GetAmountMinor() != 1050 || payment.GetAmountDecimal() != "10.50" || payment.GetCurrency() != "USD" {
+		t.Errorf("expected 1050 / 10.50 USD, got %d / %s %s", payment.GetAmountMinor(), payment.GetAmountDecimal(), payment.GetCurrency())
+	}
+	if payment.GetRefundedAmountMinor() != 250 {
+		t.Errorf("expected refunded 250, got %d", payment.GetRefundedAmountMinor())
+	}
+	if !payment.GetCreatedAt().AsTime().Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected created_at %v", payment.GetCreatedAt().AsTime())
+	}
+}
+
+func TestServer_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(s *Server) error
+		err  error
+		want codes.Code
+	}{
+		{
+			name: "missing payment ID",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(adminContext(), &paymentv1.GetPaymentRequest{})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "missing trip ID",
+			call: func(s *Server) error {
+				_, err := s.GetPaymentByTrip(adminContext(), &paymentv1.GetPaymentByTripRequest{})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "missing driver ID",
+			call: func(s *Server) error {
+				_, err := s.ListPaymentsByDriver(adminContext(), &paymentv1.ListPaymentsByDriverRequest{})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "payment not found",
+			call: func(s *Server) error {
+				_, err := s.GetPaymentByTrip(adminContext(), &paymentv1.GetPaymentByTripRequest{TripId: "trip-1"})
+				return err
+			},
+			err:  fmt.Errorf("%w: trip-1", domain.ErrPaymentNotFound),
+			want: codes.NotFound,
+		},
+		{
+			name: "invalid page token",
+			call: func(s *Server) error {
+				_, err := s.ListPaymentsByUser(adminContext(), &paymentv1.ListPaymentsByUserRequest{UserId: "user-1", PageToken: "bogus"})
+				return err
+			},
+			err:  fmt.Errorf("%w: bogus", domain.ErrInvalidPageToken),
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "deadline exceeded",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(adminContext(), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+				return err
+			},
+			err:  fmt.Errorf("failed to get payment: %w", context.DeadlineExceeded),
+			want: codes.DeadlineExceeded,
+		},
+		{
+			name: "database failure",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(adminContext(), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+				return err
+			},
+			err:  errors.New("connection refused"),
+			want: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(&mockPaymentQueryService{err: tt.err})
+
+			err := tt.call(server)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("expected code %s, got %s (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestServer_ListPaymentsByUser(t *testing.T) {
+	queries := &mockPaymentQueryService{list: &application.PaymentList{
+		Payments:      []*domain.Payment{newTestPayment()},
+		NextPageToken: "payment-1",
+	}}
+	server := NewServer(queries)
+
+	response, err := server.ListPaymentsByUser(adminContext(), &paymentv1.ListPaymentsByUserRequest{UserId: "user-1", PageSize: 1, PageToken: "payment-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if queries.ownerID != "user-1" || queries.pageSize != 1 || queries.pageToken != "payment-2" {
+		t.Errorf("unexpected query %s %d %s", queries.ownerID, queries.pageSize, queries.pageToken)
+	}
+	if len(response.GetPayments()) != 1 || response.GetNextPageToken() != "payment-1" {
+		t.Errorf("unexpected response: %v", response)
+	}
+}
+
+func TestServer_Ownership(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(s *Server) error
+		want codes.Code
+	}{
+		{
+			name: "rider gets own payment",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(principalContext(auth.RoleRider, "user-1"), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "driver gets own trip's payment",
+			call: func(s *Server) error {
+				_, err := s.GetPaymentByTrip(principalContext(auth.RoleDriver, "driver-1"), &paymentv1.GetPaymentByTripRequest{TripId: "trip-1"})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "other rider's payment is hidden",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(principalContext(auth.RoleRider, "user-2"), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+				return err
+			},
+			want: codes.NotFound,
+		},
+		{
+			name: "rider ID used as driver",
+			call: func(s *Server) error {
+				_, err := s.GetPaymentByTrip(principalContext(auth.RoleDriver, "user-1"), &paymentv1.GetPaymentByTripRequest{TripId: "trip-1"})
+				return err
+			},
+			want: codes.NotFound,
+		},
+		{
+			name: "other rider's list",
+			call: func(s *Server) error {
+				_, err := s.ListPaymentsByUser(principalContext(auth.RoleRider, "user-2"), &paymentv1.ListPaymentsByUserRequest{UserId: "user-1"})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "rider lists driver payments",
+			call: func(s *Server) error {
+				_, err := s.ListPaymentsByDriver(principalContext(auth.RoleRider, "driver-1"), &paymentv1.ListPaymentsByDriverRequest{DriverId: "driver-1"})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "driver lists own payments",
+			call: func(s *Server) error {
+				_, err := s.ListPaymentsByDriver(principalContext(auth.RoleDriver, "driver-1"), &paymentv1.ListPaymentsByDriverRequest{DriverId: "driver-1"})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "no principal",
+			call: func(s *Server) error {
+				_, err := s.GetPayment(context.Background(), &paymentv1.GetPaymentRequest{PaymentId: "payment-1"})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(&mockPaymentQueryService{payment: newTestPayment(), list: &application.PaymentList{}})
+
+			if got := status.Code(tt.call(server)); got != tt.want {
+				t.Errorf("expected code %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// Error codes returned in the error envelope; clients branch on these rather than on messages
+const (
+	CodeInvalidArgument     = "invalid_argument"
+	CodeUnauthenticated     = "unauthenticated"
+	CodeNotFound            = "not_found"
+	CodeForbidden           = "forbidden"
+	CodeConflict            = "conflict"
+	CodeUnprocessable       = "unprocessable"
+	CodePaymentDeclined     = "payment_declined"
+	CodeProviderUnavailable = "provider_unavailable"
+	CodeTimeout             = "timeout"
+	CodeInternal            = "internal"
+)
+
+// FieldError describes a request field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// errorResponse is the envelope of every error returned by the payment API
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// errorMapping maps a domain or application error to its HTTP status and error code, checked in order
+var errorMapping = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrPaymentNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrTripNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrInvalidTripID, http.StatusBadRequest, CodeInvalidArgument},
+	{domain.ErrInvalidPageToken, http.StatusBadRequest, CodeInvalidArgument},
+	{domain.ErrRefundRequestIDRequired, http.StatusBadRequest, CodeInvalidArgument},
+	{domain.ErrNotTripOwner, http.StatusForbidden, CodeForbidden},
+	{domain.ErrNotTripDriver, http.StatusForbidden, CodeForbidden},
+	{application.ErrTripAlreadyPaid, http.StatusConflict, CodeConflict},
+	{application.ErrOpenPaymentMismatch, http.StatusConflict, CodeConflict},
+	{domain.ErrPaymentAlreadyExists, http.StatusConflict, CodeConflict},
+	{domain.ErrInvalidTransition, http.StatusConflict, CodeConflict},
+	{application.ErrTripNotPayable, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{application.ErrFareExpired, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{application.ErrFareOutOfRange, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{application.ErrDriverNotAssigned, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{application.ErrProviderNotFound, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{domain.ErrInvalidRefundAmount, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{domain.ErrInvalidCaptureAmount, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{domain.ErrUnsupportedCurrency, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{domain.ErrPaymentMethodMismatch, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{errors.ErrUnsupported, http.StatusUnprocessableEntity, CodeUnprocessable},
+	{domain.ErrPaymentDeclined, http.StatusPaymentRequired, CodePaymentDeclined},
+	{domain.ErrProviderUnavailable, http.StatusServiceUnavailable, CodeProviderUnavailable},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout},
+}
+
+// writeError writes err in the error envelope. Unexpected errors are logged and reported as
+// internal without their message, which may carry infrastructure details.
+func writeError(w http.ResponseWriter, err error) {
+	for _, mapping := range errorMapping {
+		if errors.Is(err, mapping.err) {
+			writeErrorResponse(w, mapping.status, errorBody{Code: mapping.code, Message: err.Error()})
+			return
+		}
+	}
+
+	log.Printf("payment request failed: %v", err)
+	writeErrorResponse(w, http.StatusInternalServerError, errorBody{Code: CodeInternal, Message: "internal error"})
+}
+
+func writeValidationError(w http.ResponseWriter, fields []FieldError) {
+	writeErrorResponse(w, http.StatusBadRequest, errorBody{Code: CodeInvalidArgument, Message: "request validation failed", Fields: fields})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, body errorBody) {
+	writeJSON(w, status, errorResponse{Error: body})
+}
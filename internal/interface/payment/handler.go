@@ -0,0 +1,362 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+)
+
+// maxRequestBodyBytes bounds JSON request bodies
+const maxRequestBodyBytes = 64 << 10
+
+// IdempotencyKeyHeader carries the client's ID for a refund; retries with the same key refund only once
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header, which is forwarded to the provider
+const maxIdempotencyKeyLength = 200
+
+// Handler exposes checkouts, payment lookups and refunds as a JSON API for dashboards and partners.
+// Routes must be served behind auth.RequirePrincipal: riders and drivers only reach their own
+// payments, and refunds are for admins.
+type Handler struct {
+	paymentSvc application.PaymentService
+	queries    application.PaymentQueryService
+}
+
+// NewHandler creates a new payment HTTP handler
+func NewHandler(paymentSvc application.PaymentService, queries application.PaymentQueryService) *Handler {
+	return &Handler{paymentSvc: paymentSvc, queries: queries}
+}
+
+// Register adds the payment routes and their OpenAPI document to the mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	routes := h.routes()
+	for _, route := range routes {
+		mux.HandleFunc(route.Method+" "+route.Path, route.handler)
+	}
+
+	spec := OpenAPI(routes)
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	})
+}
+
+// routes describes every endpoint once, for both the mux and the OpenAPI document
+func (h *Handler) routes() []Route {
+	pageParams := []Parameter{
+		{Name: "pageSize", In: "query", Description: "payments per page, 1-100, defaults to 20", Type: "integer"},
+		{Name: "pageToken", In: "query", Description: "nextPageToken of the previous page", Type: "string"},
+	}
+	return []Route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/payments/checkout",
+			OperationID: "createCheckout",
+			Summary:     "Start a payment for the calling rider's trip fare with their chosen method",
+			Request:     checkoutRequest{},
+			Responses:   map[int]any{http.StatusCreated: paymentResponse{}},
+			handler:     h.createCheckout,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/payments",
+			OperationID: "listPayments",
+			Summary:     "List the calling rider's or driver's payments, newest first",
+			Parameters: append([]Parameter{
+				{Name: "userID", In: "query", Description: "admins only: rider whose payments are listed; exclusive with driverID", Type: "string"},
+				{Name: "driverID", In: "query", Description: "admins only: driver whose trip payments are listed; exclusive with userID", Type: "string"},
+			}, pageParams...),
+			Responses: map[int]any{http.StatusOK: paymentListResponse{}},
+			handler:   h.listPayments,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/payments/{paymentID}",
+			OperationID: "getPayment",
+			Summary:     "Get a payment",
+			Parameters:  []Parameter{{Name: "paymentID", In: "path", Required: true, Type: "string"}},
+			Responses:   map[int]any{http.StatusOK: paymentResponse{}},
+			handler:     h.getPayment,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/payments/{paymentID}/refunds",
+			OperationID: "refundPayment",
+			Summary:     "Refund part or all of a captured payment; admins only",
+			Parameters: []Parameter{
+				{Name: "paymentID", In: "path", Required: true, Type: "string"},
+				{Name: IdempotencyKeyHeader, In: "header", Description: "client-chosen ID of the refund, reused on retries so it is issued once", Required: true, Type: "string"},
+			},
+			Request:   refundRequest{},
+			Responses: map[int]any{http.StatusOK: paymentResponse{}},
+			handler:   h.refundPayment,
+		},
+	}
+}
+
+type checkoutRequest struct {
+	TripID string `json:"tripID"`
+	Method string `json:"method,omitempty" doc:"card, crypto or cash; defaults to the provider configured for the region or currency"`
+	Region string `json:"region,omitempty" doc:"rider's region, selects the default provider; the fare currency is the trip's"`
+}
+
+func (r checkoutRequest) validate() []FieldError {
+	var errs []FieldError
+	if r.TripID == "" {
+		errs = append(errs, FieldError{Field: "tripID", Message: "is required"})
+	}
+	switch strings.ToLower(r.Method) {
+	case "", application.PaymentMethodCard, application.PaymentMethodCrypto, application.PaymentMethodCash:
+	default:
+		errs = append(errs, FieldError{Field: "method", Message: "must be card, crypto or cash"})
+	}
+	return errs
+}
+
+type refundRequest struct {
+	Amount int64  `json:"amount" doc:"amount to refund in minor units of the payment currency"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (r refundRequest) validate() []FieldError {
+	if r.Amount <= 0 {
+		return []FieldError{{Field: "amount", Message: "must be positive"}}
+	}
+	return nil
+}
+
+// paymentResponse is a payment as shown to dashboards and partners; amounts are in minor units
+type paymentResponse struct {
+	ID              string    `json:"id"`
+	TripID          string    `json:"tripID"`
+	UserID          string    `json:"userID"`
+	DriverID        string    `json:"driverID"`
+	Amount          int64     `json:"amount"`
+	AmountDecimal   string    `json:"amountDecimal" doc:"amount in major units, e.g. 10.50"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"`
+	CaptureMethod   string    `json:"captureMethod"`
+	Provider        string    `json:"provider"`
+	SessionID       string    `json:"sessionID,omitempty" doc:"provider checkout session the rider completes"`
+	RefundedAmount  int64     `json:"refundedAmount"`
+	FailureReason   string    `json:"failureReason,omitempty"`
+	TransactionHash string    `json:"transactionHash,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type paymentListResponse struct {
+	Payments      []paymentResponse `json:"payments"`
+	NextPageToken string            `json:"nextPageToken,omitempty"`
+}
+
+func (h *Handler) createCheckout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	if principal.Role != auth.RoleRider {
+		writeErrorResponse(w, http.StatusForbidden, errorBody{Code: CodeForbidden, Message: "only riders can check out"})
+		return
+	}
+
+	var req checkoutRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	// The rider is the authenticated principal, so no one can start a payment for another rider's trip
+	selection := application.PaymentSelection{Method: req.Method, Region: req.Region}
+	if err := h.paymentSvc.CreatePaymentSessionForTrip(r.Context(), req.TripID, principal.ID, selection); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	payment, err := h.queries.GetPaymentByTrip(r.Context(), req.TripID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toPaymentResponse(payment))
+}
+
+func (h *Handler) getPayment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	paymentID := r.PathValue("paymentID")
+	payment, err := h.queries.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Other riders' and drivers' payments are reported as missing so their IDs cannot be probed
+	if !principal.Owns(payment) {
+		writeError(w, fmt.Errorf("%w: %s", domain.ErrPaymentNotFound, paymentID))
+		return
+	}
+	writeJSON(w, http.StatusOK, toPaymentResponse(payment))
+}
+
+func (h *Handler) listPayments(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	userID, driverID := query.Get("userID"), query.Get("driverID")
+	if !principal.IsAdmin() {
+		if userID != "" || driverID != "" {
+			writeErrorResponse(w, http.StatusForbidden, errorBody{Code: CodeForbidden, Message: "userID and driverID are for admins; riders and drivers list their own payments"})
+			return
+		}
+		if principal.Role == auth.RoleRider {
+			userID = principal.ID
+		} else {
+			driverID = principal.ID
+		}
+	}
+
+	var errs []FieldError
+	if (userID == "") == (driverID == "") {
+		errs = append(errs, FieldError{Field: "userID", Message: "exactly one of userID and driverID is required"})
+	}
+	pageSize := 0
+	if raw := query.Get("pageSize"); raw != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(raw); err != nil || pageSize < 1 || pageSize > 100 {
+			errs = append(errs, FieldError{Field: "pageSize", Message: "must be between 1 and 100"})
+		}
+	}
+	if len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+
+	var list *application.PaymentList
+	var err error
+	if userID != "" {
+		list, err = h.queries.ListPaymentsByUser(r.Context(), userID, pageSize, query.Get("pageToken"))
+	} else {
+		list, err = h.queries.ListPaymentsByDriver(r.Context(), driverID, pageSize, query.Get("pageToken"))
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := paymentListResponse{
+		Payments:      make([]paymentResponse, 0, len(list.Payments)),
+		NextPageToken: list.NextPageToken,
+	}
+	for _, payment := range list.Payments {
+		response.Payments = append(response.Payments, toPaymentResponse(payment))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) refundPayment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	if !principal.IsAdmin() {
+		writeErrorResponse(w, http.StatusForbidden, errorBody{Code: CodeForbidden, Message: "admin API key required"})
+		return
+	}
+
+	requestID := r.Header.Get(IdempotencyKeyHeader)
+	if requestID == "" || len(requestID) > maxIdempotencyKeyLength {
+		writeValidationError(w, []FieldError{{Field: IdempotencyKeyHeader, Message: "header is required, at most 200 characters"}})
+		return
+	}
+
+	var req refundRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	paymentID := r.PathValue("paymentID")
+	if err := h.paymentSvc.RefundPayment(r.Context(), paymentID, requestID, req.Amount, req.Reason); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	payment, err := h.queries.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPaymentResponse(payment))
+}
+
+// requirePrincipal returns the principal auth.RequirePrincipal put in the request context, writing
+// the error response and returning false when the route was served without it
+func requirePrincipal(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, errorBody{Code: CodeUnauthenticated, Message: "authentication required"})
+	}
+	return principal, ok
+}
+
+// validator is a request body that checks its own fields
+type validator interface {
+	validate() []FieldError
+}
+
+// decodeRequest decodes the JSON body into req and validates it, writing the error response
+// and returning false when the request is rejected
+func decodeRequest(w http.ResponseWriter, r *http.Request, req validator) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errorBody{Code: CodeInvalidArgument, Message: "invalid request body: " + err.Error()})
+		return false
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationError(w, errs)
+		return false
+	}
+	return true
+}
+
+func toPaymentResponse(payment *domain.Payment) paymentResponse {
+	return paymentResponse{
+		ID:              payment.ID,
+		TripID:          payment.TripID,
+		UserID:          payment.UserID,
+		DriverID:        payment.DriverID,
+		Amount:          payment.Amount,
+		AmountDecimal:   payment.Money().Decimal(),
+		Currency:        payment.Currency,
+		Status:          string(payment.Status),
+		CaptureMethod:   string(payment.CaptureMethod),
+		Provider:        payment.Provider,
+		SessionID:       payment.ProviderSessionID,
+		RefundedAmount:  payment.RefundedAmount,
+		FailureReason:   payment.FailureReason,
+		TransactionHash: payment.TransactionHash,
+		CreatedAt:       payment.CreatedAt,
+		UpdatedAt:       payment.UpdatedAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+)
+
+// mockPaymentService is a mock implementation of application.PaymentService
+type mockPaymentService struct {
+	application.PaymentService
+	err       error
+	tripID    string
+	userID    string
+	selection application.PaymentSelection
+	paymentID string
+	requestID string
+	amount    int64
+	reason    string
+}
+
+func (m *mockPaymentService) CreatePaymentSessionForTrip(ctx context.Context, tripID, userID string, selection application.PaymentSelection) error {
+	m.tripID, m.userID, m.selection = tripID, userID, selection
+	return m.err
+}
+
+func (m *mockPaymentService) RefundPayment(ctx context.Context, paymentID, requestID string, amount int64, reason string) error {
+	m.paymentID, m.requestID, m.amount, m.reason = paymentID, requestID, amount, reason
+	return m.err
+}
+
+// mockPaymentQueryService is a mock implementation of application.PaymentQueryService
+type mockPaymentQueryService struct {
+	payment   *domain.Payment
+	list      *application.PaymentList
+	err       error
+	ownerID   string
+	pageSize  int
+	pageToken string
+}
+
+func (m *mockPaymentQueryService) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	return m.payment, m.err
+}
+
+func (m *mockPaymentQueryService) GetPaymentByTrip(ctx context.Context, tripID string) (*domain.Payment, error) {
+	return m.payment, m.err
+}
+
+func (m *mockPaymentQueryService) ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*application.PaymentList, error) {
+	m.ownerID, m.pageSize, m.pageToken = userID, pageSize, pageToken
+	return m.list, m.err
+}
+
+func (m *mockPaymentQueryService) ListPaymentsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*application.PaymentList, error) {
+	m.ownerID, m.pageSize, m.pageToken = driverID, pageSize, pageToken
+	return m.list, m.err
+}
+
+func newTestPayment() *domain.Payment {
+	payment := domain.NewPayment("trip-1", "user-1", "driver-1", 1050, "USD", "stripe")
+	payment.ID = "payment-1"
+	payment.ProviderSessionID = "cs_test_1"
+	payment.CreatedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return payment
+}
+
+var (
+	rider  = auth.Principal{Role: auth.RoleRider, ID: "user-1"}
+	driver = auth.Principal{Role: auth.RoleDriver, ID: "driver-1"}
+	admin  = auth.Principal{Role: auth.RoleAdmin, ID: "ops"}
+)
+
+// serve sends a request on behalf of the rider of the test payment
+func serve(svc application.PaymentService, queries application.PaymentQueryService, method, target, body string) *httptest.ResponseRecorder {
+	return serveAs(rider, svc, queries, method, target, body)
+}
+
+func serveAs(principal auth.Principal, svc application.PaymentService, queries application.PaymentQueryService, method, target, body string) *httptest.ResponseRecorder {
+	return serveRequest(svc, queries, withPrincipal(httptest.NewRequest(method, target, strings.NewReader(body)), principal))
+}
+
+// withPrincipal authenticates req as auth.RequirePrincipal would
+func withPrincipal(req *http.Request, principal auth.Principal) *http.Request {
+	return req.WithContext(auth.WithPrincipal(req.Context(), principal))
+}
+
+func serveRequest(svc application.PaymentService, queries application.PaymentQueryService, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewHandler(svc, queries).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	var response errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	return response.Error
+}
+
+func TestHandler_CreateCheckout(t *testing.T) {
+	svc := &mockPaymentService{}
+	queries := &mockPaymentQueryService{payment: newTestPayment()}
+
+	rec := serve(svc, queries, http.MethodPost, "/payments/checkout", `{"tripID":"trip-1","method":"card","region":"US"}`)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if svc.tripID != "trip-1" || svc.userID != "user-1" || svc.selection.Method != "card" || svc.selection.Region != "US" {
+		t.Errorf("unexpected checkout %s %s %+v", svc.tripID, svc.userID, svc.selection)
+	}
+
+	var response paymentResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ID != "payment-1" || response.SessionID != "cs_test_1" || response.Amount != 1050 || response.AmountDecimal != "10.50" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestHandler_CreateCheckout_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{name: "missing fields", body: `{}`, fields: []string{"tripID"}},
+		{name: "unknown method", body: `{"tripID":"trip-1","method":"paypal"}`, fields: []string{"method"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockPaymentService{}
+
+			rec := serve(svc, &mockPaymentQueryService{}, http.MethodPost, "/payments/checkout", tt.body)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+			body := decodeError(t, rec)
+			if body.Code != CodeInvalidArgument || len(body.Fields) != len(tt.fields) {
+				t.Fatalf("unexpected error body: %+v", body)
+			}
+			for i, field := range tt.fields {
+				if body.Fields[i].Field != field {
+					t.Errorf("expected field %s, got %s", field, body.Fields[i].Field)
+				}
+			}
+			if svc.tripID != "" {
+				t.Error("expected payment service not to be called")
+			}
+		})
+	}
+}
+
+func TestHandler_CreateCheckout_MalformedBody(t *testing.T) {
+	// userID is rejected with the other unknown fields: the rider is always the caller
+	for _, body := range []string{`{`, `{"tripID":"trip-1","amount":1}`, `{"tripID":"trip-1","userID":"user-2"}`} {
+		rec := serve(&mockPaymentService{}, &mockPaymentQueryService{}, http.MethodPost, "/payments/checkout", body)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400 for %s, got %d", body, rec.Code)
+		}
+		if code := decodeError(t, rec).Code; code != CodeInvalidArgument {
+			t.Errorf("expected code %s, got %s", CodeInvalidArgument, code)
+		}
+	}
+}
+
+func TestHandler_CreateCheckout_OnlyRiders(t *testing.T) {
+	for _, principal := range []auth.Principal{driver, admin} {
+		svc := &mockPaymentService{}
+
+		rec := serveAs(principal, svc, &mockPaymentQueryService{payment: newTestPayment()}, http.MethodPost, "/payments/checkout", `{"tripID":"trip-1"}`)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 for %s, got %d", principal.Role, rec.Code)
+		}
+		if svc.tripID != "" {
+			t.Errorf("expected no checkout for %s", principal.Role)
+		}
+	}
+}
+
+func TestHandler_Unauthenticated(t *testing.T) {
+	targets := []struct{ method, target string }{
+		{http.MethodPost, "/payments/checkout"},
+		{http.MethodGet, "/payments"},
+		{http.MethodGet, "/payments/payment-1"},
+		{http.MethodPost, "/payments/payment-1/refunds"},
+	}
+	for _, tt := range targets {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{}`))
+		rec := serveRequest(&mockPaymentService{}, &mockPaymentQueryService{payment: newTestPayment()}, req)
+
+		if rec.Code != http.StatusUnauthorized || decodeError(t, rec).Code != CodeUnauthenticated {
+			t.Errorf("expected status 401 for %s %s, got %d", tt.method, tt.target, rec.Code)
+		}
+	}
+}
+
+func TestHandler_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "trip not found", err: fmt.Errorf("%w: trip-1", domain.ErrTripNotFound), wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "not trip owner", err: domain.ErrNotTripOwner, wantStatus: http.StatusForbidden, wantCode: CodeForbidden},
+		{name: "already paid", err: &application.FareValidationError{TripID: "trip-1", Err: application.ErrTripAlreadyPaid}, wantStatus: http.StatusConflict, wantCode: CodeConflict},
+		{name: "fare expired", err: &application.FareValidationError{TripID: "trip-1", Err: application.ErrFareExpired}, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeUnprocessable},
+		{name: "declined", err: &domain.ProviderError{Provider: "x402", Op: "settle", Err: domain.ErrPaymentDeclined}, wantStatus: http.StatusPaymentRequired, wantCode: CodePaymentDeclined},
+		{name: "provider unavailable", err: fmt.Errorf("failed to create session: %w", domain.ErrProviderUnavailable), wantStatus: http.StatusServiceUnavailable, wantCode: CodeProviderUnavailable},
+		{name: "unexpected", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(&mockPaymentService{err: tt.err}, &mockPaymentQueryService{}, http.MethodPost, "/payments/checkout", `{"tripID":"trip-1"}`)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			body := decodeError(t, rec)
+			if body.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, body.Code)
+			}
+			if tt.wantCode == CodeInternal && body.Message != "internal error" {
+				t.Errorf("expected internal details to be hidden, got %q", body.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_GetPayment(t *testing.T) {
+	rec := serve(&mockPaymentService{}, &mockPaymentQueryService{payment: newTestPayment()}, http.MethodGet, "/payments/payment-1", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response paymentResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ID != "payment-1" || response.TripID != "trip-1" || !response.CreatedAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestHandler_GetPayment_Ownership(t *testing.T) {
+	tests := []struct {
+		principal auth.Principal
+		want      int
+	}{
+		{rider, http.StatusOK},
+		{driver, http.StatusOK},
+		{admin, http.StatusOK},
+		{auth.Principal{Role: auth.RoleRider, ID: "user-2"}, http.StatusNotFound},
+		{auth.Principal{Role: auth.RoleDriver, ID: "user-1"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := serveAs(tt.principal, &mockPaymentService{}, &mockPaymentQueryService{payment: newTestPayment()}, http.MethodGet, "/payments/payment-1", "")
+
+		if rec.Code != tt.want {
+			t.Errorf("expected status %d for %+v, got %d", tt.want, tt.principal, rec.Code)
+		}
+	}
+}
+
+func TestHandler_GetPayment_NotFound(t *testing.T) {
+	queries := &mockPaymentQueryService{err: fmt.Errorf("%w: payment-9", domain.ErrPaymentNotFound)}
+
+	rec := serve(&mockPaymentService{}, queries, http.MethodGet, "/payments/payment-9", "")
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ListPayments(t *testing.T) {
+	queries := &mockPaymentQueryService{list: &application.PaymentList{
+		Payments:      []*domain.Payment{newTestPayment()},
+		NextPageToken: "payment-1",
+	}}
+
+	rec := serveAs(admin, &mockPaymentService{}, queries, http.MethodGet, "/payments?driverID=driver-1&pageSize=1&pageToken=payment-2", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if queries.ownerID != "driver-1" || queries.pageSize != 1 || queries.pageToken != "payment-2" {
+		t.Errorf("unexpected query %s %d %s", queries.ownerID, queries.pageSize, queries.pageToken)
+	}
+
+	var response paymentListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Payments) != 1 || response.NextPageToken != "payment-1" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestHandler_ListPayments_OwnPayments(t *testing.T) {
+	for _, principal := range []auth.Principal{rider, driver} {
+		queries := &mockPaymentQueryService{list: &application.PaymentList{}}
+
+		rec := serveAs(principal, &mockPaymentService{}, queries, http.MethodGet, "/payments?pageSize=5", "")
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d", principal.Role, rec.Code)
+		}
+		if queries.ownerID != principal.ID {
+			t.Errorf("expected payments of %s, got %s", principal.ID, queries.ownerID)
+		}
+	}
+
+	for _, target := range []string{"/payments?userID=user-2", "/payments?userID=user-1", "/payments?driverID=driver-1"} {
+		queries := &mockPaymentQueryService{list: &application.PaymentList{}}
+
+		rec := serve(&mockPaymentService{}, queries, http.MethodGet, target, "")
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 for %s, got %d", target, rec.Code)
+		}
+		if queries.ownerID != "" {
+			t.Errorf("expected no query for %s", target)
+		}
+	}
+}
+
+func TestHandler_ListPayments_Validation(t *testing.T) {
+	for _, target := range []string{
+		"/payments",
+		"/payments?userID=user-1&driverID=driver-1",
+		"/payments?userID=user-1&pageSize=0",
+		"/payments?userID=user-1&pageSize=abc",
+		"/payments?userID=user-1&pageSize=101",
+	} {
+		rec := serveAs(admin, &mockPaymentService{}, &mockPaymentQueryService{}, http.MethodGet, target, "")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", target, rec.Code)
+		}
+	}
+}
+
+// newRefundRequest builds a refund request made by an admin
+func newRefundRequest(idempotencyKey, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/payments/payment-1/refunds", strings.NewReader(body))
+	if idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	return withPrincipal(req, admin)
+}
+
+func TestHandler_RefundPayment(t *testing.T) {
+	svc := &mockPaymentService{}
+	payment := newTestPayment()
+	payment.RefundedAmount = 500
+
+	rec := serveRequest(svc, &mockPaymentQueryService{payment: payment}, newRefundRequest("refund-request-1", `{"amount":500,"reason":"requested_by_customer"}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if svc.paymentID != "payment-1" || svc.requestID != "refund-request-1" || svc.amount != 500 || svc.reason != "requested_by_customer" {
+		t.Errorf("unexpected refund %s %s %d %s", svc.paymentID, svc.requestID, svc.amount, svc.reason)
+	}
+}
+
+func TestHandler_RefundPayment_Errors(t *testing.T) {
+	svc := &mockPaymentService{}
+	rec := serveRequest(svc, &mockPaymentQueryService{}, newRefundRequest("", `{"amount":500}`))
+	if rec.Code != http.StatusBadRequest || decodeError(t, rec).Fields[0].Field != IdempotencyKeyHeader {
+		t.Errorf("expected status 400 for a missing Idempotency-Key, got %d", rec.Code)
+	}
+	if svc.paymentID != "" {
+		t.Error("expected no refund without an Idempotency-Key")
+	}
+
+	svc = &mockPaymentService{}
+	rec = serveRequest(svc, &mockPaymentQueryService{}, withPrincipal(newRefundRequest("refund-request-1", `{"amount":500}`), rider))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for a rider's refund, got %d", rec.Code)
+	}
+	if svc.paymentID != "" {
+		t.Error("expected no refund for a rider")
+	}
+
+	rec = serveRequest(&mockPaymentService{}, &mockPaymentQueryService{}, newRefundRequest("refund-request-1", `{"amount":0}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for zero amount, got %d", rec.Code)
+	}
+
+	svc = &mockPaymentService{err: fmt.Errorf("%w: exceeds captured amount", domain.ErrInvalidRefundAmount)}
+	rec = serveRequest(svc, &mockPaymentQueryService{}, newRefundRequest("refund-request-1", `{"amount":5000}`))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 for excessive refund, got %d", rec.Code)
+	}
+}
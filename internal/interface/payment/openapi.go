@@ -0,0 +1,214 @@
+package payment
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/interface/auth"
+)
+
+// Route describes an endpoint; the same description registers the handler and documents it
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Parameters  []Parameter
+	Request     any         // zero value of the JSON request body, nil when there is none
+	Responses   map[int]any // zero value of the JSON success body by status code
+	handler     http.HandlerFunc
+}
+
+// Parameter is a path or query parameter of a route
+type Parameter struct {
+	Name        string
+	In          string // path, query or header
+	Description string
+	Required    bool
+	Type        string // string or integer
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// OpenAPI builds an OpenAPI 3 document for routes. Schemas are derived from the request and
+// response types so the document cannot drift from what the handlers encode.
+func OpenAPI(routes []Route) map[string]any {
+	schemas := map[string]any{}
+	errorSchema := schemaRef(reflect.TypeOf(errorResponse{}), schemas)
+
+	paths := map[string]any{}
+	for _, route := range routes {
+		operation := map[string]any{
+			"operationId": route.OperationID,
+			"summary":     route.Summary,
+			"responses":   operationResponses(route, errorSchema, schemas),
+		}
+		if len(route.Parameters) > 0 {
+			operation["parameters"] = parameters(route.Parameters)
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaRef(reflect.TypeOf(route.Request), schemas)),
+			}
+		}
+
+		item, ok := paths[route.Path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Payment API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":         schemas,
+			"securitySchemes": securitySchemes(),
+		},
+		// Gateway keys forward the verified rider or driver; admin keys are sent alone
+		"security": []any{
+			map[string]any{"apiKey": []string{}, "principalRole": []string{}, "principalID": []string{}},
+			map[string]any{"apiKey": []string{}},
+		},
+	}
+}
+
+func securitySchemes() map[string]any {
+	return map[string]any{
+		"apiKey": map[string]any{"type": "http", "scheme": "bearer", "description": "gateway, partner or admin API key"},
+		"principalRole": map[string]any{
+			"type": "apiKey", "in": "header", "name": auth.PrincipalRoleHeader,
+			"description": "rider or driver the gateway verified",
+		},
+		"principalID": map[string]any{
+			"type": "apiKey", "in": "header", "name": auth.PrincipalIDHeader,
+			"description": "ID of the rider or driver the gateway verified",
+		},
+	}
+}
+
+func operationResponses(route Route, errorSchema map[string]any, schemas map[string]any) map[string]any {
+	responses := map[string]any{}
+	for status, body := range route.Responses {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     jsonContent(schemaRef(reflect.TypeOf(body), schemas)),
+		}
+	}
+	responses["default"] = map[string]any{
+		"description": "Error in the standard envelope",
+		"content":     jsonContent(errorSchema),
+	}
+	return responses
+}
+
+func parameters(params []Parameter) []any {
+	out := make([]any, 0, len(params))
+	for _, param := range params {
+		spec := map[string]any{
+			"name":     param.Name,
+			"in":       param.In,
+			"required": param.Required,
+			"schema":   map[string]any{"type": param.Type},
+		}
+		if param.Description != "" {
+			spec["description"] = param.Description
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaRef returns the schema of t, registering structs in schemas and referring to them by name
+func schemaRef(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := schemaName(t)
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = nil // reserve the name so recursive types terminate
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]any{"type": "array", "items": schemaRef(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaRef(field.Type, schemas)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			if _, isRef := property["$ref"]; isRef {
+				property = map[string]any{"allOf": []any{property}}
+			}
+			property["description"] = doc
+		}
+		properties[name] = property
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// schemaName turns an unexported Go type name such as checkoutRequest into CheckoutRequest
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return "Object"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,76 @@
+package payment
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestOpenAPI_DocumentsRoutes(t *testing.T) {
+	rec := serve(&mockPaymentService{}, &mockPaymentQueryService{}, http.MethodGet, "/openapi.json", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Responses   map[string]any
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                  `json:"required"`
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+			SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+		Security []map[string][]string `json:"security"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+
+	operations := map[string]string{
+		"/payments/checkout":            "post",
+		"/payments":                     "get",
+		"/payments/{paymentID}":         "get",
+		"/payments/{paymentID}/refunds": "post",
+	}
+	for path, method := range operations {
+		operation, ok := spec.Paths[path][method]
+		if !ok {
+			t.Errorf("expected %s %s to be documented", method, path)
+			continue
+		}
+		if _, ok := operation.Responses["default"]; !ok {
+			t.Errorf("expected %s %s to document the error envelope", method, path)
+		}
+	}
+
+	checkout, ok := spec.Components.Schemas["CheckoutRequest"]
+	if !ok {
+		t.Fatal("expected CheckoutRequest schema")
+	}
+	if len(checkout.Required) != 1 || checkout.Required[0] != "tripID" {
+		t.Errorf("expected only tripID to be required, got %v", checkout.Required)
+	}
+	if _, ok := checkout.Properties["userID"]; ok {
+		t.Error("expected the rider to come from the principal, not the body")
+	}
+	if checkout.Properties["method"]["description"] == nil {
+		t.Error("expected method description from doc tag")
+	}
+
+	payment := spec.Components.Schemas["PaymentResponse"]
+	if payment.Properties["amount"]["format"] != "int64" || payment.Properties["createdAt"]["format"] != "date-time" {
+		t.Errorf("unexpected payment properties: %v", payment.Properties)
+	}
+	if spec.Components.SecuritySchemes["principalRole"]["name"] != "X-Principal-Role" || len(spec.Security) != 2 {
+		t.Errorf("expected the API key and principal headers to be documented, got %v %v", spec.Components.SecuritySchemes, spec.Security)
+	}
+	if _, ok := spec.Components.Schemas["FieldError"]; !ok {
+		t.Error("expected FieldError schema from the error envelope")
+	}
+}
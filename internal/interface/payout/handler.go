@@ -0,0 +1,158 @@
+package payout
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// Handler exposes driver onboarding, the payout ledger and payout runs over HTTP.
+// Its routes are for operators: serve them on the internal admin listener behind auth.RequireAdmin.
+type Handler struct {
+	payoutSvc application.PayoutService
+}
+
+// NewHandler creates a new payout HTTP handler
+func NewHandler(payoutSvc application.PayoutService) *Handler {
+	return &Handler{payoutSvc: payoutSvc}
+}
+
+// Register adds the payout routes to the mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /drivers/{driverID}/payout-onboarding", h.createOnboardingLink)
+	mux.HandleFunc("GET /drivers/{driverID}/payouts", h.listPayouts)
+	mux.HandleFunc("POST /payouts/runs", h.runPayouts)
+}
+
+type onboardingLinkResponse struct {
+	URL string `json:"url"`
+}
+
+type payoutItemResponse struct {
+	PaymentID       string `json:"paymentID"`
+	TripID          string `json:"tripID"`
+	GrossAmount     int64  `json:"grossAmount"`
+	Commission      int64  `json:"commission"`
+	NetAmount       int64  `json:"netAmount"`
+	CommissionDebit bool   `json:"commissionDebit,omitempty"`
+}
+
+// payoutResponse is a ledger entry as shown on driver earnings screens; amounts are in minor units
+type payoutResponse struct {
+	ID                 string               `json:"id"`
+	Currency           string               `json:"currency"`
+	GrossAmount        int64                `json:"grossAmount"`
+	Commission         int64                `json:"commission"`
+	NetAmount          int64                `json:"netAmount"`
+	Status             string               `json:"status"`
+	ProviderTransferID string               `json:"providerTransferID,omitempty"`
+	FailureReason      string               `json:"failureReason,omitempty"`
+	Items              []payoutItemResponse `json:"items"`
+	CreatedAt          time.Time            `json:"createdAt"`
+}
+
+type runPayoutsRequest struct {
+	// Cutoff limits the run to payments created before it; defaults to now
+	Cutoff *time.Time `json:"cutoff,omitempty"`
+}
+
+type runPayoutsResponse struct {
+	Paid           int `json:"paid"`
+	Failed         int `json:"failed"`
+	SkippedDrivers int `json:"skippedDrivers"`
+}
+
+func (h *Handler) createOnboardingLink(w http.ResponseWriter, r *http.Request) {
+	url, err := h.payoutSvc.CreateOnboardingLink(r.Context(), r.PathValue("driverID"))
+	if err != nil {
+		log.Printf("failed to create onboarding link: %v", err)
+		http.Error(w, "failed to create onboarding link", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, onboardingLinkResponse{URL: url})
+}
+
+func (h *Handler) listPayouts(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	payouts, err := h.payoutSvc.ListDriverPayouts(r.Context(), r.PathValue("driverID"), limit)
+	if err != nil {
+		log.Printf("failed to list payouts: %v", err)
+		http.Error(w, "failed to list payouts", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]payoutResponse, 0, len(payouts))
+	for _, payout := range payouts {
+		response = append(response, toPayoutResponse(payout))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) runPayouts(w http.ResponseWriter, r *http.Request) {
+	var req runPayoutsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cutoff := time.Now().UTC()
+	if req.Cutoff != nil {
+		cutoff = *req.Cutoff
+	}
+
+	result, err := h.payoutSvc.RunPayouts(r.Context(), cutoff)
+	if err != nil {
+		log.Printf("payout run failed: %v", err)
+		http.Error(w, "payout run failed", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, runPayoutsResponse{
+		Paid:           result.Paid,
+		Failed:         result.Failed,
+		SkippedDrivers: result.SkippedDrivers,
+	})
+}
+
+func toPayoutResponse(payout *domain.Payout) payoutResponse {
+	response := payoutResponse{
+		ID:                 payout.ID,
+		Currency:           payout.Currency,
+		GrossAmount:        payout.GrossAmount,
+		Commission:         payout.Commission,
+		NetAmount:          payout.NetAmount,
+		Status:             string(payout.Status),
+		ProviderTransferID: payout.ProviderTransferID,
+		FailureReason:      payout.FailureReason,
+		Items:              make([]payoutItemResponse, 0, len(payout.Items)),
+		CreatedAt:          payout.CreatedAt,
+	}
+	for _, item := range payout.Items {
+		response.Items = append(response.Items, payoutItemResponse(item))
+	}
+	return response
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package payout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// mockPayoutService is a mock implementation of application.PayoutService
+type mockPayoutService struct {
+	err      error
+	driverID string
+	limit    int
+	cutoff   time.Time
+	payouts  []*domain.Payout
+}
+
+func (m *mockPayoutService) CreateOnboardingLink(ctx context.Context, driverID string) (string, error) {
+	m.driverID = driverID
+	return "https://connect.stripe.test/onboarding", m.err
+}
+
+func (m *mockPayoutService) ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]*domain.Payout, error) {
+	m.driverID = driverID
+	m.limit = limit
+	return m.payouts, m.err
+}
+
+func (m *mockPayoutService) RunPayouts(ctx context.Context, cutoff time.Time) (*application.PayoutRunResult, error) {
+	m.cutoff = cutoff
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &application.PayoutRunResult{Paid: 2, SkippedDrivers: 1}, nil
+}
+
+func serve(svc application.PayoutService, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewHandler(svc).Register(mux)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_CreateOnboardingLink(t *testing.T) {
+	svc := &mockPayoutService{}
+
+	rec := serve(svc, http.MethodPost, "/drivers/driver-1/payout-onboarding", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if svc.driverID != "driver-1" {
+		t.Errorf("expected driver-1, got %s", svc.driverID)
+	}
+
+	var response onboardingLinkResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.URL != "https://connect.stripe.test/onboarding" {
+		t.Errorf("unexpected URL %s", response.URL)
+	}
+}
+
+func TestHandler_ListPayouts(t *testing.T) {
+	payout := domain.NewPayout(domain.NewDriverAccount("driver-1", "acct_1"), "usd", 2000)
+	payout.ID = "payout-1"
+	payout.Items = []domain.PayoutItem{{PaymentID: "payment-1", TripID: "trip-1", GrossAmount: 1000, Commission: 200, NetAmount: 800}}
+	svc := &mockPayoutService{payouts: []*domain.Payout{payout}}
+
+	rec := serve(svc, http.MethodGet, "/drivers/driver-1/payouts?limit=5", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if svc.limit != 5 {
+		t.Errorf("expected limit 5, got %d", svc.limit)
+	}
+
+	var response []payoutResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response) != 1 || response[0].ID != "payout-1" || response[0].Items[0].NetAmount != 800 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestHandler_ListPayouts_InvalidLimit(t *testing.T) {
+	rec := serve(&mockPayoutService{}, http.MethodGet, "/drivers/driver-1/payouts?limit=abc", "")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_RunPayouts(t *testing.T) {
+	svc := &mockPayoutService{}
+
+	rec := serve(svc, http.MethodPost, "/payouts/runs", `{"cutoff": "2026-10-12T00:00:00Z"}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !svc.cutoff.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected cutoff %v", svc.cutoff)
+	}
+
+	var response runPayoutsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Paid != 2 || response.SkippedDrivers != 1 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestHandler_RunPayouts_ServiceError(t *testing.T) {
+	rec := serve(&mockPayoutService{err: errors.New("mongo unavailable")}, http.MethodPost, "/payouts/runs", "")
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,253 @@
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/interface/consumer"
+)
+
+// Headers added to replayed messages
+const (
+	HeaderReplayedAt = "x-replayed-at"
+	HeaderReplayedBy = "x-replayed-by"
+)
+
+// Broker reads the dead-letter queue and republishes messages (allows mocking in tests)
+type Broker interface {
+	// Get takes the next message off the dead-letter queue without acknowledging it;
+	// ok is false once the queue is empty
+	Get(ctx context.Context) (msg amqp091.Delivery, ok bool, err error)
+	Publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error
+}
+
+// DeadLetter is a dead-lettered message with the failure details recorded by consumer.DeadLetterHandler
+type DeadLetter struct {
+	ID            string `json:"id"`
+	RoutingKey    string `json:"routingKey"`
+	TripID        string `json:"tripID,omitempty"`
+	FailureClass  string `json:"failureClass"`
+	FailureReason string `json:"failureReason"`
+	FailedAt      string `json:"failedAt"`
+	RetryCount    int    `json:"retryCount"`
+
+	delivery amqp091.Delivery
+}
+
+// Filter selects dead letters; empty fields match everything
+type Filter struct {
+	RoutingKey string
+	TripID     string
+	IDs        []string
+	Limit      int // maximum number of dead letters selected, 0 for no limit
+}
+
+func (f Filter) matches(letter *DeadLetter) bool {
+	return (f.RoutingKey == "" || letter.RoutingKey == f.RoutingKey) &&
+		(f.TripID == "" || letter.TripID == f.TripID) &&
+		(len(f.IDs) == 0 || slices.Contains(f.IDs, letter.ID))
+}
+
+// AuditEntry is one line of the replay audit log
+type AuditEntry struct {
+	Time          time.Time `json:"time"`
+	Operator      string    `json:"operator"`
+	DryRun        bool      `json:"dryRun"`
+	ID            string    `json:"id"`
+	RoutingKey    string    `json:"routingKey"`
+	TripID        string    `json:"tripID,omitempty"`
+	FailureReason string    `json:"failureReason"`
+	Target        string    `json:"target"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Tool lists dead-lettered payment commands and replays them to the queue they failed on
+type Tool struct {
+	broker   Broker
+	audit    io.Writer
+	target   string
+	operator string
+	now      func() time.Time
+}
+
+// NewTool creates a replay tool republishing to the target queue and writing one JSON line
+// per replayed message to audit
+func NewTool(broker Broker, audit io.Writer, target, operator string) *Tool {
+	return &Tool{broker: broker, audit: audit, target: target, operator: operator, now: time.Now}
+}
+
+// List returns the dead letters matching filter; every message stays in the dead-letter queue
+func (t *Tool) List(ctx context.Context, filter Filter) ([]*DeadLetter, error) {
+	selected, rest, err := t.take(ctx, filter)
+	release(append(selected, rest...))
+	return selected, err
+}
+
+// Replay republishes the dead letters matching filter to the target queue and removes them from
+// the dead-letter queue. In dry-run mode nothing is republished or removed, but the audit log
+// still records what would have been replayed.
+func (t *Tool) Replay(ctx context.Context, filter Filter, dryRun bool) ([]*DeadLetter, error) {
+	selected, rest, err := t.take(ctx, filter)
+	defer release(rest)
+	if err != nil {
+		release(selected)
+		return nil, err
+	}
+
+	var replayed []*DeadLetter
+	for i, letter := range selected {
+		if dryRun {
+			release(selected[i : i+1])
+			err = t.record(letter, true, nil)
+		} else {
+			err = t.replay(ctx, letter)
+		}
+		if err != nil {
+			release(selected[i+1:])
+			return replayed, err
+		}
+		replayed = append(replayed, letter)
+	}
+	return replayed, nil
+}
+
+func (t *Tool) replay(ctx context.Context, letter *DeadLetter) error {
+	headers := make(amqp091.Table, len(letter.delivery.Headers)+2)
+	for key, value := range letter.delivery.Headers {
+		headers[key] = value
+	}
+	// A replay gets the full retry budget again; the consumer restores the routing key from the header
+	delete(headers, consumer.HeaderRetryCount)
+	headers[consumer.HeaderOriginalRoutingKey] = letter.RoutingKey
+	headers[HeaderReplayedAt] = t.now().UTC().Format(time.RFC3339)
+	headers[HeaderReplayedBy] = t.operator
+
+	msg := letter.delivery
+	publishErr := t.broker.Publish(ctx, "", t.target, amqp091.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		DeliveryMode:  amqp091.Persistent,
+		CorrelationId: msg.CorrelationId,
+		MessageId:     msg.MessageId,
+		Timestamp:     msg.Timestamp,
+		Type:          msg.Type,
+		AppId:         msg.AppId,
+		Body:          msg.Body,
+	})
+	if publishErr != nil {
+		msg.Nack(false, true)
+	} else if err := msg.Ack(false); err != nil {
+		// Already republished; the message stays dead-lettered and may be replayed twice
+		publishErr = fmt.Errorf("replayed but failed to remove from dead-letter queue: %w", err)
+	}
+
+	if err := t.record(letter, false, publishErr); err != nil {
+		return err
+	}
+	return publishErr
+}
+
+// record appends an audit entry for a replay attempt
+func (t *Tool) record(letter *DeadLetter, dryRun bool, replayErr error) error {
+	entry := AuditEntry{
+		Time:          t.now().UTC(),
+		Operator:      t.operator,
+		DryRun:        dryRun,
+		ID:            letter.ID,
+		RoutingKey:    letter.RoutingKey,
+		TripID:        letter.TripID,
+		FailureReason: letter.FailureReason,
+		Target:        t.target,
+	}
+	if replayErr != nil {
+		entry.Error = replayErr.Error()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := t.audit.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// take drains the dead-letter queue, splitting the unacknowledged messages into those matching
+// filter and the rest; the caller must settle all of them
+func (t *Tool) take(ctx context.Context, filter Filter) (selected, rest []*DeadLetter, err error) {
+	for {
+		msg, ok, err := t.broker.Get(ctx)
+		if err != nil {
+			return selected, rest, err
+		}
+		if !ok {
+			return selected, rest, nil
+		}
+
+		letter := parseDeadLetter(msg)
+		if filter.matches(letter) && (filter.Limit <= 0 || len(selected) < filter.Limit) {
+			selected = append(selected, letter)
+		} else {
+			rest = append(rest, letter)
+		}
+	}
+}
+
+// release returns the messages to the dead-letter queue
+func release(letters []*DeadLetter) {
+	for _, letter := range letters {
+		letter.delivery.Nack(false, true)
+	}
+}
+
+func parseDeadLetter(msg amqp091.Delivery) *DeadLetter {
+	letter := &DeadLetter{
+		ID:         messageID(msg),
+		RoutingKey: msg.RoutingKey,
+		delivery:   msg,
+	}
+	if routingKey, ok := msg.Headers[consumer.HeaderOriginalRoutingKey].(string); ok {
+		letter.RoutingKey = routingKey
+	}
+	letter.FailureClass, _ = msg.Headers[consumer.HeaderFailureClass].(string)
+	letter.FailureReason, _ = msg.Headers[consumer.HeaderFailureReason].(string)
+	letter.FailedAt, _ = msg.Headers[consumer.HeaderFailedAt].(string)
+	switch count := msg.Headers[consumer.HeaderRetryCount].(type) {
+	case int32:
+		letter.RetryCount = int(count)
+	case int64:
+		letter.RetryCount = int(count)
+	}
+
+	// Every payment command carries the trip in its payload
+	var message events.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &message); err == nil {
+		var payload struct {
+			TripID string `json:"tripID"`
+		}
+		if err := json.Unmarshal(message.Data, &payload); err == nil {
+			letter.TripID = payload.TripID
+		}
+	}
+	return letter
+}
+
+// messageID identifies a dead letter across listings: its message ID, or a fingerprint of
+// its body and failure time when the publisher set none
+func messageID(msg amqp091.Delivery) string {
+	if msg.MessageId != "" {
+		return msg.MessageId
+	}
+	failedAt, _ := msg.Headers[consumer.HeaderFailedAt].(string)
+	sum := sha256.Sum256(append([]byte(failedAt), msg.Body...))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
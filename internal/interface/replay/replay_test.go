@@ -0,0 +1,191 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/ride4Low/contracts/events"
+	"github.com/ride4Low/payment-service/internal/interface/consumer"
+)
+
+// mockAcknowledger records how each delivery tag was settled
+type mockAcknowledger struct {
+	acked  []uint64
+	nacked []uint64
+}
+
+func (m *mockAcknowledger) Ack(tag uint64, multiple bool) error {
+	m.acked = append(m.acked, tag)
+	return nil
+}
+
+func (m *mockAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	m.nacked = append(m.nacked, tag)
+	return nil
+}
+
+func (m *mockAcknowledger) Reject(tag uint64, requeue bool) error {
+	return m.Nack(tag, false, requeue)
+}
+
+type publishedMessage struct {
+	routingKey string
+	msg        amqp091.Publishing
+}
+
+// mockBroker is a mock implementation of Broker for testing
+type mockBroker struct {
+	queue      []amqp091.Delivery
+	published  []publishedMessage
+	publishErr error
+}
+
+func (m *mockBroker) Get(ctx context.Context) (amqp091.Delivery, bool, error) {
+	if len(m.queue) == 0 {
+		return amqp091.Delivery{}, false, nil
+	}
+	msg := m.queue[0]
+	m.queue = m.queue[1:]
+	return msg, true, nil
+}
+
+func (m *mockBroker) Publish(ctx context.Context, exchange, routingKey string, msg amqp091.Publishing) error {
+	if m.publishErr != nil {
+		return m.publishErr
+	}
+	m.published = append(m.published, publishedMessage{routingKey: routingKey, msg: msg})
+	return nil
+}
+
+func deadLetter(t *testing.T, ack *mockAcknowledger, tag uint64, routingKey, tripID string) amqp091.Delivery {
+	t.Helper()
+	data, _ := json.Marshal(events.PaymentSelectCardData{TripID: tripID, UserID: "user-1"})
+	body, err := json.Marshal(events.AmqpMessage{OwnerID: "user-1", Data: data})
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+	return amqp091.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  tag,
+		RoutingKey:   routingKey,
+		MessageId:    "msg-" + tripID,
+		Body:         body,
+		Headers: amqp091.Table{
+			consumer.HeaderOriginalRoutingKey: routingKey,
+			consumer.HeaderFailureClass:       consumer.FailureClassRetriesExhausted,
+			consumer.HeaderFailureReason:      "stripe unavailable",
+			consumer.HeaderRetryCount:         int32(3),
+		},
+	}
+}
+
+func newTestBroker(t *testing.T, ack *mockAcknowledger) *mockBroker {
+	return &mockBroker{queue: []amqp091.Delivery{
+		deadLetter(t, ack, 1, events.PaymentCmdCreateSession, "trip-1"),
+		deadLetter(t, ack, 2, consumer.PaymentCmdCapture, "trip-2"),
+		deadLetter(t, ack, 3, events.PaymentCmdCreateSession, "trip-3"),
+	}}
+}
+
+func TestTool_List(t *testing.T) {
+	ack := &mockAcknowledger{}
+	tool := NewTool(newTestBroker(t, ack), &bytes.Buffer{}, events.PaymentTripResponseQueue, "oncall")
+
+	letters, err := tool.List(context.Background(), Filter{RoutingKey: events.PaymentCmdCreateSession})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(letters) != 2 || letters[0].TripID != "trip-1" || letters[1].TripID != "trip-3" {
+		t.Fatalf("unexpected dead letters: %+v", letters)
+	}
+	if letters[0].FailureReason != "stripe unavailable" || letters[0].RetryCount != 3 {
+		t.Errorf("expected failure details, got %+v", letters[0])
+	}
+	if len(ack.acked) != 0 || len(ack.nacked) != 3 {
+		t.Errorf("expected every message requeued, got acked %v nacked %v", ack.acked, ack.nacked)
+	}
+}
+
+func TestTool_Replay(t *testing.T) {
+	ack := &mockAcknowledger{}
+	broker := newTestBroker(t, ack)
+	audit := &bytes.Buffer{}
+	tool := NewTool(broker, audit, events.PaymentTripResponseQueue, "oncall")
+
+	replayed, err := tool.Replay(context.Background(), Filter{TripID: "trip-3"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(replayed) != 1 || len(broker.published) != 1 {
+		t.Fatalf("expected 1 replayed message, got %d published %d", len(replayed), len(broker.published))
+	}
+	published := broker.published[0]
+	if published.routingKey != events.PaymentTripResponseQueue {
+		t.Errorf("expected republish to %s, got %s", events.PaymentTripResponseQueue, published.routingKey)
+	}
+	if _, ok := published.msg.Headers[consumer.HeaderRetryCount]; ok {
+		t.Error("expected the retry count to be reset")
+	}
+	if published.msg.Headers[consumer.HeaderOriginalRoutingKey] != events.PaymentCmdCreateSession || published.msg.Headers[HeaderReplayedBy] != "oncall" {
+		t.Errorf("unexpected headers: %v", published.msg.Headers)
+	}
+	if len(ack.acked) != 1 || ack.acked[0] != 3 || len(ack.nacked) != 2 {
+		t.Errorf("expected only the replayed message acked, got acked %v nacked %v", ack.acked, ack.nacked)
+	}
+
+	var entry AuditEntry
+	if err := json.Unmarshal(audit.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse audit log: %v", err)
+	}
+	if entry.ID != "msg-trip-3" || entry.Operator != "oncall" || entry.DryRun || entry.Error != "" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestTool_Replay_DryRun(t *testing.T) {
+	ack := &mockAcknowledger{}
+	broker := newTestBroker(t, ack)
+	audit := &bytes.Buffer{}
+	tool := NewTool(broker, audit, events.PaymentTripResponseQueue, "oncall")
+
+	replayed, err := tool.Replay(context.Background(), Filter{RoutingKey: events.PaymentCmdCreateSession, Limit: 1}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(replayed) != 1 || len(broker.published) != 0 {
+		t.Errorf("expected nothing published, got %d", len(broker.published))
+	}
+	if len(ack.acked) != 0 || len(ack.nacked) != 3 {
+		t.Errorf("expected every message requeued, got acked %v nacked %v", ack.acked, ack.nacked)
+	}
+	if !strings.Contains(audit.String(), `"dryRun":true`) {
+		t.Errorf("expected dry run in audit log, got %s", audit.String())
+	}
+}
+
+func TestTool_Replay_PublishFailure(t *testing.T) {
+	ack := &mockAcknowledger{}
+	broker := newTestBroker(t, ack)
+	broker.publishErr = errors.New("channel closed")
+	audit := &bytes.Buffer{}
+	tool := NewTool(broker, audit, events.PaymentTripResponseQueue, "oncall")
+
+	if _, err := tool.Replay(context.Background(), Filter{}, false); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(ack.acked) != 0 || len(ack.nacked) != 3 {
+		t.Errorf("expected every message kept, got acked %v nacked %v", ack.acked, ack.nacked)
+	}
+	if !strings.Contains(audit.String(), "channel closed") {
+		t.Errorf("expected the failure in the audit log, got %s", audit.String())
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// maxBodyBytes caps the webhook payload size, matching Stripe's recommendation
+const maxBodyBytes = 65536
+
+// EventParser verifies and translates a provider webhook payload (allows mocking in tests)
+type EventParser interface {
+	Parse(payload []byte, signature string) (*application.ProviderEvent, error)
+}
+
+// StripeHandler receives Stripe webhooks and forwards them to the payment service
+type StripeHandler struct {
+	parser     EventParser
+	paymentSvc application.PaymentService
+}
+
+// NewStripeHandler creates a new Stripe webhook handler
+func NewStripeHandler(parser EventParser, paymentSvc application.PaymentService) *StripeHandler {
+	return &StripeHandler{parser: parser, paymentSvc: paymentSvc}
+}
+
+// ServeHTTP handles a single webhook delivery.
+// Stripe retries any non-2xx response, so only transient failures return 5xx.
+func (h *StripeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := h.parser.Parse(payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		if errors.Is(err, application.ErrProviderEventIgnored) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		log.Printf("rejected stripe webhook: %v", err)
+		http.Error(w, "invalid webhook", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.paymentSvc.HandleProviderEvent(r.Context(), *event); err != nil {
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			// Out-of-order or stale event: retrying will never succeed
+			log.Printf("ignoring stripe webhook %s: %v", event.Type, err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if errors.Is(err, domain.ErrPaymentNotFound) {
+			// Not one of our payments, e.g. a session of another integration on the same Stripe account
+			log.Printf("ignoring stripe webhook %s for an unknown payment: %v", event.Type, err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		log.Printf("failed to handle stripe webhook %s: %v", event.Type, err)
+		http.Error(w, "failed to handle webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ride4Low/payment-service/internal/application"
+	"github.com/ride4Low/payment-service/internal/domain"
+)
+
+// mockEventParser is a mock implementation of EventParser
+type mockEventParser struct {
+	event     *application.ProviderEvent
+	err       error
+	signature string
+}
+
+func (m *mockEventParser) Parse(payload []byte, signature string) (*application.ProviderEvent, error) {
+	m.signature = signature
+	return m.event, m.err
+}
+
+// mockPaymentService is a mock implementation of application.PaymentService
+type mockPaymentService struct {
+	application.PaymentService
+	err   error
+	event *application.ProviderEvent
+}
+
+func (m *mockPaymentService) HandleProviderEvent(ctx context.Context, event application.ProviderEvent) error {
+	m.event = &event
+	return m.err
+}
+
+func serveWebhook(handler http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/stripe", strings.NewReader(`{}`))
+	req.Header.Set("Stripe-Signature", "t=1,v1=sig")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStripeHandler_Success(t *testing.T) {
+	parser := &mockEventParser{event: &application.ProviderEvent{Type: application.ProviderEventPaymentSucceeded, PaymentID: "payment-1"}}
+	svc := &mockPaymentService{}
+
+	rec := serveWebhook(NewStripeHandler(parser, svc))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if parser.signature != "t=1,v1=sig" {
+		t.Errorf("expected signature header to be forwarded, got '%s'", parser.signature)
+	}
+
+	if svc.event == nil || svc.event.PaymentID != "payment-1" {
+		t.Error("expected event to be forwarded to payment service")
+	}
+}
+
+func TestStripeHandler_InvalidSignature(t *testing.T) {
+	parser := &mockEventParser{err: errors.New("invalid signature")}
+	svc := &mockPaymentService{}
+
+	rec := serveWebhook(NewStripeHandler(parser, svc))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if svc.event != nil {
+		t.Error("expected payment service not to be called")
+	}
+}
+
+func TestStripeHandler_IgnoredEvent(t *testing.T) {
+	parser := &mockEventParser{err: application.ErrProviderEventIgnored}
+	svc := &mockPaymentService{}
+
+	rec := serveWebhook(NewStripeHandler(parser, svc))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestStripeHandler_ServiceError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"transient error", errors.New("mongodb down"), http.StatusInternalServerError},
+		{"invalid transition", &domain.InvalidTransitionError{From: domain.PaymentStatusRefunded, To: domain.PaymentStatusCaptured}, http.StatusOK},
+		{"unknown payment", fmt.Errorf("%w: cs_other", domain.ErrPaymentNotFound), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &mockEventParser{event: &application.ProviderEvent{Type: application.ProviderEventPaymentSucceeded}}
+			svc := &mockPaymentService{err: tt.err}
+
+			rec := serveWebhook(NewStripeHandler(parser, svc))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}